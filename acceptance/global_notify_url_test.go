@@ -0,0 +1,117 @@
+package acceptance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("global notify url", func() {
+	var (
+		server   *ghttp.Server
+		receiver *ghttp.Server
+		events   []map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		server = createTLSServer()
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v0/staged/products"),
+				ghttp.RespondWith(http.StatusOK, `[{"type": "cf", "guid": "cf-some-guid"}]`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/api/v0/staged/products/cf-some-guid"),
+				ghttp.RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		events = nil
+		receiver = ghttp.NewServer()
+		receiver.RouteToHandler("POST", "/hooks", func(w http.ResponseWriter, req *http.Request) {
+			var event map[string]interface{}
+			Expect(json.NewDecoder(req.Body).Decode(&event)).To(Succeed())
+			events = append(events, event)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		receiver.Close()
+	})
+
+	It("posts an event when a command that changes Ops Manager starts and finishes", func() {
+		command := exec.Command(pathToMain,
+			"--target", server.URL(),
+			"--username", "some-username",
+			"--password", "some-password",
+			"--skip-ssl-validation",
+			"--notify-url", receiver.URL()+"/hooks",
+			"unstage-product",
+			"--product-name", "cf",
+		)
+
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(events).To(HaveLen(2))
+		Expect(events[0]).To(HaveKeyWithValue("event", "started"))
+		Expect(events[0]).To(HaveKeyWithValue("command", "unstage-product"))
+		Expect(events[0]).To(HaveKeyWithValue("target", server.URL()))
+		Expect(events[0]).To(HaveKeyWithValue("user", "some-username"))
+		Expect(events[0]).To(HaveKeyWithValue("products", []interface{}{"cf"}))
+		Expect(events[1]).To(HaveKeyWithValue("event", "finished"))
+		Expect(events[1]).To(HaveKeyWithValue("outcome", "succeeded"))
+	})
+
+	It("reads the notify url from the env file", func() {
+		envFile, err := ioutil.TempFile("", "env.yml")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = fmt.Fprintf(envFile, "notify-url: %s/hooks\n", receiver.URL())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(envFile.Close()).To(Succeed())
+
+		command := exec.Command(pathToMain,
+			"--target", server.URL(),
+			"--username", "some-username",
+			"--password", "some-password",
+			"--skip-ssl-validation",
+			"--env", envFile.Name(),
+			"unstage-product",
+			"--product-name", "cf",
+		)
+
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("does not notify for commands that only read from Ops Manager", func() {
+		command := exec.Command(pathToMain,
+			"--target", server.URL(),
+			"--username", "some-username",
+			"--password", "some-password",
+			"--skip-ssl-validation",
+			"--notify-url", receiver.URL()+"/hooks",
+			"curl",
+			"--path", "/api/v0/staged/products",
+		)
+
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(events).To(BeEmpty())
+	})
+})
@@ -20,6 +20,7 @@ Usage: om [options] <command> [<args>]
   --decryption-passphrase, -d, OM_DECRYPTION_PASSPHRASE  string  Passphrase to decrypt the installation if the Ops Manager VM has been rebooted (optional for most commands)
   --env, -e                                              string  env file with login credentials
   --help, -h                                             bool    prints this usage information (default: false)
+  --notify-url, OM_NOTIFY_URL                            string  URL to POST a JSON event to when a command that changes Ops Manager starts and finishes
   --password, -p, OM_PASSWORD                            string  admin password for the Ops Manager VM (not required for unauthenticated commands)
   --request-timeout, -r, OM_REQUEST_TIMEOUT              int     timeout in seconds for HTTP requests to Ops Manager (default: 1800)
   --skip-ssl-validation, -k, OM_SKIP_SSL_VALIDATION      bool    skip ssl certificate validation during http requests (default: false)
@@ -44,6 +45,7 @@ Usage: om [options] configure-authentication [<args>]
   --decryption-passphrase, -d, OM_DECRYPTION_PASSPHRASE  string  Passphrase to decrypt the installation if the Ops Manager VM has been rebooted (optional for most commands)
   --env, -e                                              string  env file with login credentials
   --help, -h                                             bool    prints this usage information (default: false)
+  --notify-url, OM_NOTIFY_URL                            string  URL to POST a JSON event to when a command that changes Ops Manager starts and finishes
   --password, -p, OM_PASSWORD                            string  admin password for the Ops Manager VM (not required for unauthenticated commands)
   --request-timeout, -r, OM_REQUEST_TIMEOUT              int     timeout in seconds for HTTP requests to Ops Manager (default: 1800)
   --skip-ssl-validation, -k, OM_SKIP_SSL_VALIDATION      bool    skip ssl certificate validation during http requests (default: false)
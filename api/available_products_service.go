@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/pivotal-cf/om/network"
 	"github.com/pkg/errors"
 )
 
@@ -18,6 +19,10 @@ type UploadAvailableProductInput struct {
 	Product         io.Reader
 	ContentType     string
 	PollingInterval int
+
+	// HideProgress is set when several uploads run at once, as their
+	// progress bars would overwrite each other.
+	HideProgress bool
 }
 
 type ProductInfo struct {
@@ -47,6 +52,7 @@ func (a Api) UploadAvailableProduct(input UploadAvailableProductInput) (UploadAv
 	req.ContentLength = input.ContentLength
 
 	req = req.WithContext(context.WithValue(req.Context(), "polling-interval", time.Duration(input.PollingInterval)*time.Second))
+	req = req.WithContext(network.HideProgress(req.Context(), input.HideProgress))
 
 	resp, err := a.progressClient.Do(req)
 	if err != nil {
@@ -18,33 +18,46 @@ type ImportInstallationInput struct {
 }
 
 func (a Api) DownloadInstallationAssetCollection(outputFile string) error {
-	resp, err := a.sendProgressAPIRequest("GET", "/api/v0/installation_asset_collection", nil)
+	installation, contentLength, err := a.OpenInstallationAssetCollection()
 	if err != nil {
-		return errors.Wrap(err, "could not make api request to installation_asset_collection endpoint")
-	}
-	defer resp.Body.Close()
-
-	if err = validateStatusOK(resp); err != nil {
 		return err
 	}
+	defer installation.Close()
 
 	outputFileHandle, err := os.Create(outputFile)
 	if err != nil {
 		return errors.Wrap(err, "cannot create output file")
 	}
+	defer outputFileHandle.Close()
 
-	bytesWritten, err := io.Copy(outputFileHandle, resp.Body)
+	bytesWritten, err := io.Copy(outputFileHandle, installation)
 	if err != nil {
 		return errors.Wrap(err, "cannot write output file")
 	}
 
-	if bytesWritten != resp.ContentLength {
-		return fmt.Errorf("invalid response length (expected %d, got %d)", resp.ContentLength, bytesWritten)
+	if bytesWritten != contentLength {
+		return fmt.Errorf("invalid response length (expected %d, got %d)", contentLength, bytesWritten)
 	}
 
 	return nil
 }
 
+// OpenInstallationAssetCollection starts exporting the installation, so it can
+// be streamed somewhere other than a local file. The caller must close it.
+func (a Api) OpenInstallationAssetCollection() (io.ReadCloser, int64, error) {
+	resp, err := a.sendProgressAPIRequest("GET", "/api/v0/installation_asset_collection", nil)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "could not make api request to installation_asset_collection endpoint")
+	}
+
+	if err = validateStatusOK(resp); err != nil {
+		resp.Body.Close()
+		return nil, 0, err
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
 func (a Api) UploadInstallationAssetCollection(input ImportInstallationInput) error {
 	req, err := http.NewRequest("POST", "/api/v0/installation_asset_collection", input.Installation)
 	if err != nil {
@@ -118,6 +118,41 @@ var _ = Describe("InstallationAssetService", func() {
 		})
 	})
 
+	Describe("OpenInstallationAssetCollection", func() {
+		It("returns the installation to be streamed, with its length", func() {
+			progressClient.DoReturns(&http.Response{
+				StatusCode:    http.StatusOK,
+				ContentLength: int64(len("some-installation")),
+				Body:          ioutil.NopCloser(strings.NewReader("some-installation")),
+			}, nil)
+
+			installation, length, err := service.OpenInstallationAssetCollection()
+			Expect(err).ToNot(HaveOccurred())
+			defer installation.Close()
+
+			request := progressClient.DoArgsForCall(0)
+			Expect(request.Method).To(Equal("GET"))
+			Expect(request.URL.Path).To(Equal("/api/v0/installation_asset_collection"))
+
+			contents, err := ioutil.ReadAll(installation)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-installation"))
+			Expect(length).To(Equal(int64(17)))
+		})
+
+		When("the api returns a non-200 status code", func() {
+			It("returns an error", func() {
+				progressClient.DoReturns(&http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}, nil)
+
+				_, _, err := service.OpenInstallationAssetCollection()
+				Expect(err).To(MatchError(ContainSubstring("request failed: unexpected response")))
+			})
+		})
+	})
+
 	Describe("UploadInstallationAssetCollection", func() {
 		It("makes a request to import the installation to the Ops Manager", func() {
 			unauthedProgressClient.DoStub = func(req *http.Request) (*http.Response, error) {
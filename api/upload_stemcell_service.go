@@ -4,6 +4,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/pivotal-cf/om/network"
 	"github.com/pkg/errors"
 )
 
@@ -11,6 +12,7 @@ type StemcellUploadInput struct {
 	ContentLength int64
 	Stemcell      io.Reader
 	ContentType   string
+	HideProgress  bool
 }
 
 type StemcellUploadOutput struct{}
@@ -24,6 +26,8 @@ func (a Api) UploadStemcell(input StemcellUploadInput) (StemcellUploadOutput, er
 	req.Header.Set("Content-Type", input.ContentType)
 	req.ContentLength = input.ContentLength
 
+	req = req.WithContext(network.HideProgress(req.Context(), input.HideProgress))
+
 	resp, err := a.progressClient.Do(req)
 	if err != nil {
 		return StemcellUploadOutput{}, errors.Wrap(err, "could not make api request to stemcells endpoint")
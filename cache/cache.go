@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pivotal-cf/om/validator"
+)
+
+// Cache keeps files in a directory by their SHA256, so that a file that has
+// already been downloaded can be linked or copied instead of downloaded again.
+// Each file is kept as <directory>/<sha256>/<name>.
+type Cache struct {
+	directory string
+}
+
+type Entry struct {
+	SHA256   string
+	Name     string
+	Path     string
+	Size     int64
+	LastUsed time.Time
+}
+
+func New(directory string) Cache {
+	return Cache{directory: directory}
+}
+
+// Get finds the file with the checksum sum. The file is checked to still
+// have that checksum, and is removed from the cache when it does not.
+func (c Cache) Get(sum string) (Entry, bool, error) {
+	entry, ok, err := c.entry(sum)
+	if err != nil || !ok {
+		return Entry{}, false, err
+	}
+
+	calculated, err := validator.NewSHA256Calculator().Checksum(entry.Path)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	if calculated != sum {
+		return Entry{}, false, os.RemoveAll(filepath.Dir(entry.Path))
+	}
+
+	return entry, true, nil
+}
+
+// Add keeps the file at path, which has the checksum sum, in the cache.
+// The file is hard-linked when it is on the same filesystem, and copied
+// otherwise.
+func (c Cache) Add(sum, path string) error {
+	_, ok, err := c.entry(sum)
+	if err != nil || ok {
+		return err
+	}
+
+	entryDirectory := filepath.Join(c.directory, sum)
+	err = os.MkdirAll(entryDirectory, 0755)
+	if err != nil {
+		return err
+	}
+
+	// linked to a temporary name first, so that another download adding the
+	// same file never sees it half copied. The name is unique, as two
+	// downloads of the same file can add it at once.
+	temporaryFile, err := ioutil.TempFile(entryDirectory, "."+filepath.Base(path)+".*"+addingSuffix)
+	if err != nil {
+		return err
+	}
+	temporary := temporaryFile.Name()
+	_ = temporaryFile.Close()
+
+	// the file is only reserving the name, as a link cannot replace a file
+	err = os.Remove(temporary)
+	if err != nil {
+		return err
+	}
+
+	err = linkOrCopy(path, temporary)
+	if err != nil {
+		_ = os.Remove(temporary)
+		return err
+	}
+
+	return os.Rename(temporary, filepath.Join(entryDirectory, filepath.Base(path)))
+}
+
+// addingSuffix ends the temporary names of the files Add is adding.
+const addingSuffix = ".adding"
+
+// LinkTo hard-links, or copies, the file of entry to destination, and marks
+// it as used so that it is among the last to be pruned.
+func (c Cache) LinkTo(entry Entry, destination string) error {
+	now := time.Now()
+	err := os.Chtimes(entry.Path, now, now)
+	if err != nil {
+		return err
+	}
+
+	_ = os.Remove(destination)
+	return linkOrCopy(entry.Path, destination)
+}
+
+// Entries lists the files in the cache, the most recently used first.
+func (c Cache) Entries() ([]Entry, error) {
+	directories, err := ioutil.ReadDir(c.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, directory := range directories {
+		if !directory.IsDir() {
+			continue
+		}
+
+		entry, ok, err := c.entry(directory.Name())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+
+	return entries, nil
+}
+
+// Prune removes the least recently used files until the files in the cache
+// take up no more than maxSize bytes, and returns the files it removed.
+func (c Cache) Prune(maxSize int64) ([]Entry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		total   int64
+		removed []Entry
+	)
+	for _, entry := range entries {
+		total += entry.Size
+		if total <= maxSize {
+			continue
+		}
+
+		err = os.RemoveAll(filepath.Join(c.directory, entry.SHA256))
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, entry)
+	}
+
+	return removed, c.removeLeftovers()
+}
+
+// removeLeftovers removes the temporary files left by an Add that was
+// interrupted, which are not listed as entries. Files that are being added
+// right now are left alone, as they were linked or copied less than an hour
+// ago.
+func (c Cache) removeLeftovers() error {
+	directories, err := ioutil.ReadDir(c.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, directory := range directories {
+		if !directory.IsDir() {
+			continue
+		}
+
+		entryDirectory := filepath.Join(c.directory, directory.Name())
+		files, err := ioutil.ReadDir(entryDirectory)
+		if err != nil {
+			return err
+		}
+
+		remaining := len(files)
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), addingSuffix) || time.Since(file.ModTime()) < time.Hour {
+				continue
+			}
+
+			err = os.Remove(filepath.Join(entryDirectory, file.Name()))
+			if err != nil {
+				return err
+			}
+			remaining--
+		}
+
+		if remaining == 0 {
+			_ = os.Remove(entryDirectory)
+		}
+	}
+
+	return nil
+}
+
+func (c Cache) entry(sum string) (Entry, bool, error) {
+	files, err := ioutil.ReadDir(filepath.Join(c.directory, sum))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Entry{}, false, nil
+		}
+		return Entry{}, false, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		return Entry{
+			SHA256:   sum,
+			Name:     file.Name(),
+			Path:     filepath.Join(c.directory, sum, file.Name()),
+			Size:     file.Size(),
+			LastUsed: file.ModTime(),
+		}, true, nil
+	}
+
+	return Entry{}, false, nil
+}
+
+// linkOrCopy copies the file only when it cannot be linked because it is on
+// another filesystem. The copy never replaces a file, which could be linked
+// to another that is in use.
+func linkOrCopy(source, destination string) error {
+	err := os.Link(source, destination)
+	if linkErr, ok := err.(*os.LinkError); !ok || linkErr.Err != syscall.EXDEV {
+		return err
+	}
+
+	sourceFile, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destinationFile, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(destinationFile, sourceFile)
+	if err != nil {
+		destinationFile.Close()
+		return err
+	}
+
+	return destinationFile.Close()
+}
+
+var sizeUnits = []string{"B", "K", "M", "G", "T"}
+
+// ParseSize reads a number of bytes, such as 500M or 20G, with the units
+// being powers of 1024.
+func ParseSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	value = strings.TrimSuffix(value, "B")
+	if value == "" {
+		return 0, fmt.Errorf("could not parse size %q", size)
+	}
+
+	multiplier := int64(1)
+	for i, unit := range sizeUnits[1:] {
+		if strings.HasSuffix(value, unit) {
+			value = strings.TrimSuffix(value, unit)
+			multiplier = int64(1) << (10 * uint(i+1))
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("could not parse size %q: expected a number of bytes, or a number followed by K, M, G or T", size)
+	}
+
+	return int64(number * float64(multiplier)), nil
+}
+
+// FormatSize writes a number of bytes with the largest unit of ParseSize
+// that keeps it at least 1.
+func FormatSize(size int64) string {
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(sizeUnits)-1 {
+		value /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d%s", size, sizeUnits[unit])
+	}
+
+	return fmt.Sprintf("%.1f%s", value, sizeUnits[unit])
+}
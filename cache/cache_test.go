@@ -0,0 +1,202 @@
+package cache_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/cache"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		directory string
+		c         cache.Cache
+	)
+
+	sha := func(contents string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+	}
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(directory, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	readFile := func(path string) string {
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "om-cache-")
+		Expect(err).ToNot(HaveOccurred())
+
+		c = cache.New(filepath.Join(directory, "cache"))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(directory)).To(Succeed())
+	})
+
+	It("links a file that was added to another path", func() {
+		Expect(c.Add(sha("cf 2.7.5"), writeFile("cf-2.7.5.pivotal", "cf 2.7.5"))).To(Succeed())
+
+		entry, ok, err := c.Get(sha("cf 2.7.5"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(entry.Name).To(Equal("cf-2.7.5.pivotal"))
+		Expect(entry.Size).To(Equal(int64(8)))
+
+		destination := filepath.Join(directory, "[cf,2.7.5]cf-2.7.5.pivotal")
+		Expect(c.LinkTo(entry, destination)).To(Succeed())
+		Expect(readFile(destination)).To(Equal("cf 2.7.5"))
+	})
+
+	It("does not find a file that was not added", func() {
+		_, ok, err := c.Get(sha("cf 2.7.5"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("removes a file that no longer has its checksum", func() {
+		Expect(c.Add(sha("cf 2.7.5"), writeFile("cf-2.7.5.pivotal", "cf 2.7.5"))).To(Succeed())
+
+		entries, err := c.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(entries[0].Path, []byte("corrupted"), 0600)).To(Succeed())
+
+		_, ok, err := c.Get(sha("cf 2.7.5"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		entries, err = c.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("prunes the least recently used files until it is no larger than the max size", func() {
+		now := time.Now()
+		for _, name := range []string{"old", "older", "newest"} {
+			contents := name + " file"
+			Expect(c.Add(sha(contents), writeFile(name, contents))).To(Succeed())
+		}
+
+		entries, err := c.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		for _, entry := range entries {
+			age := map[string]time.Duration{"newest": 0, "old": time.Hour, "older": 2 * time.Hour}[entry.Name]
+			Expect(os.Chtimes(entry.Path, now.Add(-age), now.Add(-age))).To(Succeed())
+		}
+
+		removed, err := c.Prune(int64(len("newest file") + len("old file")))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(removed).To(HaveLen(1))
+		Expect(removed[0].Name).To(Equal("older"))
+
+		entries, err = c.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Name).To(Equal("newest"))
+		Expect(entries[1].Name).To(Equal("old"))
+	})
+
+	It("adds the same file from several downloads at once", func() {
+		var sources []string
+		for i := 0; i < 8; i++ {
+			Expect(os.Mkdir(filepath.Join(directory, fmt.Sprint(i)), 0700)).To(Succeed())
+			sources = append(sources, writeFile(filepath.Join(fmt.Sprint(i), "cf-2.7.5.pivotal"), "cf 2.7.5"))
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(sources))
+		for i, source := range sources {
+			wg.Add(1)
+			go func(i int, source string) {
+				defer wg.Done()
+				errs[i] = c.Add(sha("cf 2.7.5"), source)
+			}(i, source)
+		}
+		wg.Wait()
+
+		for i, source := range sources {
+			Expect(errs[i]).ToNot(HaveOccurred())
+			Expect(readFile(source)).To(Equal("cf 2.7.5"))
+		}
+
+		entries, err := c.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+
+		files, err := ioutil.ReadDir(filepath.Dir(entries[0].Path))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("prunes the files left by adding a file that was interrupted", func() {
+		Expect(c.Add(sha("cf 2.7.5"), writeFile("cf-2.7.5.pivotal", "cf 2.7.5"))).To(Succeed())
+
+		interrupted := filepath.Join(directory, "cache", sha("srt 2.7.5"))
+		Expect(os.Mkdir(interrupted, 0700)).To(Succeed())
+		left := filepath.Join(interrupted, ".srt-2.7.5.pivotal.123.adding")
+		Expect(ioutil.WriteFile(left, []byte("srt"), 0600)).To(Succeed())
+		adding := filepath.Join(interrupted, ".srt-2.7.5.pivotal.456.adding")
+		Expect(ioutil.WriteFile(adding, []byte("srt"), 0600)).To(Succeed())
+
+		anHourAgo := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(left, anHourAgo, anHourAgo)).To(Succeed())
+
+		removed, err := c.Prune(1024)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(removed).To(BeEmpty())
+		Expect(left).ToNot(BeAnExistingFile())
+		Expect(adding).To(BeAnExistingFile())
+
+		Expect(os.Chtimes(adding, anHourAgo, anHourAgo)).To(Succeed())
+		_, err = c.Prune(1024)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(interrupted).ToNot(BeADirectory())
+
+		entries, err := c.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	It("lists nothing when the directory does not exist", func() {
+		entries, err := c.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	DescribeTable("ParseSize", func(size string, expected int64) {
+		parsed, err := cache.ParseSize(size)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(expected))
+	},
+		Entry("bytes", "1000", int64(1000)),
+		Entry("kilobytes", "2K", int64(2048)),
+		Entry("megabytes", "500M", int64(500*1024*1024)),
+		Entry("gigabytes with a B", "1.5GB", int64(1536*1024*1024)),
+		Entry("lowercase", "20g", int64(20*1024*1024*1024)),
+	)
+
+	It("does not parse sizes that are not numbers", func() {
+		_, err := cache.ParseSize("lots")
+		Expect(err).To(MatchError(`could not parse size "lots": expected a number of bytes, or a number followed by K, M, G or T`))
+	})
+
+	It("formats sizes", func() {
+		Expect(cache.FormatSize(512)).To(Equal("512B"))
+		Expect(cache.FormatSize(9.5 * 1024 * 1024 * 1024)).To(Equal("9.5G"))
+	})
+})
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v2"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/pivotal-cf/jhanda"
@@ -15,7 +17,10 @@ type ApplyChanges struct {
 	service        applyChangesService
 	pendingService pendingChangesService
 	logger         logger
+	stderr         logger
 	logWriter      logWriter
+	eventWriter    installationEventWriter
+	interrupts     chan os.Signal
 	waitDuration   time.Duration
 	Options        struct {
 		Config             string   `short:"c"   long:"config"               description:"path to yml file containing errand configuration (see docs/apply-changes/README.md for format)"`
@@ -23,6 +28,8 @@ type ApplyChanges struct {
 		Reattach           bool     `long:"reattach" description:"reattach to an already running apply changes (if available)"`
 		SkipDeployProducts bool     `short:"sdp" long:"skip-deploy-products" description:"skip deploying products when applying changes - just update the director"`
 		ProductNames       []string `short:"n"   long:"product-name"         description:"name of the product(s) to deploy, cannot be used in conjunction with --skip-deploy-products (OM 2.2+)"`
+		Events             string   `long:"events"                           description:"print the installation log as structured events, followed by a timeline of each step (options: json)"`
+		Timeout            int      `long:"timeout"                          description:"timeout in seconds to wait for the installation to finish. the installation keeps running on Ops Manager"`
 	}
 }
 
@@ -41,12 +48,22 @@ type logWriter interface {
 	Flush(logs string) error
 }
 
-func NewApplyChanges(service applyChangesService, pendingService pendingChangesService, logWriter logWriter, logger logger, waitDuration time.Duration) ApplyChanges {
+//counterfeiter:generate -o ./fakes/installation_event_writer.go --fake-name InstallationEventWriter . installationEventWriter
+type installationEventWriter interface {
+	Flush(logs string) error
+	FlushFinished(logs string) error
+	WriteTimeline()
+}
+
+func NewApplyChanges(service applyChangesService, pendingService pendingChangesService, logWriter logWriter, eventWriter installationEventWriter, logger logger, stderr logger, interrupts chan os.Signal, waitDuration time.Duration) ApplyChanges {
 	return ApplyChanges{
 		service:        service,
 		pendingService: pendingService,
 		logger:         logger,
+		stderr:         stderr,
 		logWriter:      logWriter,
+		eventWriter:    eventWriter,
+		interrupts:     interrupts,
 		waitDuration:   waitDuration,
 	}
 }
@@ -56,6 +73,16 @@ func (ac ApplyChanges) Execute(args []string) error {
 		return fmt.Errorf("could not parse apply-changes flags: %s", err)
 	}
 
+	if ac.Options.Events != "" && ac.Options.Events != "json" {
+		return fmt.Errorf("unsupported events format %q: must be json", ac.Options.Events)
+	}
+
+	// with --events, only the events are printed to stdout, so that it can
+	// be read as JSON lines
+	if ac.Options.Events != "" {
+		ac.logger = ac.stderr
+	}
+
 	errands := api.ApplyErrandChanges{}
 
 	if ac.Options.Config != "" {
@@ -117,6 +144,19 @@ func (ac ApplyChanges) Execute(args []string) error {
 }
 
 func (ac ApplyChanges) waitForApplyChangesCompletion(installation api.InstallationsServiceOutput) error {
+	var writer logWriter = ac.logWriter
+	if ac.Options.Events != "" {
+		writer = ac.eventWriter
+	}
+
+	signal.Notify(ac.interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(ac.interrupts)
+
+	var timeout <-chan time.Time
+	if ac.Options.Timeout > 0 {
+		timeout = time.After(time.Duration(ac.Options.Timeout) * time.Second)
+	}
+
 	for {
 		current, err := ac.service.GetInstallation(installation.ID)
 		if err != nil {
@@ -128,18 +168,41 @@ func (ac ApplyChanges) waitForApplyChangesCompletion(installation api.Installati
 			return fmt.Errorf("installation failed to get logs: %s", err)
 		}
 
-		err = ac.logWriter.Flush(install.Logs)
+		finished := current.Status == api.StatusSucceeded || current.Status == api.StatusFailed
+		if finished && ac.Options.Events != "" {
+			err = ac.eventWriter.FlushFinished(install.Logs)
+		} else {
+			err = writer.Flush(install.Logs)
+		}
 		if err != nil {
 			return fmt.Errorf("installation failed to flush logs: %s", err)
 		}
 
 		if current.Status == api.StatusSucceeded {
+			ac.writeTimeline()
 			return nil
 		} else if current.Status == api.StatusFailed {
+			ac.writeTimeline()
 			return errors.New("installation was unsuccessful")
 		}
 
-		time.Sleep(ac.waitDuration)
+		select {
+		case sig := <-ac.interrupts:
+			ac.writeTimeline()
+			ac.logger.Printf("received %s: no longer waiting for the installation, which is still running on Ops Manager (Installation ID: %d)", sig, installation.ID)
+			return fmt.Errorf("stopped waiting for installation %d: received %s", installation.ID, sig)
+		case <-timeout:
+			ac.writeTimeline()
+			ac.logger.Printf("timed out: no longer waiting for the installation, which is still running on Ops Manager (Installation ID: %d)", installation.ID)
+			return fmt.Errorf("installation %d did not finish within %d seconds", installation.ID, ac.Options.Timeout)
+		case <-time.After(ac.waitDuration):
+		}
+	}
+}
+
+func (ac ApplyChanges) writeTimeline() {
+	if ac.Options.Events != "" {
+		ac.eventWriter.WriteTimeline()
 	}
 }
 
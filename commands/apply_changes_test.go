@@ -6,6 +6,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"os"
+	"syscall"
 	"time"
 
 	"github.com/pivotal-cf/jhanda"
@@ -23,6 +24,8 @@ var _ = Describe("ApplyChanges", func() {
 		pendingService *fakes.PendingChangesService
 		logger         *fakes.Logger
 		writer         *fakes.LogWriter
+		eventWriter    *fakes.InstallationEventWriter
+		interrupts     chan os.Signal
 		statusOutputs  []api.InstallationsServiceOutput
 		statusErrors   []error
 		logsOutputs    []api.InstallationsServiceOutput
@@ -36,6 +39,8 @@ var _ = Describe("ApplyChanges", func() {
 		pendingService = &fakes.PendingChangesService{}
 		logger = &fakes.Logger{}
 		writer = &fakes.LogWriter{}
+		eventWriter = &fakes.InstallationEventWriter{}
+		interrupts = make(chan os.Signal, 1)
 
 		statusCount = 0
 		logsCount = 0
@@ -79,7 +84,7 @@ var _ = Describe("ApplyChanges", func() {
 		})
 
 		It("applies changes to the Ops Manager", func() {
-			command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+			command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 			err := command.Execute([]string{})
 			Expect(err).ToNot(HaveOccurred())
@@ -105,11 +110,117 @@ var _ = Describe("ApplyChanges", func() {
 			Expect(writer.FlushArgsForCall(2)).To(Equal("some other logs"))
 		})
 
+		When("passed the events flag", func() {
+			It("flushes the logs as events and writes a timeline when the installation finishes", func() {
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
+
+				err := command.Execute([]string{"--events", "json"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(writer.FlushCallCount()).To(Equal(0))
+				Expect(eventWriter.FlushCallCount()).To(Equal(2))
+				Expect(eventWriter.FlushArgsForCall(1)).To(Equal("these logs"))
+				Expect(eventWriter.FlushFinishedCallCount()).To(Equal(1))
+				Expect(eventWriter.FlushFinishedArgsForCall(0)).To(Equal("some other logs"))
+				Expect(eventWriter.WriteTimelineCallCount()).To(Equal(1))
+			})
+
+			It("logs to stderr, so that stdout only has the events", func() {
+				stderr := &fakes.Logger{}
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, stderr, interrupts, 1)
+
+				err := command.Execute([]string{"--events", "json"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(logger.PrintfCallCount()).To(Equal(0))
+				format, content := stderr.PrintfArgsForCall(0)
+				Expect(fmt.Sprintf(format, content...)).To(Equal("attempting to apply changes to the targeted Ops Manager"))
+			})
+
+			It("writes a timeline when the installation fails", func() {
+				statusOutputs = []api.InstallationsServiceOutput{{Status: "failed"}}
+				statusErrors = []error{nil}
+				logsOutputs = []api.InstallationsServiceOutput{{Logs: "some logs"}}
+				logsErrors = []error{nil}
+
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
+
+				err := command.Execute([]string{"--events", "json"})
+				Expect(err).To(MatchError("installation was unsuccessful"))
+				Expect(eventWriter.FlushFinishedArgsForCall(0)).To(Equal("some logs"))
+				Expect(eventWriter.WriteTimelineCallCount()).To(Equal(1))
+			})
+
+			It("does not write a timeline without the flag", func() {
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
+
+				err := command.Execute([]string{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(eventWriter.FlushCallCount()).To(Equal(0))
+				Expect(eventWriter.WriteTimelineCallCount()).To(Equal(0))
+			})
+
+			It("returns an error for an unsupported format", func() {
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
+
+				err := command.Execute([]string{"--events", "xml"})
+				Expect(err).To(MatchError(`unsupported events format "xml": must be json`))
+				Expect(service.CreateInstallationCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the installation does not finish before the timeout", func() {
+			BeforeEach(func() {
+				service.GetInstallationStub = nil
+				service.GetInstallationReturns(api.InstallationsServiceOutput{Status: "running"}, nil)
+				service.GetInstallationLogsStub = nil
+				service.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: "some logs"}, nil)
+			})
+
+			It("stops waiting and leaves the installation running", func() {
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 10*time.Millisecond)
+
+				err := command.Execute([]string{"--timeout", "1"})
+				Expect(err).To(MatchError("installation 311 did not finish within 1 seconds"))
+
+				format, content := logger.PrintfArgsForCall(logger.PrintfCallCount() - 1)
+				Expect(fmt.Sprintf(format, content...)).To(Equal("timed out: no longer waiting for the installation, which is still running on Ops Manager (Installation ID: 311)"))
+			})
+
+			It("writes a timeline", func() {
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 10*time.Millisecond)
+
+				err := command.Execute([]string{"--timeout", "1", "--events", "json"})
+				Expect(err).To(MatchError("installation 311 did not finish within 1 seconds"))
+				Expect(eventWriter.WriteTimelineCallCount()).To(Equal(1))
+			})
+		})
+
+		When("a signal is received while waiting", func() {
+			It("stops waiting and leaves the installation running", func() {
+				service.GetInstallationStub = func(id int) (api.InstallationsServiceOutput, error) {
+					interrupts <- syscall.SIGTERM
+					return api.InstallationsServiceOutput{Status: "running"}, nil
+				}
+				service.GetInstallationLogsStub = nil
+
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, time.Minute)
+
+				err := command.Execute([]string{})
+				Expect(err).To(MatchError("stopped waiting for installation 311: received terminated"))
+				Expect(service.GetInstallationCallCount()).To(Equal(1))
+
+				format, content := logger.PrintfArgsForCall(logger.PrintfCallCount() - 1)
+				Expect(fmt.Sprintf(format, content...)).To(Equal("received terminated: no longer waiting for the installation, which is still running on Ops Manager (Installation ID: 311)"))
+			})
+		})
+
 		When("passed the ignore-warnings flag", func() {
 			It("applies changes while ignoring warnings", func() {
 				service.InfoReturns(api.Info{Version: "2.3-build43"}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 				err := command.Execute([]string{"--ignore-warnings"})
 				Expect(err).ToNot(HaveOccurred())
@@ -121,7 +232,7 @@ var _ = Describe("ApplyChanges", func() {
 
 		When("passed the skip-deploy-products flag", func() {
 			It("applies changes while not deploying products", func() {
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 				err := command.Execute([]string{"--skip-deploy-products"})
 				Expect(err).ToNot(HaveOccurred())
@@ -131,7 +242,7 @@ var _ = Describe("ApplyChanges", func() {
 			})
 
 			It("fails if product names were specified", func() {
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 				err := command.Execute([]string{"--skip-deploy-products", "--product-name", "product1"})
 				Expect(err).To(HaveOccurred())
 			})
@@ -143,7 +254,7 @@ var _ = Describe("ApplyChanges", func() {
 				service.CreateInstallationReturns(api.InstallationsServiceOutput{}, errors.New("error"))
 				service.RunningInstallationReturns(api.InstallationsServiceOutput{}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 				err := command.Execute([]string{"--product-name", "product1", "--product-name", "product2"})
 				Expect(err).To(HaveOccurred())
 
@@ -162,7 +273,7 @@ var _ = Describe("ApplyChanges", func() {
 					StartedAt: &installationStartedAt,
 				}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 				err := command.Execute([]string{"--reattach"})
 				Expect(err).ToNot(HaveOccurred())
@@ -189,7 +300,7 @@ var _ = Describe("ApplyChanges", func() {
 					StartedAt: &installationStartedAt,
 				}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 				err := command.Execute([]string{})
 				Expect(err).To(HaveOccurred())
@@ -261,7 +372,7 @@ errands:
 				})
 
 				It("calls the api with correct arguments", func() {
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{"--config", fileName})
 					Expect(err).ToNot(HaveOccurred())
@@ -310,7 +421,7 @@ errands:
 
 			Context("given a file that does not exist", func() {
 				It("returns an error", func() {
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{"--config", "filedoesnotexist"})
 					Expect(err).To(MatchError("could not load config: open filedoesnotexist: no such file or directory"))
@@ -332,7 +443,7 @@ errands: lolololol
 				})
 
 				It("returns an error", func() {
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{"--config", fileName})
 					Expect(err).To(MatchError(ContainSubstring("line 3: cannot unmarshal !!str `lolololol`")))
@@ -354,7 +465,7 @@ errands: lolololol
 
 			logsErrors = []error{nil}
 
-			command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+			command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 			err := command.Execute([]string{})
 			Expect(err).To(MatchError("installation was unsuccessful"))
@@ -365,7 +476,7 @@ errands: lolololol
 				It("returns an error", func() {
 					service.RunningInstallationReturns(api.InstallationsServiceOutput{}, errors.New("some error"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("could not check for any already running installation: some error"))
@@ -378,7 +489,7 @@ errands: lolololol
 					for _, version := range versions {
 						service.InfoReturns(api.Info{Version: version}, nil)
 
-						command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+						command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 						err := command.Execute([]string{"--product-name", "p-mysql"})
 						Expect(err).To(MatchError(fmt.Sprintf("--product-name is only available with Ops Manager 2.2 or later: you are running %s", version)))
 					}
@@ -389,7 +500,7 @@ errands: lolololol
 				It("returns an error", func() {
 					service.CreateInstallationReturns(api.InstallationsServiceOutput{}, errors.New("some error"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to trigger: some error"))
@@ -404,7 +515,7 @@ errands: lolololol
 
 					statusErrors = []error{errors.New("another error")}
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to get status: another error"))
@@ -425,7 +536,7 @@ errands: lolololol
 
 					logsErrors = []error{errors.New("no")}
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to get logs: no"))
@@ -448,7 +559,7 @@ errands: lolololol
 
 					writer.FlushReturns(errors.New("yes"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, eventWriter, logger, logger, interrupts, 1)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to flush logs: yes"))
@@ -459,7 +570,7 @@ errands: lolololol
 
 	Describe("Usage", func() {
 		It("returns usage information for the command", func() {
-			command := commands.NewApplyChanges(nil, nil, nil, nil, 1)
+			command := commands.NewApplyChanges(nil, nil, nil, nil, nil, nil, nil, 1)
 			Expect(command.Usage()).To(Equal(jhanda.Usage{
 				Description:      "This authenticated command kicks off an install of any staged changes on the Ops Manager.",
 				ShortDescription: "triggers an install on the Ops Manager targeted",
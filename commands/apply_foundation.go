@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/extractor"
+	"github.com/pivotal-cf/om/interpolate"
+	"gopkg.in/yaml.v2"
+)
+
+type ApplyFoundation struct {
+	environFunc       func() []string
+	commands          jhanda.CommandSet
+	metadataExtractor metadataExtractor
+	logger            logger
+	Options           struct {
+		Manifest       string   `long:"manifest"        short:"m" required:"true" description:"path to yml file describing the director, stemcells and products of the foundation (see docs/apply-foundation/README.md for format)"`
+		VarsFile       []string `long:"vars-file"       short:"l"                 description:"Load variables from a YAML file"`
+		Vars           []string `long:"var"             short:"v"                 description:"Load variable from the command line. Format: VAR=VAL"`
+		VarsEnv        []string `long:"vars-env"                                  description:"Load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
+		OpsFile        []string `long:"ops-file"        short:"o"                 description:"YAML operations file"`
+		ApplyChanges   bool     `long:"apply-changes"                             description:"run apply-changes once everything has been staged and configured"`
+		IgnoreWarnings bool     `long:"ignore-warnings" short:"i"                 description:"ignore issues reported by Ops Manager when applying changes"`
+	}
+}
+
+type foundationManifest struct {
+	Director  *foundationConfigStep `yaml:"director"`
+	Stemcells []foundationStemcell  `yaml:"stemcells"`
+	Products  []foundationProduct   `yaml:"products"`
+}
+
+type foundationConfigStep struct {
+	Config    string   `yaml:"config"`
+	VarsFiles []string `yaml:"vars-files"`
+	Vars      []string `yaml:"vars"`
+	OpsFiles  []string `yaml:"ops-files"`
+}
+
+type foundationStemcell struct {
+	File   string `yaml:"file"`
+	Shasum string `yaml:"shasum"`
+}
+
+type foundationProduct struct {
+	Name                 string            `yaml:"product-name"`
+	Version              string            `yaml:"product-version"`
+	File                 string            `yaml:"file"`
+	Shasum               string            `yaml:"shasum"`
+	Stemcells            []string          `yaml:"stemcells"`
+	Errands              api.ProductErrand `yaml:"errands"`
+	foundationConfigStep `yaml:",inline"`
+}
+
+func NewApplyFoundation(environFunc func() []string, commands jhanda.CommandSet, metadataExtractor metadataExtractor, logger logger) ApplyFoundation {
+	return ApplyFoundation{
+		environFunc:       environFunc,
+		commands:          commands,
+		metadataExtractor: metadataExtractor,
+		logger:            logger,
+	}
+}
+
+func (af ApplyFoundation) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command reads a manifest describing a whole foundation and converges Ops Manager to it by running configure-director, upload-stemcell, upload-product, stage-product, configure-product and assign-multi-stemcell in dependency order, and optionally apply-changes.",
+		ShortDescription: "**EXPERIMENTAL** uploads, stages and configures everything described in a foundation manifest",
+		Flags:            af.Options,
+	}
+}
+
+func (af ApplyFoundation) Execute(args []string) error {
+	if _, err := jhanda.Parse(&af.Options, args); err != nil {
+		return fmt.Errorf("could not parse apply-foundation flags: %s", err)
+	}
+
+	manifest, err := af.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	manifest.Products, err = af.orderProducts(manifest.Products)
+	if err != nil {
+		return err
+	}
+
+	if manifest.Director != nil {
+		err = af.run("configure-director", configStepArgs(*manifest.Director)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, stemcell := range manifest.Stemcells {
+		stemcellArgs := []string{"--stemcell", stemcell.File}
+		if stemcell.Shasum != "" {
+			stemcellArgs = append(stemcellArgs, "--shasum", stemcell.Shasum)
+		}
+
+		err = af.run("upload-stemcell", stemcellArgs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, product := range manifest.Products {
+		err = af.applyProduct(product)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !af.Options.ApplyChanges {
+		errands := manifestErrands(manifest)
+		if len(errands.Errands) > 0 {
+			var products []string
+			for _, product := range manifest.Products {
+				if _, ok := errands.Errands[product.Name]; ok {
+					products = append(products, product.Name)
+				}
+			}
+			af.logger.Printf("warning: the errands of %s were not applied, as they are only used by apply-changes: use --apply-changes to apply them", strings.Join(products, ", "))
+		}
+
+		af.logger.Printf("finished applying foundation manifest %s", af.Options.Manifest)
+		return nil
+	}
+
+	return af.applyChanges(manifest)
+}
+
+func (af ApplyFoundation) loadManifest() (foundationManifest, error) {
+	varsEnvs := af.Options.VarsEnv
+	if value, ok := os.LookupEnv("OM_VARS_ENV"); ok {
+		// EXPERIMENTAL: don't put this directly in VarsEnv
+		varsEnvs = append(varsEnvs, value)
+	}
+
+	contents, err := interpolate.Execute(interpolate.Options{
+		TemplateFile:  af.Options.Manifest,
+		VarsFiles:     af.Options.VarsFile,
+		Vars:          af.Options.Vars,
+		EnvironFunc:   af.environFunc,
+		VarsEnvs:      varsEnvs,
+		OpsFiles:      af.Options.OpsFile,
+		ExpectAllKeys: true,
+	})
+	if err != nil {
+		return foundationManifest{}, err
+	}
+
+	var manifest foundationManifest
+	err = yaml.UnmarshalStrict(contents, &manifest)
+	if err != nil {
+		return foundationManifest{}, fmt.Errorf("could not be parsed as valid manifest: %s: %s", af.Options.Manifest, err)
+	}
+
+	if manifest.Director != nil && manifest.Director.Config == "" {
+		return foundationManifest{}, fmt.Errorf("manifest %s: \"director.config\" is required", af.Options.Manifest)
+	}
+
+	for index, stemcell := range manifest.Stemcells {
+		if stemcell.File == "" {
+			return foundationManifest{}, fmt.Errorf("manifest %s: \"file\" is required for stemcells[%d]", af.Options.Manifest, index)
+		}
+	}
+
+	for index, product := range manifest.Products {
+		if product.Name == "" || product.Version == "" {
+			return foundationManifest{}, fmt.Errorf("manifest %s: \"product-name\" and \"product-version\" are required for products[%d]", af.Options.Manifest, index)
+		}
+	}
+
+	return manifest, nil
+}
+
+// orderProducts puts each product after the products in the manifest that
+// its tile requires, so that they are staged before it. Products are
+// otherwise kept in the order of the manifest. The requirements of products
+// without a file are not known, as their tile is not read.
+func (af ApplyFoundation) orderProducts(products []foundationProduct) ([]foundationProduct, error) {
+	inManifest := map[string]bool{}
+	for _, product := range products {
+		inManifest[product.Name] = true
+	}
+
+	requires := map[string][]string{}
+	for _, product := range products {
+		if product.File == "" {
+			continue
+		}
+
+		metadata, err := af.metadataExtractor.ExtractMetadata(product.File)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the metadata of %s: %s", product.File, err)
+		}
+
+		inspection, err := extractor.InspectMetadata(metadata.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the metadata of %s: %s", product.File, err)
+		}
+
+		for _, dependency := range inspection.ProductDependencies {
+			if inManifest[dependency.Name] && dependency.Name != product.Name {
+				requires[product.Name] = append(requires[product.Name], dependency.Name)
+			}
+		}
+	}
+
+	var ordered []foundationProduct
+	placed := map[string]bool{}
+	remaining := products
+	for len(remaining) > 0 {
+		next := -1
+		for index, product := range remaining {
+			ready := true
+			for _, dependency := range requires[product.Name] {
+				ready = ready && placed[dependency]
+			}
+
+			if ready {
+				next = index
+				break
+			}
+		}
+
+		if next < 0 {
+			var names []string
+			for _, product := range remaining {
+				names = append(names, product.Name)
+			}
+			return nil, fmt.Errorf("manifest %s: products %s cannot be ordered, as their tiles require each other", af.Options.Manifest, strings.Join(names, ", "))
+		}
+
+		ordered = append(ordered, remaining[next])
+		placed[remaining[next].Name] = true
+		remaining = append(remaining[:next:next], remaining[next+1:]...)
+	}
+
+	for index, product := range ordered {
+		if product.Name != products[index].Name {
+			var names []string
+			for _, product := range ordered {
+				names = append(names, product.Name)
+			}
+			af.logger.Printf("applying products in the order their tiles require them: %s", strings.Join(names, ", "))
+			break
+		}
+	}
+
+	return ordered, nil
+}
+
+func (af ApplyFoundation) applyProduct(product foundationProduct) error {
+	if product.File != "" {
+		uploadArgs := []string{"--product", product.File, "--product-version", product.Version}
+		if product.Shasum != "" {
+			uploadArgs = append(uploadArgs, "--shasum", product.Shasum)
+		}
+
+		err := af.run("upload-product", uploadArgs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := af.run("stage-product", "--product-name", product.Name, "--product-version", product.Version)
+	if err != nil {
+		return err
+	}
+
+	if product.Config != "" {
+		err = af.run("configure-product", configStepArgs(product.foundationConfigStep)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(product.Stemcells) > 0 {
+		stemcellArgs := []string{"--product", product.Name}
+		for _, stemcell := range product.Stemcells {
+			stemcellArgs = append(stemcellArgs, "--stemcell", stemcell)
+		}
+
+		err = af.run("assign-multi-stemcell", stemcellArgs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (af ApplyFoundation) applyChanges(manifest foundationManifest) error {
+	var applyArgs []string
+	if af.Options.IgnoreWarnings {
+		applyArgs = append(applyArgs, "--ignore-warnings")
+	}
+
+	errands := manifestErrands(manifest)
+	if len(errands.Errands) > 0 {
+		contents, err := yaml.Marshal(errands)
+		if err != nil {
+			return fmt.Errorf("could not marshal errand configuration: %s", err) // un-tested
+		}
+
+		errandsFile, err := ioutil.TempFile("", "errands.yml")
+		if err != nil {
+			return fmt.Errorf("could not create errand configuration: %s", err) // un-tested
+		}
+		defer os.Remove(errandsFile.Name())
+
+		_, err = errandsFile.Write(contents)
+		errandsFile.Close()
+		if err != nil {
+			return fmt.Errorf("could not write errand configuration: %s", err) // un-tested
+		}
+
+		applyArgs = append(applyArgs, "--config", errandsFile.Name())
+	}
+
+	return af.run("apply-changes", applyArgs...)
+}
+
+// manifestErrands combines the errands of every product in the manifest
+// into the errand configuration of apply-changes.
+func manifestErrands(manifest foundationManifest) api.ApplyErrandChanges {
+	errands := api.ApplyErrandChanges{Errands: map[string]api.ProductErrand{}}
+	for _, product := range manifest.Products {
+		if product.Errands.RunPostDeploy != nil || product.Errands.RunPreDelete != nil {
+			errands.Errands[product.Name] = product.Errands
+		}
+	}
+	return errands
+}
+
+func (af ApplyFoundation) run(command string, args ...string) error {
+	af.logger.Printf("running %s", command)
+	return af.commands.Execute(command, args)
+}
+
+func configStepArgs(step foundationConfigStep) []string {
+	args := []string{"--config", step.Config}
+	for _, varsFile := range step.VarsFiles {
+		args = append(args, "--vars-file", varsFile)
+	}
+	for _, v := range step.Vars {
+		args = append(args, "--var", v)
+	}
+	for _, opsFile := range step.OpsFiles {
+		args = append(args, "--ops-file", opsFile)
+	}
+	return args
+}
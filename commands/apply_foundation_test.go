@@ -0,0 +1,300 @@
+package commands_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/extractor"
+)
+
+var _ = Describe("ApplyFoundation", func() {
+	var (
+		logger        *fakes.Logger
+		fakeExtractor *fakes.MetadataExtractor
+		calls         []string
+		errandsYAML   string
+		failing       map[string]error
+		command       commands.ApplyFoundation
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		fakeExtractor = &fakes.MetadataExtractor{}
+		calls = nil
+		errandsYAML = ""
+		failing = map[string]error{}
+
+		commandSet := jhanda.CommandSet{}
+		for _, name := range []string{
+			"apply-changes",
+			"assign-multi-stemcell",
+			"configure-director",
+			"configure-product",
+			"stage-product",
+			"upload-product",
+			"upload-stemcell",
+		} {
+			name := name
+			commandSet[name] = recordingCommand{execute: func(args []string) error {
+				calls = append(calls, strings.TrimSpace(name+" "+strings.Join(args, " ")))
+
+				if name == "apply-changes" {
+					for i, arg := range args {
+						if arg == "--config" {
+							contents, err := ioutil.ReadFile(args[i+1])
+							Expect(err).ToNot(HaveOccurred())
+							errandsYAML = string(contents)
+						}
+					}
+				}
+
+				return failing[name]
+			}}
+		}
+
+		command = commands.NewApplyFoundation(func() []string { return nil }, commandSet, fakeExtractor, logger)
+	})
+
+	It("uploads, stages and configures everything in dependency order", func() {
+		manifest := writeTestConfigFile(`---
+director:
+  config: director.yml
+  vars-files: [director-vars.yml]
+  vars: [az=us-east-1a]
+  ops-files: [director-ops.yml]
+stemcells:
+- file: stemcell.tgz
+  shasum: some-stemcell-sha
+products:
+- product-name: cf
+  product-version: 2.7.0
+  file: cf.pivotal
+  config: cf.yml
+  vars-files: [cf-vars.yml]
+  stemcells: [ubuntu-xenial:456.30]
+- product-name: p-healthwatch
+  product-version: 1.8.0
+  config: healthwatch.yml
+`)
+
+		err := command.Execute([]string{"--manifest", manifest})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calls).To(Equal([]string{
+			"configure-director --config director.yml --vars-file director-vars.yml --var az=us-east-1a --ops-file director-ops.yml",
+			"upload-stemcell --stemcell stemcell.tgz --shasum some-stemcell-sha",
+			"upload-product --product cf.pivotal --product-version 2.7.0",
+			"stage-product --product-name cf --product-version 2.7.0",
+			"configure-product --config cf.yml --vars-file cf-vars.yml",
+			"assign-multi-stemcell --product cf --stemcell ubuntu-xenial:456.30",
+			"stage-product --product-name p-healthwatch --product-version 1.8.0",
+			"configure-product --config healthwatch.yml",
+		}))
+
+		format, content := logger.PrintfArgsForCall(logger.PrintfCallCount() - 1)
+		Expect(fmt.Sprintf(format, content...)).To(Equal(fmt.Sprintf("finished applying foundation manifest %s", manifest)))
+	})
+
+	It("stages a product after the products its tile requires", func() {
+		fakeExtractor.ExtractMetadataStub = func(file string) (extractor.Metadata, error) {
+			if file == "pas-windows.pivotal" {
+				return extractor.Metadata{Raw: []byte(`{name: pas-windows, requires_product_versions: [{name: cf, version: ~> 2.7}, {name: p-bosh, version: ~> 2.7}]}`)}, nil
+			}
+			return extractor.Metadata{Raw: []byte(`{name: cf}`)}, nil
+		}
+
+		manifest := writeTestConfigFile(`---
+products:
+- product-name: pas-windows
+  product-version: 2.7.0
+  file: pas-windows.pivotal
+- product-name: cf
+  product-version: 2.7.0
+  file: cf.pivotal
+`)
+
+		err := command.Execute([]string{"--manifest", manifest})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calls).To(Equal([]string{
+			"upload-product --product cf.pivotal --product-version 2.7.0",
+			"stage-product --product-name cf --product-version 2.7.0",
+			"upload-product --product pas-windows.pivotal --product-version 2.7.0",
+			"stage-product --product-name pas-windows --product-version 2.7.0",
+		}))
+
+		format, content := logger.PrintfArgsForCall(0)
+		Expect(fmt.Sprintf(format, content...)).To(Equal("applying products in the order their tiles require them: cf, pas-windows"))
+	})
+
+	It("interpolates the manifest before reading it", func() {
+		manifest := writeTestConfigFile(`---
+products:
+- product-name: cf
+  product-version: ((cf_version))
+`)
+
+		err := command.Execute([]string{"--manifest", manifest, "--var", "cf_version=2.7.1"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calls).To(Equal([]string{
+			"stage-product --product-name cf --product-version 2.7.1",
+		}))
+	})
+
+	When("the manifest has errands but --apply-changes is not set", func() {
+		It("warns that the errands were not applied", func() {
+			manifest := writeTestConfigFile(`---
+products:
+- product-name: cf
+  product-version: 2.7.0
+  errands:
+    run_post_deploy:
+      smoke_tests: false
+- product-name: p-healthwatch
+  product-version: 1.8.0
+`)
+
+			err := command.Execute([]string{"--manifest", manifest})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(calls).To(Equal([]string{
+				"stage-product --product-name cf --product-version 2.7.0",
+				"stage-product --product-name p-healthwatch --product-version 1.8.0",
+			}))
+
+			var lines []string
+			for i := 0; i < logger.PrintfCallCount(); i++ {
+				format, content := logger.PrintfArgsForCall(i)
+				lines = append(lines, fmt.Sprintf(format, content...))
+			}
+			Expect(lines).To(ContainElement("warning: the errands of cf were not applied, as they are only used by apply-changes: use --apply-changes to apply them"))
+		})
+	})
+
+	When("--apply-changes is set", func() {
+		It("runs apply-changes with the errands from the manifest", func() {
+			manifest := writeTestConfigFile(`---
+products:
+- product-name: cf
+  product-version: 2.7.0
+  errands:
+    run_post_deploy:
+      smoke_tests: false
+`)
+
+			err := command.Execute([]string{"--manifest", manifest, "--apply-changes", "--ignore-warnings"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(calls).To(HaveLen(2))
+			Expect(calls[1]).To(HavePrefix("apply-changes --ignore-warnings --config "))
+			Expect(errandsYAML).To(MatchYAML(`{errands: {cf: {run_post_deploy: {smoke_tests: false}, run_pre_delete: {}}}}`))
+		})
+
+		It("does not pass a config when there are no errands", func() {
+			manifest := writeTestConfigFile(`products: []`)
+
+			err := command.Execute([]string{"--manifest", manifest, "--apply-changes"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(calls).To(Equal([]string{"apply-changes"}))
+		})
+	})
+
+	Context("failure cases", func() {
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse apply-foundation flags: flag provided but not defined: -badflag"))
+			})
+		})
+
+		When("the manifest contains unknown keys", func() {
+			It("returns an error", func() {
+				manifest := writeTestConfigFile(`unknown-key: true`)
+
+				err := command.Execute([]string{"--manifest", manifest})
+				Expect(err).To(MatchError(ContainSubstring("could not be parsed as valid manifest")))
+			})
+		})
+
+		When("a product is missing its version", func() {
+			It("returns an error before running anything", func() {
+				manifest := writeTestConfigFile(`{director: {config: director.yml}, products: [{product-name: cf}]}`)
+
+				err := command.Execute([]string{"--manifest", manifest})
+				Expect(err).To(MatchError(ContainSubstring(`"product-name" and "product-version" are required for products[0]`)))
+				Expect(calls).To(BeEmpty())
+			})
+		})
+
+		When("the tiles of products require each other", func() {
+			It("returns an error before running anything", func() {
+				fakeExtractor.ExtractMetadataStub = func(file string) (extractor.Metadata, error) {
+					if file == "a.pivotal" {
+						return extractor.Metadata{Raw: []byte(`{requires_product_versions: [{name: b, version: 1.0.0}]}`)}, nil
+					}
+					return extractor.Metadata{Raw: []byte(`{requires_product_versions: [{name: a, version: 1.0.0}]}`)}, nil
+				}
+
+				manifest := writeTestConfigFile(`{products: [{product-name: a, product-version: 1.0.0, file: a.pivotal}, {product-name: b, product-version: 1.0.0, file: b.pivotal}]}`)
+
+				err := command.Execute([]string{"--manifest", manifest})
+				Expect(err).To(MatchError(ContainSubstring("products a, b cannot be ordered, as their tiles require each other")))
+				Expect(calls).To(BeEmpty())
+			})
+		})
+
+		When("the metadata of a product cannot be read", func() {
+			It("returns an error before running anything", func() {
+				fakeExtractor.ExtractMetadataReturns(extractor.Metadata{}, errors.New("not a zip file"))
+
+				manifest := writeTestConfigFile(`{products: [{product-name: cf, product-version: 2.7.0, file: cf.pivotal}]}`)
+
+				err := command.Execute([]string{"--manifest", manifest})
+				Expect(err).To(MatchError("could not read the metadata of cf.pivotal: not a zip file"))
+				Expect(calls).To(BeEmpty())
+			})
+		})
+
+		When("a step fails", func() {
+			It("stops and returns the error", func() {
+				failing["stage-product"] = errors.New("could not stage")
+				manifest := writeTestConfigFile(`{products: [{product-name: cf, product-version: 2.7.0, config: cf.yml}]}`)
+
+				err := command.Execute([]string{"--manifest", manifest, "--apply-changes"})
+				Expect(err).To(MatchError(`could not execute "stage-product": could not stage`))
+				Expect(calls).To(Equal([]string{"stage-product --product-name cf --product-version 2.7.0"}))
+			})
+		})
+	})
+
+	Describe("Usage", func() {
+		It("returns usage information for the command", func() {
+			Expect(command.Usage()).To(Equal(jhanda.Usage{
+				Description:      "This authenticated command reads a manifest describing a whole foundation and converges Ops Manager to it by running configure-director, upload-stemcell, upload-product, stage-product, configure-product and assign-multi-stemcell in dependency order, and optionally apply-changes.",
+				ShortDescription: "**EXPERIMENTAL** uploads, stages and configures everything described in a foundation manifest",
+				Flags:            command.Options,
+			}))
+		})
+	})
+})
+
+type recordingCommand struct {
+	execute func(args []string) error
+}
+
+func (r recordingCommand) Execute(args []string) error {
+	return r.execute(args)
+}
+
+func (r recordingCommand) Usage() jhanda.Usage {
+	return jhanda.Usage{}
+}
@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+//counterfeiter:generate -o ./fakes/blobstore.go --fake-name Blobstore . Blobstore
+
+// Blobstore reads and writes files in a bucket. Implementations for s3, gcs
+// and azure are registered by the download_clients package.
+type Blobstore interface {
+	Name() string
+	Put(name string, r io.Reader, size int64) error
+	Open(name string) (io.ReadCloser, int64, error)
+	List(prefix string) ([]BlobstoreFile, error)
+	Delete(name string) error
+}
+
+type BlobstoreFile struct {
+	Name         string
+	Size         int64
+	LastModified time.Time
+}
+
+// BlobstoreOptions has the same meaning as the blobstore flags of
+// download-product, so that a single configuration can be shared.
+type BlobstoreOptions struct {
+	Bucket string
+
+	GCSServiceAccountJSON string
+	GCSProjectID          string
+
+	S3AccessKeyID     string
+	S3AuthType        string
+	S3SecretAccessKey string
+	S3RegionName      string
+	S3Endpoint        string
+	S3DisableSSL      bool
+	S3EnableV2Signing bool
+
+	AzureStorageAccount string
+	AzureKey            string
+}
+
+// BlobstoreFlags are the blobstore flags of the commands that read from or
+// write to a blobstore, named as those of download-product. They are embedded
+// in the options of those commands.
+type BlobstoreFlags struct {
+	Bucket string `long:"blobstore-bucket" alias:"s3-bucket,gcs-bucket,azure-container" description:"bucket name in the s3|gcs|azure compatible blobstore (the container for azure)"`
+
+	GCSServiceAccountJSON string `long:"gcs-service-account-json" alias:"gcp-service-account-json" description:"the service account key JSON"`
+	GCSProjectID          string `long:"gcs-project-id" alias:"gcp-project-id" description:"the project id for the bucket's gcp account"`
+
+	S3AccessKeyID     string `long:"s3-access-key-id"                 description:"access key for the s3 compatible blobstore"`
+	S3AuthType        string `long:"s3-auth-type"                     description:"can be set to \"iam\" in order to allow use of instance credentials" default:"accesskey"`
+	S3SecretAccessKey string `long:"s3-secret-access-key"             description:"secret key for the s3 compatible blobstore"`
+	S3RegionName      string `long:"s3-region-name"                   description:"bucket region in the s3 compatible blobstore. If not using AWS, this value is 'region'"`
+	S3Endpoint        string `long:"s3-endpoint"                      description:"the endpoint to access the s3 compatible blobstore. If not using AWS, this is required"`
+	S3DisableSSL      bool   `long:"s3-disable-ssl"                   description:"whether to disable ssl validation when contacting the s3 compatible blobstore"`
+	S3EnableV2Signing bool   `long:"s3-enable-v2-signing"             description:"whether to use v2 signing with your s3 compatible blobstore. (if you don't know what this is, leave blank, or set to 'false')"`
+
+	AzureStorageAccount string `long:"azure-storage-account" description:"the name of the storage account where the container exists"`
+	AzureKey            string `long:"azure-storage-key" description:"the access key for the storage account"`
+}
+
+// blobstoreOptions are the options of newBlobstore for the flags, which
+// have the same fields.
+func (f BlobstoreFlags) blobstoreOptions() BlobstoreOptions {
+	return BlobstoreOptions(f)
+}
+
+type BlobstoreRegistration func(options BlobstoreOptions) (Blobstore, error)
+
+var blobstores = make(map[string]BlobstoreRegistration)
+
+func RegisterBlobstore(name string, f BlobstoreRegistration) {
+	blobstores[name] = f
+}
+
+func newBlobstore(kind string, options BlobstoreOptions) (Blobstore, error) {
+	registration, ok := blobstores[kind]
+	if !ok {
+		var names []string
+		for name := range blobstores {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf("unsupported blobstore %q: must be one of %s", kind, strings.Join(names, ","))
+	}
+
+	if options.Bucket == "" {
+		return nil, fmt.Errorf("--blobstore-bucket is required when using the %s blobstore", kind)
+	}
+
+	return registration(options)
+}
+
+// checksumFileName is the name of the sidecar file holding the SHA256 of
+// a file, in the format written by sha256sum.
+func checksumFileName(name string) string {
+	return name + ".sha256"
+}
+
+func formatChecksum(sum, name string) string {
+	return fmt.Sprintf("%s  %s\n", sum, path.Base(name))
+}
+
+func parseChecksum(contents string) (string, error) {
+	fields := strings.Fields(contents)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum file is empty")
+	}
+
+	if _, err := hex.DecodeString(fields[0]); err != nil || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("checksum file does not start with a SHA256: %q", fields[0])
+	}
+
+	return fields[0], nil
+}
+
+// ReadBlobstoreChecksum reads the SHA256 of a file from the checksum file
+// next to it in the blobstore.
+func ReadBlobstoreChecksum(store Blobstore, name string) (string, error) {
+	reader, _, err := store.Open(checksumFileName(name))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return parseChecksum(line)
+}
+
+// checksumReader computes the SHA256 of what is read through it. When
+// expected is set, reaching the end of a file with a different checksum
+// is an error instead of io.EOF, so that a corrupt file is never fully sent.
+type checksumReader struct {
+	reader   io.Reader
+	digest   hash.Hash
+	expected string
+	read     int64
+}
+
+func newChecksumReader(reader io.Reader, expected string) *checksumReader {
+	return &checksumReader{
+		reader:   reader,
+		digest:   sha256.New(),
+		expected: expected,
+	}
+}
+
+func (cr *checksumReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.digest.Write(p[:n])
+	cr.read += int64(n)
+
+	if err == io.EOF && cr.expected != "" && cr.Sum() != cr.expected {
+		return n, fmt.Errorf("the checksum of the file (%s) does not match the expected checksum (%s)", cr.Sum(), cr.expected)
+	}
+
+	return n, err
+}
+
+func (cr *checksumReader) Sum() string {
+	return hex.EncodeToString(cr.digest.Sum(nil))
+}
+
+// exportTimestamp is how the names of exports differ from each other: a
+// number or timestamp after the stem, as in installation-20191017120000.zip
+// or installation-2019-10-17T12:00:00Z.zip.
+const exportTimestamp = `[-_.]*[0-9][-_.:0-9TZ]*`
+
+// exportsToPrune finds the exports named like name, the same stem followed
+// by a number or timestamp and the same extension, in the same directory as
+// it, and returns all but the keep most recent ones. Other files in the
+// directory are never returned.
+func exportsToPrune(files []BlobstoreFile, name string, keep int) []BlobstoreFile {
+	dir := path.Dir(name)
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(path.Base(name), ext)
+
+	pattern := regexp.QuoteMeta(stem)
+	if match := regexp.MustCompile(`^(.*?)` + exportTimestamp + `$`).FindStringSubmatch(stem); match != nil && match[1] != "" {
+		pattern = regexp.QuoteMeta(match[1]) + exportTimestamp
+	}
+	exportName := regexp.MustCompile(`^` + pattern + regexp.QuoteMeta(ext) + `$`)
+
+	var exports []BlobstoreFile
+	for _, file := range files {
+		if path.Dir(file.Name) == dir && exportName.MatchString(path.Base(file.Name)) {
+			exports = append(exports, file)
+		}
+	}
+
+	sort.SliceStable(exports, func(i, j int) bool {
+		return exports[i].LastModified.After(exports[j].LastModified)
+	})
+
+	if len(exports) <= keep {
+		return nil
+	}
+
+	return exports[keep:]
+}
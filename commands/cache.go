@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/cache"
+)
+
+type Cache struct {
+	stdout  *log.Logger
+	Options struct {
+		CacheDir string `long:"cache-dir" required:"true" description:"the directory given to the --cache-dir of download-product"`
+		MaxSize  string `long:"max-size"                  description:"with prune, the most the files in the cache can take up, such as 500M or 20G. The least recently used files are removed until they take up no more"`
+	}
+}
+
+func NewCache(stdout *log.Logger) *Cache {
+	return &Cache{
+		stdout: stdout,
+	}
+}
+
+func (c Cache) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command manages the directory given to the --cache-dir of download-product. 'om cache list' lists the files in it, the most recently used first. 'om cache prune --max-size 20G' removes the least recently used files until the rest take up no more than the max size",
+		ShortDescription: "**EXPERIMENTAL** lists or prunes the files in a download-product cache",
+		Flags:            c.Options,
+	}
+}
+
+func (c *Cache) Execute(args []string) error {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "prune") {
+		return fmt.Errorf("please choose one of 'om cache list' or 'om cache prune'")
+	}
+
+	_, err := jhanda.Parse(&c.Options, args[1:])
+	if err != nil {
+		return fmt.Errorf("could not parse cache flags: %s", err)
+	}
+
+	if args[0] == "prune" {
+		return c.prune()
+	}
+
+	return c.list()
+}
+
+func (c Cache) list() error {
+	entries, err := cache.New(c.Options.CacheDir).Entries()
+	if err != nil {
+		return fmt.Errorf("could not read the cache: %s", err)
+	}
+
+	var output bytes.Buffer
+	table := tablewriter.NewWriter(&output)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"File", "SHA256", "Size", "Last Used"})
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+		table.Append([]string{
+			entry.Name,
+			entry.SHA256,
+			cache.FormatSize(entry.Size),
+			entry.LastUsed.Format("2006-01-02 15:04:05"),
+		})
+	}
+	table.Render()
+
+	c.stdout.Print(output.String())
+	c.stdout.Printf("%d files, %s in total", len(entries), cache.FormatSize(total))
+
+	return nil
+}
+
+func (c Cache) prune() error {
+	if c.Options.MaxSize == "" {
+		return fmt.Errorf("--max-size is required to prune the cache")
+	}
+
+	maxSize, err := cache.ParseSize(c.Options.MaxSize)
+	if err != nil {
+		return fmt.Errorf("invalid --max-size: %s", err)
+	}
+
+	removed, err := cache.New(c.Options.CacheDir).Prune(maxSize)
+	for _, entry := range removed {
+		c.stdout.Printf("removed %s (%s)", entry.Name, cache.FormatSize(entry.Size))
+	}
+	if err != nil {
+		return fmt.Errorf("could not prune the cache: %s", err)
+	}
+
+	if len(removed) == 0 {
+		c.stdout.Printf("the cache is already no larger than %s", cache.FormatSize(maxSize))
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package commands_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/cache"
+	"github.com/pivotal-cf/om/commands"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		command  *commands.Cache
+		buffer   *gbytes.Buffer
+		tempDir  string
+		cacheDir string
+	)
+
+	addFile := func(name, contents string, lastUsed time.Time) {
+		path := filepath.Join(tempDir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+
+		sum := fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+		Expect(cache.New(cacheDir).Add(sum, path)).To(Succeed())
+		Expect(os.Chtimes(filepath.Join(cacheDir, sum, name), lastUsed, lastUsed)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "om-tests-")
+		Expect(err).ToNot(HaveOccurred())
+		cacheDir = filepath.Join(tempDir, "cache")
+
+		now := time.Now()
+		addFile("cf-2.7.4.pivotal", "cf 2.7.4", now.Add(-time.Hour))
+		addFile("cf-2.7.5.pivotal", "cf 2.7.5", now)
+
+		buffer = gbytes.NewBuffer()
+		command = commands.NewCache(log.New(buffer, "", 0))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	It("lists the files in the cache", func() {
+		err := command.Execute([]string{"list", "--cache-dir", cacheDir})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(buffer).To(gbytes.Say(`cf-2.7.5.pivotal \| [0-9a-f]{64} \| 8B`))
+		Expect(buffer).To(gbytes.Say(`cf-2.7.4.pivotal \| [0-9a-f]{64} \| 8B`))
+		Expect(buffer).To(gbytes.Say(`2 files, 16B in total`))
+	})
+
+	It("prunes the least recently used files", func() {
+		err := command.Execute([]string{"prune", "--cache-dir", cacheDir, "--max-size", "10"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(buffer).To(gbytes.Say(`removed cf-2.7.4.pivotal \(8B\)`))
+
+		entries, err := cache.New(cacheDir).Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name).To(Equal("cf-2.7.5.pivotal"))
+	})
+
+	It("requires --max-size to prune", func() {
+		err := command.Execute([]string{"prune", "--cache-dir", cacheDir})
+		Expect(err).To(MatchError("--max-size is required to prune the cache"))
+	})
+
+	It("requires a subcommand", func() {
+		err := command.Execute([]string{"--cache-dir", cacheDir})
+		Expect(err).To(MatchError("please choose one of 'om cache list' or 'om cache prune'"))
+	})
+
+	It("requires --cache-dir", func() {
+		err := command.Execute([]string{"list"})
+		Expect(err).To(MatchError(`could not parse cache flags: missing required flag "--cache-dir"`))
+	})
+})
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/extractor"
+)
+
+type CheckProductCompatibility struct {
+	service           productCompatibilityService
+	metadataExtractor metadataExtractor
+	logger            logger
+	Options           struct {
+		Product string `long:"product" short:"p" required:"true" description:"path to the product file to check"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/product_compatibility_service.go --fake-name ProductCompatibilityService . productCompatibilityService
+type productCompatibilityService interface {
+	Info() (api.Info, error)
+	GetDiagnosticReport() (api.DiagnosticReport, error)
+}
+
+func NewCheckProductCompatibility(metadataExtractor metadataExtractor, service productCompatibilityService, logger logger) CheckProductCompatibility {
+	return CheckProductCompatibility{
+		metadataExtractor: metadataExtractor,
+		service:           service,
+		logger:            logger,
+	}
+}
+
+func (c CheckProductCompatibility) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command reads the metadata of a product file and checks that the targeted Ops Manager can install it: that its version satisfies what the product requires, that the products it depends on are deployed or staged with the versions it requires, and that stemcells satisfying its stemcell criteria have been uploaded",
+		ShortDescription: "**EXPERIMENTAL** checks that a product file can be installed on the Ops Manager targeted",
+		Flags:            c.Options,
+	}
+}
+
+func (c CheckProductCompatibility) Execute(args []string) error {
+	if _, err := jhanda.Parse(&c.Options, args); err != nil {
+		return fmt.Errorf("could not parse check-product-compatibility flags: %s", err)
+	}
+
+	metadata, err := c.metadataExtractor.ExtractMetadata(c.Options.Product)
+	if err != nil {
+		return fmt.Errorf("failed to extract product metadata: %s", err)
+	}
+
+	return checkProductCompatibility(c.service, c.logger, metadata, true)
+}
+
+// compatibilityCheck is whether one requirement of a product is satisfied
+// by the targeted Ops Manager.
+type compatibilityCheck struct {
+	requirement string
+	constraint  string
+	found       string
+	satisfied   bool
+	stemcell    bool
+}
+
+// checkProductCompatibility prints how the targeted Ops Manager satisfies
+// the requirements of a product, and returns an error when it does not
+// satisfy them all. Unless stemcellsRequired, a missing stemcell is only
+// warned about, as it can still be uploaded after the product.
+func checkProductCompatibility(service productCompatibilityService, logger logger, metadata extractor.Metadata, stemcellsRequired bool) error {
+	inspection, err := extractor.InspectMetadata(metadata.Raw)
+	if err != nil {
+		return err
+	}
+
+	info, err := service.Info()
+	if err != nil {
+		return fmt.Errorf("failed to get the Ops Manager version: %s", err)
+	}
+
+	report, err := service.GetDiagnosticReport()
+	if err != nil {
+		return fmt.Errorf("failed to get the products and stemcells on Ops Manager: %s", err)
+	}
+
+	checks := productCompatibilityChecks(inspection, info, report)
+
+	var output bytes.Buffer
+	table := tablewriter.NewWriter(&output)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"Requirement", "Constraint", "Found", "Result"})
+
+	unsatisfied := 0
+	var missingStemcells []string
+	for _, check := range checks {
+		result := "satisfied"
+		switch {
+		case check.satisfied:
+		case check.stemcell && !stemcellsRequired:
+			result = "not uploaded yet"
+			missingStemcells = append(missingStemcells, check.requirement)
+		default:
+			result = "not satisfied"
+			unsatisfied++
+		}
+		table.Append([]string{check.requirement, check.constraint, check.found, result})
+	}
+	table.Render()
+
+	logger.Printf("checking %s %s against Ops Manager %s", inspection.Name, inspection.Version, info.Version)
+	logger.Print(output.String())
+
+	for _, stemcell := range missingStemcells {
+		logger.Printf("warning: no %s satisfying what %s %s requires has been uploaded yet", stemcell, inspection.Name, inspection.Version)
+	}
+
+	if unsatisfied > 0 {
+		return fmt.Errorf("%s %s is not compatible with the targeted Ops Manager: %d of %d requirements are not satisfied", inspection.Name, inspection.Version, unsatisfied, len(checks))
+	}
+
+	return nil
+}
+
+func productCompatibilityChecks(inspection extractor.ProductInspection, info api.Info, report api.DiagnosticReport) []compatibilityCheck {
+	var checks []compatibilityCheck
+
+	if inspection.OpsManagerVersion != "" {
+		checks = append(checks, compatibilityCheck{
+			requirement: "Ops Manager",
+			constraint:  inspection.OpsManagerVersion,
+			found:       info.Version,
+			satisfied:   versionSatisfies(info.Version, inspection.OpsManagerVersion),
+		})
+	}
+
+	for _, dependency := range inspection.ProductDependencies {
+		check := compatibilityCheck{
+			requirement: "product " + dependency.Name,
+			constraint:  dependency.Version,
+		}
+
+		var found []string
+		for _, products := range []struct {
+			state    string
+			products []api.DiagnosticProduct
+		}{
+			{"deployed", report.DeployedProducts},
+			{"staged", report.StagedProducts},
+		} {
+			for _, product := range products.products {
+				if product.Name != dependency.Name {
+					continue
+				}
+
+				found = append(found, fmt.Sprintf("%s (%s)", product.Version, products.state))
+				if versionSatisfies(product.Version, dependency.Version) {
+					check.satisfied = true
+				}
+			}
+		}
+
+		check.found = foundOrNone(found)
+		checks = append(checks, check)
+	}
+
+	criteria := append([]extractor.StemcellCriteria{inspection.StemcellCriteria}, inspection.AdditionalStemcellsCriteria...)
+	for _, stemcellCriteria := range criteria {
+		if stemcellCriteria.OS == "" {
+			continue
+		}
+
+		check := compatibilityCheck{
+			requirement: "stemcell " + stemcellCriteria.OS,
+			constraint:  stemcellConstraint(stemcellCriteria),
+			stemcell:    true,
+		}
+
+		var found []string
+		for _, stemcell := range reportedStemcells(report) {
+			if stemcell.OS != stemcellCriteria.OS && !strings.Contains(stemcell.Filename, "-"+stemcellCriteria.OS+"-") {
+				continue
+			}
+
+			found = append(found, stemcell.Version)
+			if stemcellSatisfies(stemcell.Version, stemcellCriteria) {
+				check.satisfied = true
+			}
+		}
+
+		check.found = foundOrNone(found)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+var stemcellFilenameVersion = regexp.MustCompile(`bosh-stemcell-(\d+(?:\.\d+)*)-`)
+
+// reportedStemcells are the stemcells uploaded to Ops Manager. Older Ops
+// Managers only report their filenames, such as
+// light-bosh-stemcell-456.30-google-kvm-ubuntu-xenial-go_agent.tgz, which
+// have the version in them.
+func reportedStemcells(report api.DiagnosticReport) []api.Stemcell {
+	if len(report.AvailableStemcells) > 0 {
+		return report.AvailableStemcells
+	}
+
+	var stemcells []api.Stemcell
+	for _, filename := range report.Stemcells {
+		matches := stemcellFilenameVersion.FindStringSubmatch(filename)
+		if matches == nil {
+			continue
+		}
+
+		stemcells = append(stemcells, api.Stemcell{Filename: filename, Version: matches[1]})
+	}
+
+	return stemcells
+}
+
+// versionSatisfies is whether a version reported by Ops Manager, such as
+// 2.7.3-build.12 or 2.7-build.12, satisfies a constraint from the metadata
+// of a product. The build is not part of the version a product requires.
+func versionSatisfies(reported string, constraint string) bool {
+	if index := strings.Index(reported, "-build"); index >= 0 {
+		reported = reported[:index]
+	}
+
+	v, err := version.NewVersion(reported)
+	if err != nil {
+		return false
+	}
+
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+
+	return constraints.Check(v)
+}
+
+// stemcellSatisfies follows the stemcell_criteria of a product: a stemcell
+// of the same major version that is at least the version of the criteria,
+// or exactly that version when the product disables patch security updates.
+func stemcellSatisfies(stemcellVersion string, criteria extractor.StemcellCriteria) bool {
+	available, err := version.NewVersion(stemcellVersion)
+	if err != nil {
+		return false
+	}
+
+	required, err := version.NewVersion(criteria.Version)
+	if err != nil {
+		return false
+	}
+
+	if !patchSecurityUpdates(criteria) {
+		return available.Equal(required)
+	}
+
+	return available.Segments()[0] == required.Segments()[0] && !available.LessThan(required)
+}
+
+func stemcellConstraint(criteria extractor.StemcellCriteria) string {
+	if !patchSecurityUpdates(criteria) {
+		return criteria.Version
+	}
+
+	return "~> " + criteria.Version
+}
+
+func patchSecurityUpdates(criteria extractor.StemcellCriteria) bool {
+	return criteria.EnablePatchSecurityUpdates == nil || *criteria.EnablePatchSecurityUpdates
+}
+
+func foundOrNone(found []string) string {
+	if len(found) == 0 {
+		return "none"
+	}
+
+	return strings.Join(found, ", ")
+}
@@ -0,0 +1,142 @@
+package commands_test
+
+import (
+	"errors"
+	"log"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/extractor"
+)
+
+var _ = Describe("CheckProductCompatibility", func() {
+	var (
+		command           commands.CheckProductCompatibility
+		service           *fakes.ProductCompatibilityService
+		metadataExtractor *fakes.MetadataExtractor
+		buffer            *gbytes.Buffer
+	)
+
+	BeforeEach(func() {
+		service = &fakes.ProductCompatibilityService{}
+		metadataExtractor = &fakes.MetadataExtractor{}
+		buffer = gbytes.NewBuffer()
+
+		metadataExtractor.ExtractMetadataReturns(extractor.Metadata{
+			Name:    "some-product",
+			Version: "1.2.3",
+			Raw: []byte(`---
+name: some-product
+product_version: 1.2.3
+stemcell_criteria:
+  os: ubuntu-xenial
+  version: "456.30"
+additional_stemcells_criteria:
+- os: windows2019
+  version: "2019.7"
+  enable_patch_security_updates: false
+requires_product_versions:
+- name: p-bosh
+  version: ~> 2.7.0
+- name: cf
+  version: ">= 2.6"
+`),
+		}, nil)
+
+		service.InfoReturns(api.Info{Version: "2.7.3-build.12"}, nil)
+		service.GetDiagnosticReportReturns(api.DiagnosticReport{
+			DeployedProducts: []api.DiagnosticProduct{
+				{Name: "p-bosh", Version: "2.7.3-build.12"},
+				{Name: "cf", Version: "2.5.9"},
+			},
+			StagedProducts: []api.DiagnosticProduct{
+				{Name: "cf", Version: "2.6.4"},
+			},
+			AvailableStemcells: []api.Stemcell{
+				{Filename: "light-bosh-stemcell-456.51-google-kvm-ubuntu-xenial-go_agent.tgz", OS: "ubuntu-xenial", Version: "456.51"},
+				{Filename: "light-bosh-stemcell-2019.7-google-kvm-windows2019-go_agent.tgz", Version: "2019.7"},
+			},
+		}, nil)
+
+		command = commands.NewCheckProductCompatibility(metadataExtractor, service, log.New(buffer, "", 0))
+	})
+
+	It("reports that the requirements of the product are satisfied", func() {
+		err := command.Execute([]string{"--product", "/path/to/some-product.pivotal"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(metadataExtractor.ExtractMetadataArgsForCall(0)).To(Equal("/path/to/some-product.pivotal"))
+
+		Expect(buffer).To(gbytes.Say(`checking some-product 1.2.3 against Ops Manager 2.7.3-build.12`))
+		Expect(buffer).To(gbytes.Say(`Ops Manager\s+\| ~> 2.7.0\s+\| 2.7.3-build.12\s+\| satisfied`))
+		Expect(buffer).To(gbytes.Say(`product cf\s+\| >= 2.6\s+\| 2.5.9 \(deployed\), 2.6.4 \(staged\)\s+\| satisfied`))
+		Expect(buffer).To(gbytes.Say(`stemcell ubuntu-xenial\s+\| ~> 456.30\s+\| 456.51\s+\| satisfied`))
+		Expect(buffer).To(gbytes.Say(`stemcell windows2019\s+\| 2019.7\s+\| 2019.7\s+\| satisfied`))
+	})
+
+	It("returns an error when a requirement of the product is not satisfied", func() {
+		service.InfoReturns(api.Info{Version: "2.8-build.101"}, nil)
+		service.GetDiagnosticReportReturns(api.DiagnosticReport{
+			AvailableStemcells: []api.Stemcell{
+				{OS: "ubuntu-xenial", Version: "621.5"},
+				{OS: "windows2019", Version: "2019.8"},
+			},
+		}, nil)
+
+		err := command.Execute([]string{"--product", "/path/to/some-product.pivotal"})
+		Expect(err).To(MatchError("some-product 1.2.3 is not compatible with the targeted Ops Manager: 4 of 4 requirements are not satisfied"))
+
+		Expect(buffer).To(gbytes.Say(`Ops Manager\s+\| ~> 2.7.0\s+\| 2.8-build.101\s+\| not satisfied`))
+		Expect(buffer).To(gbytes.Say(`product cf\s+\| >= 2.6\s+\| none\s+\| not satisfied`))
+		Expect(buffer).To(gbytes.Say(`stemcell ubuntu-xenial\s+\| ~> 456.30\s+\| 621.5\s+\| not satisfied`))
+		Expect(buffer).To(gbytes.Say(`stemcell windows2019\s+\| 2019.7\s+\| 2019.8\s+\| not satisfied`))
+	})
+
+	It("reads the stemcells from their filenames when Ops Manager does not report their versions", func() {
+		service.GetDiagnosticReportReturns(api.DiagnosticReport{
+			DeployedProducts: []api.DiagnosticProduct{
+				{Name: "p-bosh", Version: "2.7.3-build.12"},
+				{Name: "cf", Version: "2.6.4"},
+			},
+			Stemcells: []string{
+				"light-bosh-stemcell-456.51-google-kvm-ubuntu-xenial-go_agent.tgz",
+				"light-bosh-stemcell-2019.7-google-kvm-windows2019-go_agent.tgz",
+			},
+		}, nil)
+
+		err := command.Execute([]string{"--product", "/path/to/some-product.pivotal"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(buffer).To(gbytes.Say(`stemcell ubuntu-xenial\s+\| ~> 456.30\s+\| 456.51\s+\| satisfied`))
+		Expect(buffer).To(gbytes.Say(`stemcell windows2019\s+\| 2019.7\s+\| 2019.7\s+\| satisfied`))
+	})
+
+	When("the metadata cannot be extracted", func() {
+		It("returns an error", func() {
+			metadataExtractor.ExtractMetadataReturns(extractor.Metadata{}, errors.New("not a zip"))
+
+			err := command.Execute([]string{"--product", "/path/to/some-product.pivotal"})
+			Expect(err).To(MatchError("failed to extract product metadata: not a zip"))
+		})
+	})
+
+	When("Ops Manager cannot be queried", func() {
+		It("returns an error", func() {
+			service.GetDiagnosticReportReturns(api.DiagnosticReport{}, errors.New("unavailable"))
+
+			err := command.Execute([]string{"--product", "/path/to/some-product.pivotal"})
+			Expect(err).To(MatchError("failed to get the products and stemcells on Ops Manager: unavailable"))
+		})
+	})
+
+	When("the product flag is not provided", func() {
+		It("returns an error", func() {
+			err := command.Execute([]string{})
+			Expect(err).To(MatchError(`could not parse check-product-compatibility flags: missing required flag "--product"`))
+		})
+	})
+})
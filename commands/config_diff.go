@@ -0,0 +1,319 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type configDifference struct {
+	Section string      `json:"section"`
+	Key     string      `json:"key"`
+	Staged  interface{} `json:"staged"`
+	Config  interface{} `json:"config"`
+}
+
+func formatConfigDifferences(differences []configDifference) (string, error) {
+	var output strings.Builder
+	for _, difference := range differences {
+		stagedYAML, err := yaml.Marshal(difference.Staged)
+		if err != nil {
+			return "", err // un-tested
+		}
+
+		configYAML, err := yaml.Marshal(difference.Config)
+		if err != nil {
+			return "", err // un-tested
+		}
+
+		location := difference.Section
+		if difference.Key != "" {
+			location = fmt.Sprintf("%s: %s", difference.Section, difference.Key)
+		}
+
+		fmt.Fprintf(&output, "--- staged (%s)\n", location)
+		fmt.Fprintf(&output, "+++ config (%s)\n", location)
+		for _, line := range diffLines(splitLines(string(stagedYAML)), splitLines(string(configYAML))) {
+			output.WriteString(line + "\n")
+		}
+	}
+
+	return strings.TrimSuffix(output.String(), "\n"), nil
+}
+
+// planRequests prints the requests a dry run would make for a section of a
+// config file, followed by the fields within that section that would change.
+func planRequests(logger logger, differences []configDifference, section string, requests ...string) error {
+	for _, request := range requests {
+		logger.Printf("would request: %s", request)
+	}
+
+	var sectionDifferences []configDifference
+	for _, difference := range differences {
+		if difference.Section == section {
+			sectionDifferences = append(sectionDifferences, difference)
+		}
+	}
+
+	if len(sectionDifferences) == 0 {
+		logger.Printf("no changes to %s", section)
+		return nil
+	}
+
+	output, err := formatConfigDifferences(sectionDifferences)
+	if err != nil {
+		return err // un-tested
+	}
+
+	logger.Printf("changes to %s:\n%s", section, output)
+	return nil
+}
+
+// diffConfigSection compares one section of a config file with its staged
+// counterpart. Maps are compared key by key and lists of named elements
+// element by element, so each difference points at what changed.
+func diffConfigSection(section string, staged, desired interface{}) ([]configDifference, error) {
+	desiredSection, err := normalizeForDiff(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedSection, err := normalizeForDiff(staged)
+	if err != nil {
+		return nil, err
+	}
+
+	var differences []configDifference
+	compare := func(key string, stagedValue, desiredValue interface{}) {
+		stagedValue = restrictToDesired(stagedValue, desiredValue)
+		if !matchesStaged(stagedValue, desiredValue) {
+			differences = append(differences, configDifference{
+				Section: section,
+				Key:     key,
+				Staged:  stagedValue,
+				Config:  desiredValue,
+			})
+		}
+	}
+
+	switch d := desiredSection.(type) {
+	case map[string]interface{}:
+		stagedValues, _ := stagedSection.(map[string]interface{})
+
+		var keys []string
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			compare(key, stagedValues[key], d[key])
+		}
+	case []interface{}:
+		stagedValues, _ := stagedSection.([]interface{})
+
+		for index, desiredValue := range d {
+			values, _ := desiredValue.(map[string]interface{})
+			name, _ := values["name"].(string)
+			if name == "" {
+				compare("", stagedSection, desiredSection)
+				break
+			}
+
+			var stagedValue interface{}
+			if value, ok := findDesiredElement(stagedValues, desiredValue, index); ok {
+				stagedValue = value
+			}
+			compare(name, stagedValue, desiredValue)
+		}
+	case nil:
+	default:
+		compare("", stagedSection, desiredSection)
+	}
+
+	return differences, nil
+}
+
+// normalizeForDiff round-trips a value through JSON so that values decoded
+// from YAML and from the API compare with the same types.
+func normalizeForDiff(value interface{}) (interface{}, error) {
+	contents, err := getJSONProperties(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	err = json.Unmarshal([]byte(contents), &normalized)
+	if err != nil {
+		return nil, fmt.Errorf("could not normalize configuration: %s", err) // un-tested
+	}
+
+	return normalized, nil
+}
+
+// restrictToDesired drops everything from staged that desired does not
+// declare. List elements are paired up by their "name" when they have one.
+func restrictToDesired(staged, desired interface{}) interface{} {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		s, ok := staged.(map[string]interface{})
+		if !ok {
+			return staged
+		}
+
+		restricted := map[string]interface{}{}
+		for key, desiredValue := range d {
+			if value, ok := s[key]; ok {
+				restricted[key] = restrictToDesired(value, desiredValue)
+			}
+		}
+		return restricted
+	case []interface{}:
+		s, ok := staged.([]interface{})
+		if !ok {
+			return staged
+		}
+
+		restricted := make([]interface{}, 0, len(s))
+		for index, value := range s {
+			if desiredValue, ok := findDesiredElement(d, value, index); ok {
+				value = restrictToDesired(value, desiredValue)
+			}
+			restricted = append(restricted, value)
+		}
+		return restricted
+	}
+
+	return staged
+}
+
+func findDesiredElement(desired []interface{}, staged interface{}, index int) (interface{}, bool) {
+	if values, ok := staged.(map[string]interface{}); ok {
+		if name, ok := values["name"]; ok {
+			for _, element := range desired {
+				if desiredValues, ok := element.(map[string]interface{}); ok && desiredValues["name"] == name {
+					return element, true
+				}
+			}
+			return nil, false
+		}
+	}
+
+	if index < len(desired) {
+		return desired[index], true
+	}
+
+	return nil, false
+}
+
+var credentialPlaceholder = regexp.MustCompile(`^\(\((.+)\)\)$`)
+
+func isCredentialPlaceholder(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == "***" || credentialPlaceholder.MatchString(v)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return false
+		}
+		for _, inner := range v {
+			if !isCredentialPlaceholder(inner) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func matchesStaged(staged, desired interface{}) bool {
+	if isCredentialPlaceholder(staged) {
+		return true
+	}
+
+	switch s := staged.(type) {
+	case map[string]interface{}:
+		d, ok := desired.(map[string]interface{})
+		if !ok || len(s) != len(d) {
+			return false
+		}
+		for key, value := range s {
+			desiredValue, ok := d[key]
+			if !ok || !matchesStaged(value, desiredValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		d, ok := desired.([]interface{})
+		if !ok || len(s) != len(d) {
+			return false
+		}
+		for i := range s {
+			if !matchesStaged(s[i], d[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(staged, desired)
+}
+
+func splitLines(contents string) []string {
+	return strings.Split(strings.TrimSuffix(contents, "\n"), "\n")
+}
+
+// diffLines returns a line-by-line diff of a and b, prefixing removed lines
+// with "-", added lines with "+" and common lines with a space.
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		lines = append(lines, "-"+a[i])
+	}
+
+	for ; j < len(b); j++ {
+		lines = append(lines, "+"+b[j])
+	}
+
+	return lines
+}
@@ -2,7 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"io/ioutil"
+	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/pivotal-cf/go-pivnet"
 	"github.com/pivotal-cf/jhanda"
@@ -20,12 +25,25 @@ type ConfigTemplate struct {
 		Vars       []string `long:"var"                                  description:"Load variable from the command line. Format: VAR=VAL"`
 
 		OutputDirectory   string `long:"output-directory"               description:"a directory to create templates under. must already exist."                       required:"true"`
-		PivnetApiToken    string `long:"pivnet-api-token"                                                                                                   required:"true"`
-		PivnetProductSlug string `long:"pivnet-product-slug"            description:"the product name in pivnet"                                                       required:"true"`
-		ProductVersion    string `long:"product-version"                description:"the version of the product from which to generate a template"                     required:"true"`
+		ProductPath       string `long:"product-path"                   description:"path to a product file from which to generate a template, instead of downloading it. Cannot be used with the flags of a source"`
+		Source            string `long:"source"              short:"s"  description:"where to get the product file from when --product-path is not given, the same as the --source of download-product (options: pivnet,s3,gcs,azure,file,http)" default:"pivnet"`
+		PivnetApiToken    string `long:"pivnet-api-token"               description:"API token to use when interacting with Pivnet. Required when the source is pivnet"`
+		PivnetProductSlug string `long:"pivnet-product-slug"            description:"the product name in pivnet. Required unless --product-path is given"`
+		ProductVersion    string `long:"product-version"                description:"the version of the product from which to generate a template. Required unless --product-path is given"`
 		PivnetFileGlob    string `long:"pivnet-file-glob"    short:"f"  description:"a glob to match exactly one file in the pivnet product slug"  default:"*.pivotal" `
 		PivnetDisableSSL  bool   `long:"pivnet-disable-ssl"             description:"whether to disable ssl validation when contacting the Pivotal Network"`
 		ExcludeVersion    bool   `long:"exclude-version"                description:"if set, will not output a version-specific directory"`
+		CacheDir          string `long:"cache-dir"                      description:"the --cache-dir of download-product. When the product file is in it, the metadata is read from there instead of from Pivnet"`
+
+		BlobstoreProductPath string `long:"blobstore-product-path" alias:"s3-product-path,gcs-product-path,azure-product-path" description:"specify the lookup path where the s3|gcs|azure|file|http product artifacts are stored"`
+
+		BlobstoreFlags
+
+		FileDirectory string `long:"file-directory" description:"the directory, such as an NFS mount, in which the product path is, when the source is file"`
+
+		HTTPURL        string `long:"http-url"         description:"the url of the artifact server under which the product path is, when the source is http"`
+		HTTPIndexFile  string `long:"http-index-file"  description:"the path, relative to the http url, of a file listing the artifacts one per line. Without it, the HTML directory listing of the product path is read"`
+		HTTPDisableSSL bool   `long:"http-disable-ssl" description:"whether to disable ssl validation when contacting the artifact server"`
 	}
 }
 
@@ -38,7 +56,15 @@ var pivnetHost = pivnet.DefaultHost
 var DefaultProvider = func() func(c *ConfigTemplate) MetadataProvider {
 	return func(c *ConfigTemplate) MetadataProvider {
 		options := c.Options
-		return metadata.NewPivnetProvider(pivnetHost, options.PivnetApiToken, options.PivnetProductSlug, options.ProductVersion, options.PivnetFileGlob, options.PivnetDisableSSL)
+		if options.ProductPath != "" {
+			return metadata.NewFileProvider(options.ProductPath)
+		}
+
+		if options.Source != "pivnet" {
+			return downloadedMetadataProvider{options: c.downloadProductOptions()}
+		}
+
+		return metadata.NewPivnetProvider(pivnetHost, options.PivnetApiToken, options.PivnetProductSlug, options.ProductVersion, options.PivnetFileGlob, options.PivnetDisableSSL, options.CacheDir)
 	}
 }
 
@@ -63,6 +89,11 @@ func (c *ConfigTemplate) Execute(args []string) error {
 		return fmt.Errorf("could not parse config-template flags: %s", err.Error())
 	}
 
+	err = c.validate()
+	if err != nil {
+		return fmt.Errorf("could not parse config-template flags: %s", err.Error())
+	}
+
 	_, err = os.Stat(c.Options.OutputDirectory)
 	if os.IsNotExist(err) {
 		return fmt.Errorf("output-directory does not exist: %s", c.Options.OutputDirectory)
@@ -71,6 +102,9 @@ func (c *ConfigTemplate) Execute(args []string) error {
 	metadataSource := c.newMetadataSource()
 	metadataBytes, err := metadataSource.MetadataBytes()
 	if err != nil {
+		if c.Options.ProductPath != "" {
+			return fmt.Errorf("error getting metadata from %s: %s", c.Options.ProductPath, err)
+		}
 		return fmt.Errorf("error getting metadata for %s at version %s: %s", c.Options.PivnetProductSlug, c.Options.ProductVersion, err)
 	}
 
@@ -86,10 +120,149 @@ func (c *ConfigTemplate) newMetadataSource() (metadataSource MetadataProvider) {
 	return c.buildProvider(c)
 }
 
+// validate checks the flags that are only required for some sources of the
+// product file.
+func (c *ConfigTemplate) validate() error {
+	if c.Options.ProductPath != "" {
+		if flags := c.sourceFlags(); len(flags) > 0 {
+			return fmt.Errorf("cannot use both --product-path and %s; please choose one or the other", strings.Join(flags, ", "))
+		}
+		return nil
+	}
+
+	if c.Options.Source == "pivnet" && c.Options.PivnetApiToken == "" {
+		return fmt.Errorf(`missing required flag "--pivnet-api-token"`)
+	}
+
+	if c.Options.PivnetProductSlug == "" {
+		return fmt.Errorf(`missing required flag "--pivnet-product-slug"`)
+	}
+
+	if c.Options.ProductVersion == "" {
+		return fmt.Errorf(`missing required flag "--product-version"`)
+	}
+
+	return nil
+}
+
+// sourceFlags are the flags given for getting the product file from a
+// source, which --product-path is used instead of.
+func (c *ConfigTemplate) sourceFlags() []string {
+	o := c.Options
+
+	var flags []string
+	for _, flag := range []struct {
+		name  string
+		given bool
+	}{
+		{"--source", o.Source != "pivnet"},
+		{"--pivnet-api-token", o.PivnetApiToken != ""},
+		{"--pivnet-product-slug", o.PivnetProductSlug != ""},
+		{"--product-version", o.ProductVersion != ""},
+		{"--pivnet-file-glob", o.PivnetFileGlob != "*.pivotal"},
+		{"--pivnet-disable-ssl", o.PivnetDisableSSL},
+		{"--cache-dir", o.CacheDir != ""},
+		{"--blobstore-product-path", o.BlobstoreProductPath != ""},
+		{"--file-directory", o.FileDirectory != ""},
+		{"--http-url", o.HTTPURL != ""},
+		{"--http-index-file", o.HTTPIndexFile != ""},
+		{"--http-disable-ssl", o.HTTPDisableSSL},
+	} {
+		if flag.given {
+			flags = append(flags, flag.name)
+		}
+	}
+
+	blobstore := reflect.ValueOf(o.BlobstoreFlags)
+	for i := 0; i < blobstore.NumField(); i++ {
+		field := blobstore.Type().Field(i)
+		if fmt.Sprint(blobstore.Field(i).Interface()) != defaultFlagValue(field) {
+			flags = append(flags, "--"+field.Tag.Get("long"))
+		}
+	}
+
+	return flags
+}
+
+// defaultFlagValue is the value a flag has when it is not given.
+func defaultFlagValue(field reflect.StructField) string {
+	if value, ok := field.Tag.Lookup("default"); ok {
+		return value
+	}
+
+	return fmt.Sprint(reflect.Zero(field.Type).Interface())
+}
+
+// downloadProductOptions configures the download client of the source the
+// way download-product would for the same flags.
+func (c *ConfigTemplate) downloadProductOptions() DownloadProductOptions {
+	return DownloadProductOptions{
+		Source:            c.Options.Source,
+		BlobstoreFlags:    c.Options.BlobstoreFlags,
+		PivnetFileGlob:    c.Options.PivnetFileGlob,
+		PivnetProductSlug: c.Options.PivnetProductSlug,
+		PivnetDisableSSL:  c.Options.PivnetDisableSSL,
+		PivnetToken:       c.Options.PivnetApiToken,
+		ProductVersion:    c.Options.ProductVersion,
+		ProductPath:       c.Options.BlobstoreProductPath,
+		FileDirectory:     c.Options.FileDirectory,
+		HTTPURL:           c.Options.HTTPURL,
+		HTTPIndexFile:     c.Options.HTTPIndexFile,
+		HTTPDisableSSL:    c.Options.HTTPDisableSSL,
+		CacheDir:          c.Options.CacheDir,
+	}
+}
+
+// downloadedMetadataProvider reads the metadata from a product file that it
+// downloads with the client download-product uses for the source.
+type downloadedMetadataProvider struct {
+	options DownloadProductOptions
+}
+
+func (p downloadedMetadataProvider) MetadataBytes() ([]byte, error) {
+	plugin, ok := plugins[p.options.Source]
+	if !ok {
+		return nil, fmt.Errorf("could not find valid source for '%s'", p.options.Source)
+	}
+
+	stderr := log.New(os.Stderr, "", 0)
+	client, err := plugin(p.options, os.Stderr, stderr, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	fileArtifact, err := client.GetLatestProductFile(p.options.PivnetProductSlug, p.options.ProductVersion, p.options.PivnetFileGlob)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadDir, err := ioutil.TempDir("", "om-config-template")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(downloadDir)
+
+	// downloaded as download-product would, so that the file is read from,
+	// and added to, the --cache-dir
+	download := DownloadProduct{
+		stderr:         stderr,
+		downloadClient: client,
+		Options:        p.options,
+	}
+
+	productFilePath := filepath.Join(downloadDir, filepath.Base(fileArtifact.Name()))
+	err = download.downloadFileArtifact(fileArtifact, productFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not download product: %s", err)
+	}
+
+	return metadata.NewFileProvider(productFilePath).MetadataBytes()
+}
+
 func (c *ConfigTemplate) Usage() jhanda.Usage {
 	return jhanda.Usage{
-		Description:      "**EXPERIMENTAL** this command generates a product configuration template from a .pivotal file on Pivnet",
-		ShortDescription: "**EXPERIMENTAL** generates a config template from a Pivnet product",
+		Description:      "**EXPERIMENTAL** this command generates a product configuration template from a .pivotal file on Pivnet, in a blobstore or other source of download-product, or on disk with --product-path",
+		ShortDescription: "**EXPERIMENTAL** generates a config template from a product file",
 		Flags:            c.Options,
 	}
 }
@@ -1,9 +1,12 @@
 package commands_test
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
 	"os"
 	"path/filepath"
 
@@ -175,6 +178,164 @@ var _ = Describe("ConfigTemplate", func() {
 		})
 	})
 
+	Describe("the source of the product file", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			tempDir = createOutputDirectory()
+			command = commands.NewConfigTemplate(commands.DefaultProvider())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tempDir)).To(Succeed())
+		})
+
+		When("--product-path is given", func() {
+			It("generates the template from the metadata in the product file", func() {
+				productFile, err := ioutil.TempFile("", "fake-tile-*.pivotal")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.Remove(productFile.Name())
+				createTempZipFile(productFile)
+
+				err = command.Execute([]string{
+					"--output-directory", tempDir,
+					"--product-path", productFile.Name(),
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(filepath.Join(tempDir, "fake-tile", "1.2.3", "product.yml")).To(BeAnExistingFile())
+			})
+
+			It("returns an error when the product file has no metadata", func() {
+				err := command.Execute([]string{
+					"--output-directory", tempDir,
+					"--product-path", "/not/a/product.pivotal",
+				})
+				Expect(err).To(MatchError(ContainSubstring("error getting metadata from /not/a/product.pivotal")))
+			})
+
+			It("cannot also be given a pivnet product slug", func() {
+				err := command.Execute([]string{
+					"--output-directory", tempDir,
+					"--product-path", "/some/product.pivotal",
+					"--pivnet-product-slug", "some-product",
+				})
+				Expect(err).To(MatchError("could not parse config-template flags: cannot use both --product-path and --pivnet-product-slug; please choose one or the other"))
+			})
+
+			It("cannot also be given a source or blobstore flags", func() {
+				err := command.Execute([]string{
+					"--output-directory", tempDir,
+					"--product-path", "/some/product.pivotal",
+					"--source", "s3",
+					"--blobstore-bucket", "some-bucket",
+					"--s3-region-name", "some-region",
+				})
+				Expect(err).To(MatchError("could not parse config-template flags: cannot use both --product-path and --source, --blobstore-bucket, --s3-region-name; please choose one or the other"))
+			})
+		})
+
+		When("--source is a blobstore", func() {
+			var (
+				fakeProductDownloader *fakes.ProductDownloader
+				clientOptions         commands.DownloadProductOptions
+			)
+
+			BeforeEach(func() {
+				fakeProductDownloader = &fakes.ProductDownloader{}
+				fileArtifact := &fakes.FileArtifacter{}
+				fileArtifact.NameReturns("/some-path/[some-product,1.2.3]fake-tile.pivotal")
+				fakeProductDownloader.GetLatestProductFileReturns(fileArtifact, nil)
+				fakeProductDownloader.DownloadProductToFileStub = func(_ commands.FileArtifacter, file *os.File) error {
+					createTempZipFile(file)
+					return nil
+				}
+
+				commands.RegisterProductClient("s3", func(c commands.DownloadProductOptions, progressWriter io.Writer, stdout *log.Logger, stderr *log.Logger) (commands.ProductDownloader, error) {
+					clientOptions = c
+					return fakeProductDownloader, nil
+				})
+			})
+
+			It("downloads the product file with the download-product client for the source", func() {
+				err := command.Execute([]string{
+					"--output-directory", tempDir,
+					"--source", "s3",
+					"--pivnet-product-slug", "some-product",
+					"--product-version", "1.2.3",
+					"--blobstore-bucket", "some-bucket",
+					"--blobstore-product-path", "/some-path",
+					"--s3-region-name", "some-region",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(clientOptions.Bucket).To(Equal("some-bucket"))
+				Expect(clientOptions.ProductPath).To(Equal("/some-path"))
+				Expect(clientOptions.S3RegionName).To(Equal("some-region"))
+
+				slug, version, glob := fakeProductDownloader.GetLatestProductFileArgsForCall(0)
+				Expect(slug).To(Equal("some-product"))
+				Expect(version).To(Equal("1.2.3"))
+				Expect(glob).To(Equal("*.pivotal"))
+
+				Expect(filepath.Join(tempDir, "fake-tile", "1.2.3", "product.yml")).To(BeAnExistingFile())
+			})
+
+			It("adds the product file to --cache-dir like download-product", func() {
+				cacheDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(cacheDir)
+
+				productFile, err := ioutil.TempFile("", "fake-tile-*.pivotal")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.Remove(productFile.Name())
+				createTempZipFile(productFile)
+				contents, err := ioutil.ReadFile(productFile.Name())
+				Expect(err).ToNot(HaveOccurred())
+
+				fileArtifact := &fakes.FileArtifacter{}
+				fileArtifact.NameReturns("/some-path/[some-product,1.2.3]fake-tile.pivotal")
+				fileArtifact.SHA256Returns(fmt.Sprintf("%x", sha256.Sum256(contents)))
+				fakeProductDownloader.GetLatestProductFileReturns(fileArtifact, nil)
+
+				for i := 0; i < 2; i++ {
+					err = command.Execute([]string{
+						"--output-directory", tempDir,
+						"--source", "s3",
+						"--pivnet-product-slug", "some-product",
+						"--product-version", "1.2.3",
+						"--cache-dir", cacheDir,
+					})
+					Expect(err).ToNot(HaveOccurred())
+				}
+
+				Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(1))
+			})
+
+			It("does not need a pivnet api token", func() {
+				err := command.Execute([]string{
+					"--output-directory", tempDir,
+					"--source", "s3",
+					"--pivnet-product-slug", "some-product",
+				})
+				Expect(err).To(MatchError(`could not parse config-template flags: missing required flag "--product-version"`))
+			})
+
+			It("returns an error when the product file cannot be downloaded", func() {
+				fakeProductDownloader.DownloadProductToFileStub = nil
+				fakeProductDownloader.DownloadProductToFileReturns(errors.New("access denied"))
+
+				err := command.Execute([]string{
+					"--output-directory", tempDir,
+					"--source", "s3",
+					"--pivnet-product-slug", "some-product",
+					"--product-version", "1.2.3",
+				})
+				Expect(err).To(MatchError("error getting metadata for some-product at version 1.2.3: could not download product: access denied"))
+			})
+		})
+	})
+
 	Describe("Usage", func() {
 		BeforeEach(func() {
 			command = commands.NewConfigTemplate(func(*commands.ConfigTemplate) commands.MetadataProvider {
@@ -186,8 +347,8 @@ var _ = Describe("ConfigTemplate", func() {
 
 		It("returns usage information for the command", func() {
 			Expect(command.Usage()).To(Equal(jhanda.Usage{
-				Description:      "**EXPERIMENTAL** this command generates a product configuration template from a .pivotal file on Pivnet",
-				ShortDescription: "**EXPERIMENTAL** generates a config template from a Pivnet product",
+				Description:      "**EXPERIMENTAL** this command generates a product configuration template from a .pivotal file on Pivnet, in a blobstore or other source of download-product, or on disk with --product-path",
+				ShortDescription: "**EXPERIMENTAL** generates a config template from a product file",
 				Flags:            command.Options,
 			}))
 		})
@@ -24,6 +24,7 @@ type ConfigureDirector struct {
 		VarsEnv                []string `long:"vars-env" description:"Load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
 		Vars                   []string `long:"var" short:"v" description:"Load variable from the command line. Format: VAR=VAL"`
 		OpsFile                []string `long:"ops-file" description:"YAML operations file"`
+		DryRun                 bool     `long:"dry-run" alias:"plan" description:"print the requests that would be made and the fields that would change, without changing the director"`
 	}
 }
 
@@ -51,10 +52,17 @@ type configureDirectorService interface {
 	CreateStagedVMExtension(api.CreateVMExtension) error
 	DeleteCustomVMTypes() error
 	DeleteVMExtension(name string) error
+	GetStagedDirectorAvailabilityZones() (api.AvailabilityZonesOutput, error)
+	GetStagedDirectorIaasConfigurations(redact bool) (map[string][]map[string]interface{}, error)
+	GetStagedDirectorNetworks() (api.NetworksConfigurationOutput, error)
+	GetStagedDirectorProperties(redact bool) (map[string]interface{}, error)
 	GetStagedProductByName(name string) (api.StagedProductsFindOutput, error)
+	GetStagedProductJobResourceConfig(productGUID, jobGUID string) (api.JobProperties, error)
 	GetStagedProductManifest(guid string) (manifest string, err error)
+	GetStagedProductNetworksAndAZs(product string) (map[string]interface{}, error)
 	Info() (api.Info, error)
 	ListInstallations() ([]api.InstallationsServiceOutput, error)
+	ListStagedProductJobs(productGUID string) (map[string]string, error)
 	ListStagedVMExtensions() ([]api.VMExtension, error)
 	ListVMTypes() ([]api.VMType, error)
 	UpdateStagedDirectorIAASConfigurations(api.IAASConfigurationsInput) error
@@ -100,6 +108,10 @@ func (c ConfigureDirector) Execute(args []string) error {
 		return err
 	}
 
+	if c.Options.DryRun {
+		return c.plan(config)
+	}
+
 	err = c.updateIAASConfigurations(config)
 	if err != nil {
 		return err
@@ -542,6 +554,254 @@ func (c ConfigureDirector) configureResourceConfiguration(config *directorConfig
 	return nil
 }
 
+// plan performs the same reads as a real run and reports the requests that
+// would be made, without issuing any of them.
+func (c ConfigureDirector) plan(config *directorConfig) error {
+	c.logger.Printf("planning the director...")
+	c.logger.Printf("dry run: no changes will be made to the director")
+
+	if config.IAASConfigurations != nil {
+		info, err := c.service.Info()
+		if err != nil {
+			return fmt.Errorf("could not retrieve info from targetted ops manager: %v", err)
+		}
+		if ok, _ := info.VersionAtLeast(2, 2); !ok {
+			return fmt.Errorf("\"iaas-configurations\" is only available with Ops Manager 2.2 or later: you are running %s", info.Version)
+		}
+
+		staged, err := c.service.GetStagedDirectorIaasConfigurations(true)
+		if err != nil {
+			return err
+		}
+
+		err = c.planNamedElements("iaas-configurations", staged["iaas_configurations"], config.IAASConfigurations, "/api/v0/staged/director/iaas_configurations")
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.PropertiesConfiguration != nil {
+		staged, err := c.service.GetStagedDirectorProperties(true)
+		if err != nil {
+			return err
+		}
+
+		err = c.planSection("properties-configuration", staged, config.PropertiesConfiguration, "PUT /api/v0/staged/director/properties")
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.AZConfiguration != nil {
+		staged, err := c.service.GetStagedDirectorAvailabilityZones()
+		if err != nil {
+			return err
+		}
+
+		err = c.planNamedElements("az-configuration", staged.AvailabilityZones, config.AZConfiguration, "/api/v0/staged/director/availability_zones")
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.NetworksConfiguration != nil {
+		staged, err := c.service.GetStagedDirectorNetworks()
+		if err != nil {
+			return err
+		}
+
+		err = c.planSection("networks-configuration", staged, config.NetworksConfiguration, "PUT /api/v0/staged/director/networks")
+		if err != nil {
+			return err
+		}
+	}
+
+	productGUID, err := c.getProductGUID()
+	if err != nil {
+		return err
+	}
+
+	if config.NetworkAssignment != nil {
+		staged, err := c.service.GetStagedProductNetworksAndAZs(productGUID)
+		if err != nil {
+			return err
+		}
+
+		err = c.planSection("network-assignment", staged, config.NetworkAssignment, "PUT /api/v0/staged/director/network_and_az")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.planVMTypes(config)
+	if err != nil {
+		return err
+	}
+
+	err = c.planVMExtensions(config)
+	if err != nil {
+		return err
+	}
+
+	return c.planResourceConfiguration(config, productGUID)
+}
+
+func (c ConfigureDirector) planSection(section string, staged, desired interface{}, requests ...string) error {
+	differences, err := diffConfigSection(section, staged, desired)
+	if err != nil {
+		return err
+	}
+
+	return planRequests(c.logger, differences, section, requests...)
+}
+
+// planNamedElements plans a section whose elements are created with a POST
+// to path, or updated with a PUT to path/<guid> when one with the same name
+// is already staged.
+func (c ConfigureDirector) planNamedElements(section string, staged, desired interface{}, path string) error {
+	normalizedStaged, err := normalizeForDiff(staged)
+	if err != nil {
+		return err
+	}
+
+	normalizedDesired, err := normalizeForDiff(desired)
+	if err != nil {
+		return err
+	}
+
+	stagedGUIDs := map[string]string{}
+	stagedElements, _ := normalizedStaged.([]interface{})
+	for _, element := range stagedElements {
+		values, _ := element.(map[string]interface{})
+		name, _ := values["name"].(string)
+		guid, _ := values["guid"].(string)
+		stagedGUIDs[name] = guid
+	}
+
+	var requests []string
+	desiredElements, _ := normalizedDesired.([]interface{})
+	for _, element := range desiredElements {
+		values, _ := element.(map[string]interface{})
+		name, _ := values["name"].(string)
+		if guid := stagedGUIDs[name]; guid != "" {
+			requests = append(requests, fmt.Sprintf("PUT %s/%s (%s)", path, guid, name))
+		} else {
+			requests = append(requests, fmt.Sprintf("POST %s (%s)", path, name))
+		}
+	}
+
+	return c.planSection(section, normalizedStaged, normalizedDesired, requests...)
+}
+
+func (c ConfigureDirector) planVMTypes(config *directorConfig) error {
+	if len(config.VMTypes.VMTypes) == 0 {
+		if config.VMTypes.CustomTypesOnly {
+			return fmt.Errorf("if custom_types = true, vm_types must not be empty")
+		}
+
+		return nil
+	}
+
+	existingVMTypes, err := c.service.ListVMTypes()
+	if err != nil {
+		return err
+	}
+
+	stagedVMTypes := make([]api.CreateVMType, 0, len(existingVMTypes))
+	for i := range existingVMTypes {
+		stagedVMTypes = append(stagedVMTypes, existingVMTypes[i].CreateVMType)
+	}
+
+	var requests []string
+	if !config.VMTypes.CustomTypesOnly {
+		requests = append(requests, "DELETE /api/v0/vm_types")
+	}
+	requests = append(requests, "PUT /api/v0/vm_types")
+
+	return c.planSection("vmtypes-configuration", stagedVMTypes, config.VMTypes.VMTypes, requests...)
+}
+
+func (c ConfigureDirector) planVMExtensions(config *directorConfig) error {
+	if config.VMExtensions == nil {
+		return nil
+	}
+
+	stagedExtensions, err := c.service.ListStagedVMExtensions()
+	if err != nil {
+		return err
+	}
+
+	extensions, err := getJSONProperties(config.VMExtensions)
+	if err != nil {
+		return err
+	}
+
+	var desiredExtensions []api.VMExtension
+	err = json.Unmarshal([]byte(extensions), &desiredExtensions)
+	if err != nil {
+		return fmt.Errorf("could not unmarshall vmextensions-configuration json: %s. Full Error: %s", config.VMExtensions, err)
+	}
+
+	extensionsToDelete := map[string]bool{}
+	for _, extension := range stagedExtensions {
+		extensionsToDelete[extension.Name] = true
+	}
+
+	var requests []string
+	for _, extension := range desiredExtensions {
+		requests = append(requests, fmt.Sprintf("PUT /api/v0/staged/vm_extensions/%s", extension.Name))
+		delete(extensionsToDelete, extension.Name)
+	}
+
+	var names []string
+	for name := range extensionsToDelete {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		requests = append(requests, fmt.Sprintf("DELETE /api/v0/staged/vm_extensions/%s", name))
+	}
+
+	return c.planSection("vmextensions-configuration", stagedExtensions, desiredExtensions, requests...)
+}
+
+func (c ConfigureDirector) planResourceConfiguration(config *directorConfig, productGUID string) error {
+	if config.ResourceConfiguration == nil {
+		return nil
+	}
+
+	jobsToGUIDs, err := c.service.ListStagedProductJobs(productGUID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch jobs: %s", err)
+	}
+
+	var names []string
+	for name := range config.ResourceConfiguration {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	staged := map[string]interface{}{}
+	var requests []string
+	for _, name := range names {
+		jobGUID, ok := jobsToGUIDs[name]
+		if !ok {
+			return fmt.Errorf("unable to find job guid for job %s", name)
+		}
+
+		jobProperties, err := c.service.GetStagedProductJobResourceConfig(productGUID, jobGUID)
+		if err != nil {
+			return fmt.Errorf("could not fetch existing job configuration for job %s: %s", name, err)
+		}
+
+		staged[name] = jobProperties
+		requests = append(requests, fmt.Sprintf("PUT /api/v0/staged/products/%s/jobs/%s/resource_config", productGUID, jobGUID))
+	}
+
+	return c.planSection("resource-configuration", staged, config.ResourceConfiguration, requests...)
+}
+
 func checkRunningInstallation(listInstallations func() ([]api.InstallationsServiceOutput, error)) error {
 	installations, err := listInstallations()
 	if err != nil {
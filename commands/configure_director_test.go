@@ -634,5 +634,105 @@ vmtypes-configuration:
 				})
 			})
 		})
+
+		When("--dry-run is set", func() {
+			BeforeEach(func() {
+				service.GetStagedDirectorIaasConfigurationsReturns(map[string][]map[string]interface{}{
+					"iaas_configurations": {{"name": "default", "guid": "some-iaas-guid", "project": "some-project"}},
+				}, nil)
+				service.GetStagedDirectorPropertiesReturns(map[string]interface{}{
+					"director_configuration": map[string]interface{}{"some-director-assignment": "director", "ntp_servers_string": "ntp"},
+					"iaas_configuration":     map[string]interface{}{"some-iaas-assignment": "***"},
+				}, nil)
+				service.GetStagedDirectorAvailabilityZonesReturns(api.AvailabilityZonesOutput{
+					AvailabilityZones: []api.AvailabilityZoneOutput{
+						{Name: "AZ1", Fields: map[string]interface{}{"guid": "some-az-guid", "clusters": []interface{}{map[string]interface{}{"cluster": "pizza-boxes"}}}},
+					},
+				}, nil)
+				service.ListStagedProductJobsReturns(map[string]string{"resource": "some-job-guid"}, nil)
+				service.GetStagedProductJobResourceConfigReturns(api.JobProperties{
+					"instances":     1,
+					"instance_type": map[string]interface{}{"id": "automatic"},
+				}, nil)
+			})
+
+			It("prints the requests and changes without configuring the director", func() {
+				err := command.Execute([]string{
+					"--config", writeTestConfigFile(`---
+iaas-configurations:
+- name: default
+  project: some-project
+- name: other
+  project: other-project
+properties-configuration:
+  director_configuration:
+    some-director-assignment: changed
+  iaas_configuration:
+    some-iaas-assignment: some-secret
+az-configuration:
+- name: AZ1
+  clusters:
+  - cluster: pizza-boxes
+resource-configuration:
+  resource:
+    instance_type:
+      id: some-type
+vmextensions-configuration:
+- name: some_vm_extension
+  cloud_properties: {}
+vmtypes-configuration:
+  vm_types:
+  - name: vmtype1
+    cpu: 1
+    ram: 2048
+    ephemeral_disk: 10240
+`),
+					"--dry-run",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(service.UpdateStagedDirectorIAASConfigurationsCallCount()).To(Equal(0))
+				Expect(service.UpdateStagedDirectorPropertiesCallCount()).To(Equal(0))
+				Expect(service.UpdateStagedDirectorAvailabilityZonesCallCount()).To(Equal(0))
+				Expect(service.UpdateStagedDirectorNetworksCallCount()).To(Equal(0))
+				Expect(service.UpdateStagedDirectorNetworkAndAZCallCount()).To(Equal(0))
+				Expect(service.DeleteCustomVMTypesCallCount()).To(Equal(0))
+				Expect(service.CreateCustomVMTypesCallCount()).To(Equal(0))
+				Expect(service.CreateStagedVMExtensionCallCount()).To(Equal(0))
+				Expect(service.DeleteVMExtensionCallCount()).To(Equal(0))
+				Expect(service.ConfigureJobResourceConfigCallCount()).To(Equal(0))
+
+				Expect(service.GetStagedDirectorPropertiesArgsForCall(0)).To(BeTrue())
+
+				Expect(stdout).To(gbytes.Say("planning the director..."))
+				Expect(stdout).To(gbytes.Say("dry run: no changes will be made to the director"))
+				Expect(stdout).To(gbytes.Say(`would request: PUT /api/v0/staged/director/iaas_configurations/some-iaas-guid \(default\)`))
+				Expect(stdout).To(gbytes.Say(`would request: POST /api/v0/staged/director/iaas_configurations \(other\)`))
+				Expect(stdout).To(gbytes.Say(`changes to iaas-configurations:\n--- staged \(iaas-configurations: other\)\n\+\+\+ config \(iaas-configurations: other\)\n-null\n\+name: other\n\+project: other-project\n`))
+				Expect(stdout).To(gbytes.Say(`would request: PUT /api/v0/staged/director/properties`))
+				Expect(stdout).To(gbytes.Say(`changes to properties-configuration:\n--- staged \(properties-configuration: director_configuration\)\n\+\+\+ config \(properties-configuration: director_configuration\)\n-some-director-assignment: director\n\+some-director-assignment: changed\n`))
+				Expect(stdout).To(gbytes.Say(`would request: PUT /api/v0/staged/director/availability_zones/some-az-guid \(AZ1\)`))
+				Expect(stdout).To(gbytes.Say(`no changes to az-configuration`))
+				Expect(stdout).To(gbytes.Say(`would request: DELETE /api/v0/vm_types`))
+				Expect(stdout).To(gbytes.Say(`would request: PUT /api/v0/vm_types`))
+				Expect(stdout).To(gbytes.Say(`changes to vmtypes-configuration:`))
+				Expect(stdout).To(gbytes.Say(`would request: PUT /api/v0/staged/vm_extensions/some_vm_extension`))
+				Expect(stdout).To(gbytes.Say(`would request: DELETE /api/v0/staged/vm_extensions/some_other_vm_extension`))
+				Expect(stdout).To(gbytes.Say(`would request: PUT /api/v0/staged/products/p-bosh-guid/jobs/some-job-guid/resource_config`))
+				Expect(stdout).To(gbytes.Say(`changes to resource-configuration:\n--- staged \(resource-configuration: resource\)\n\+\+\+ config \(resource-configuration: resource\)\n instance_type:\n-  id: automatic\n\+  id: some-type\n`))
+			})
+
+			When("a configured job does not exist", func() {
+				It("returns an error", func() {
+					service.ListStagedProductJobsReturns(map[string]string{}, nil)
+
+					err := command.Execute([]string{
+						"--config", writeTestConfigFile(`{"resource-configuration": {"resource": {"instances": 1}}}`),
+						"--dry-run",
+					})
+					Expect(err).To(MatchError("unable to find job guid for job resource"))
+				})
+			})
+		})
 	})
 })
@@ -11,6 +11,7 @@ import (
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/config"
+	"github.com/pivotal-cf/om/configparser"
 
 	yamlConverter "github.com/ghodss/yaml"
 	"gopkg.in/yaml.v2"
@@ -27,14 +28,23 @@ type ConfigureProduct struct {
 		Vars       []string `long:"var" short:"v"       description:"Load variable from the command line. Format: VAR=VAL"`
 		VarsEnv    []string `long:"vars-env" description:"Load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
 		OpsFile    []string `long:"ops-file"  short:"o" description:"YAML operations file"`
+		DryRun     bool     `long:"dry-run" alias:"plan" description:"print the requests that would be made and the fields that would change, without changing the staged product"`
 	}
 }
 
 //counterfeiter:generate -o ./fakes/configure_product_service.go --fake-name ConfigureProductService . configureProductService
 type configureProductService interface {
 	ConfigureJobResourceConfig(productGUID string, config map[string]interface{}) error
+	GetStagedProductByName(product string) (api.StagedProductsFindOutput, error)
+	GetStagedProductJobMaxInFlight(productGUID string) (map[string]interface{}, error)
+	GetStagedProductJobResourceConfig(productGUID, jobGUID string) (api.JobProperties, error)
+	GetStagedProductNetworksAndAZs(product string) (map[string]interface{}, error)
+	GetStagedProductProperties(product string) (map[string]api.ResponseProperty, error)
+	GetStagedProductSyslogConfiguration(product string) (map[string]interface{}, error)
+	Info() (api.Info, error)
 	ListInstallations() ([]api.InstallationsServiceOutput, error)
 	ListStagedPendingChanges() (api.PendingChangesOutput, error)
+	ListStagedProductErrands(productID string) (api.ErrandsListOutput, error)
 	ListStagedProductJobs(productGUID string) (map[string]string, error)
 	ListStagedProducts() (api.StagedProductsOutput, error)
 	UpdateStagedProductErrands(productID, errandName string, postDeployState, preDeleteState interface{}) error
@@ -76,9 +86,13 @@ func (cp ConfigureProduct) Execute(args []string) error {
 		return err
 	}
 
-	cp.logger.Printf("configuring %s...", cfg.ProductName)
+	if cp.Options.DryRun {
+		cp.logger.Printf("planning %s...", cfg.ProductName)
+	} else {
+		cp.logger.Printf("configuring %s...", cfg.ProductName)
+	}
 
-	err = cp.validateConfig(cfg)
+	err = validateProductConfig(cfg)
 	if err != nil {
 		return err
 	}
@@ -88,6 +102,10 @@ func (cp ConfigureProduct) Execute(args []string) error {
 		return err
 	}
 
+	if cp.Options.DryRun {
+		return cp.plan(cfg, productGUID)
+	}
+
 	err = cp.configureNetwork(cfg, productGUID)
 	if err != nil {
 		return err
@@ -332,7 +350,7 @@ func (cp *ConfigureProduct) interpolateConfig(cfg configureProduct) (configurePr
 		varsEnvs = append(varsEnvs, value)
 	}
 
-	configContents, err := interpolate.Execute(interpolate.Options{
+	return interpolateProductConfig(cfg, interpolate.Options{
 		TemplateFile:  cp.Options.ConfigFile,
 		VarsFiles:     cp.Options.VarsFile,
 		Vars:          cp.Options.Vars,
@@ -341,19 +359,23 @@ func (cp *ConfigureProduct) interpolateConfig(cfg configureProduct) (configurePr
 		OpsFiles:      cp.Options.OpsFile,
 		ExpectAllKeys: true,
 	})
+}
+
+func interpolateProductConfig(cfg configureProduct, options interpolate.Options) (configureProduct, error) {
+	configContents, err := interpolate.Execute(options)
 	if err != nil {
 		return configureProduct{}, err
 	}
 
 	err = yaml.UnmarshalStrict(configContents, &cfg)
 	if err != nil {
-		return configureProduct{}, fmt.Errorf("%s could not be parsed as valid configuration: %s", cp.Options.ConfigFile, err)
+		return configureProduct{}, fmt.Errorf("%s could not be parsed as valid configuration: %s", options.TemplateFile, err)
 	}
 
 	return cfg, nil
 }
 
-func (cp ConfigureProduct) validateConfig(cfg configureProduct) error {
+func validateProductConfig(cfg configureProduct) error {
 	if cfg.ProductName == "" {
 		return fmt.Errorf("could not parse configure-product config: \"product-name\" is required")
 	}
@@ -409,3 +431,103 @@ func (cp ConfigureProduct) validateConfigComplete(productGUID string) error {
 	}
 	return nil
 }
+
+// plan performs the same reads as a real run and reports the requests that
+// would be made, without issuing any of them.
+func (cp ConfigureProduct) plan(cfg configureProduct, productGUID string) error {
+	info, err := cp.service.Info()
+	if err != nil {
+		return err
+	}
+
+	staged, err := getStagedProductConfig(cp.service, info, cfg.ProductName, func(string) configparser.CredentialHandler {
+		return configparser.NewPlaceholderHandler()
+	})
+	if err != nil {
+		return err
+	}
+
+	differences, err := diffProductConfigs(staged, cfg.ProductConfiguration)
+	if err != nil {
+		return err
+	}
+
+	cp.logger.Printf("dry run: no changes will be made to the staged product")
+
+	productPath := fmt.Sprintf("/api/v0/staged/products/%s", productGUID)
+
+	if cfg.NetworkProperties != nil {
+		err = planRequests(cp.logger, differences, "network-properties", "PUT "+productPath+"/networks_and_azs")
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.ProductProperties != nil {
+		err = planRequests(cp.logger, differences, "product-properties", "PUT "+productPath+"/properties")
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.ResourceConfigProperties != nil {
+		jobsToGUIDs, err := cp.service.ListStagedProductJobs(productGUID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch jobs: %s", err)
+		}
+
+		var names []string
+		for name := range cfg.ResourceConfigProperties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var requests []string
+		var maxInFlight bool
+		for _, name := range names {
+			jobGUID, ok := jobsToGUIDs[name]
+			if !ok {
+				return fmt.Errorf("unable to find job guid for job %s", name)
+			}
+			requests = append(requests, fmt.Sprintf("PUT %s/jobs/%s/resource_config", productPath, jobGUID))
+			if cfg.ResourceConfigProperties[name].MaxInFlight != nil {
+				maxInFlight = true
+			}
+		}
+		if maxInFlight {
+			requests = append(requests, "PUT "+productPath+"/max_in_flight")
+		}
+
+		err = planRequests(cp.logger, differences, "resource-config", requests...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.SyslogProperties != nil {
+		err = planRequests(cp.logger, differences, "syslog-properties", "PUT "+productPath+"/syslog_configuration")
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.ErrandConfigs) > 0 {
+		var names []string
+		for name := range cfg.ErrandConfigs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var requests []string
+		for _, name := range names {
+			requests = append(requests, fmt.Sprintf("PUT %s/errands (errand: %s)", productPath, name))
+		}
+
+		err = planRequests(cp.logger, differences, "errand-config", requests...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
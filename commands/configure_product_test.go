@@ -558,6 +558,108 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 		})
 
+		When("--dry-run is set", func() {
+			BeforeEach(func() {
+				service.ListStagedProductsReturns(api.StagedProductsOutput{
+					Products: []api.StagedProduct{
+						{GUID: "some-product-guid", Type: "cf"},
+					},
+				}, nil)
+				service.InfoReturns(api.Info{Version: "2.5.0"}, nil)
+				service.GetStagedProductByNameReturns(api.StagedProductsFindOutput{
+					Product: api.StagedProduct{GUID: "some-product-guid", Type: "cf"},
+				}, nil)
+				service.GetStagedProductPropertiesReturns(map[string]api.ResponseProperty{
+					".properties.something": {Value: "staged-value", Configurable: true},
+				}, nil)
+				service.GetStagedProductNetworksAndAZsReturns(map[string]interface{}{
+					"singleton_availability_zone": map[string]interface{}{"name": "az-one"},
+				}, nil)
+				service.ListStagedProductJobsReturns(map[string]string{"some-job": "some-job-guid"}, nil)
+				service.GetStagedProductJobResourceConfigReturns(api.JobProperties{"instances": 1}, nil)
+
+				config = `---
+product-name: cf
+product-properties:
+  .properties.something:
+    value: configure-me
+network-properties:
+  singleton_availability_zone:
+    name: az-one
+resource-config:
+  some-job:
+    instances: 2
+errand-config:
+  smoke_tests:
+    post-deploy-state: true
+`
+			})
+
+			It("prints the requests and changes without configuring the product", func() {
+				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				err := client.Execute([]string{"--config", configFile.Name(), "--dry-run"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(service.UpdateStagedProductPropertiesCallCount()).To(Equal(0))
+				Expect(service.UpdateStagedProductNetworksAndAZsCallCount()).To(Equal(0))
+				Expect(service.ConfigureJobResourceConfigCallCount()).To(Equal(0))
+				Expect(service.UpdateStagedProductJobMaxInFlightCallCount()).To(Equal(0))
+				Expect(service.UpdateSyslogConfigurationCallCount()).To(Equal(0))
+				Expect(service.UpdateStagedProductErrandsCallCount()).To(Equal(0))
+
+				var lines []string
+				for i := 0; i < logger.PrintfCallCount(); i++ {
+					format, content := logger.PrintfArgsForCall(i)
+					lines = append(lines, fmt.Sprintf(format, content...))
+				}
+				Expect(lines).To(Equal([]string{
+					"planning cf...",
+					"dry run: no changes will be made to the staged product",
+					"would request: PUT /api/v0/staged/products/some-product-guid/networks_and_azs",
+					"no changes to network-properties",
+					"would request: PUT /api/v0/staged/products/some-product-guid/properties",
+					"changes to product-properties:\n--- staged (product-properties: .properties.something)\n+++ config (product-properties: .properties.something)\n-staged-value\n+configure-me",
+					"would request: PUT /api/v0/staged/products/some-product-guid/jobs/some-job-guid/resource_config",
+					"changes to resource-config:\n--- staged (resource-config: some-job)\n+++ config (resource-config: some-job)\n-instances: 1\n+instances: 2",
+					"would request: PUT /api/v0/staged/products/some-product-guid/errands (errand: smoke_tests)",
+					"changes to errand-config:\n--- staged (errand-config: smoke_tests)\n+++ config (errand-config: smoke_tests)\n-null\n+post-deploy-state: true",
+				}))
+			})
+
+			It("includes the max_in_flight request when a job sets max_in_flight", func() {
+				config = `---
+product-name: cf
+resource-config:
+  some-job:
+    instances: 2
+    max_in_flight: 20%
+`
+				Expect(ioutil.WriteFile(configFile.Name(), []byte(config), 0600)).To(Succeed())
+
+				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				err := client.Execute([]string{"--config", configFile.Name(), "--dry-run"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(service.UpdateStagedProductJobMaxInFlightCallCount()).To(Equal(0))
+
+				var lines []string
+				for i := 0; i < logger.PrintfCallCount(); i++ {
+					format, content := logger.PrintfArgsForCall(i)
+					lines = append(lines, fmt.Sprintf(format, content...))
+				}
+				Expect(lines).To(ContainElement("would request: PUT /api/v0/staged/products/some-product-guid/jobs/some-job-guid/resource_config"))
+				Expect(lines).To(ContainElement("would request: PUT /api/v0/staged/products/some-product-guid/max_in_flight"))
+			})
+
+			It("accepts --plan as an alias", func() {
+				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				err := client.Execute([]string{"--config", configFile.Name(), "--plan"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(service.UpdateStagedProductPropertiesCallCount()).To(Equal(0))
+			})
+		})
+
 		When("there is a running installation", func() {
 			BeforeEach(func() {
 				service.ListInstallationsReturns([]api.InstallationsServiceOutput{
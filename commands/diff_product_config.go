@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/config"
+	"github.com/pivotal-cf/om/configparser"
+	"github.com/pivotal-cf/om/interpolate"
+)
+
+type DiffProductConfig struct {
+	environFunc func() []string
+	service     stagedConfigService
+	stdout      logger
+	Options     struct {
+		ConfigFile string   `long:"config"    short:"c" required:"true" description:"path to yml file containing the configure-product config to compare against the staged product"`
+		VarsFile   []string `long:"vars-file" short:"l"                 description:"Load variables from a YAML file"`
+		Vars       []string `long:"var"       short:"v"                 description:"Load variable from the command line. Format: VAR=VAL"`
+		VarsEnv    []string `long:"vars-env"                            description:"Load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
+		OpsFile    []string `long:"ops-file"  short:"o"                 description:"YAML operations file"`
+		Format     string   `long:"format"    short:"f" default:"text"  description:"Format to print as (options: text,json)"`
+	}
+}
+
+func NewDiffProductConfig(environFunc func() []string, service stagedConfigService, stdout logger) DiffProductConfig {
+	return DiffProductConfig{
+		environFunc: environFunc,
+		service:     service,
+		stdout:      stdout,
+	}
+}
+
+func (dpc DiffProductConfig) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command compares a configure-product config file against the staged product and exits non-zero when they differ. Credentials cannot be read back from Ops Manager and are not compared.",
+		ShortDescription: "**EXPERIMENTAL** compares a configure-product config with the staged product",
+		Flags:            dpc.Options,
+	}
+}
+
+func (dpc DiffProductConfig) Execute(args []string) error {
+	if _, err := jhanda.Parse(&dpc.Options, args); err != nil {
+		return fmt.Errorf("could not parse diff-product-config flags: %s", err)
+	}
+
+	if dpc.Options.Format != "text" && dpc.Options.Format != "json" {
+		return fmt.Errorf("unsupported format %q: must be one of text,json", dpc.Options.Format)
+	}
+
+	varsEnvs := dpc.Options.VarsEnv
+	if value, ok := os.LookupEnv("OM_VARS_ENV"); ok {
+		// EXPERIMENTAL: don't put this directly in VarsEnv
+		varsEnvs = append(varsEnvs, value)
+	}
+
+	cfg, err := interpolateProductConfig(configureProduct{}, interpolate.Options{
+		TemplateFile:  dpc.Options.ConfigFile,
+		VarsFiles:     dpc.Options.VarsFile,
+		Vars:          dpc.Options.Vars,
+		EnvironFunc:   dpc.environFunc,
+		VarsEnvs:      varsEnvs,
+		OpsFiles:      dpc.Options.OpsFile,
+		ExpectAllKeys: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = validateProductConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	info, err := dpc.service.Info()
+	if err != nil {
+		return err
+	}
+
+	staged, err := getStagedProductConfig(dpc.service, info, cfg.ProductName, func(string) configparser.CredentialHandler {
+		return configparser.NewPlaceholderHandler()
+	})
+	if err != nil {
+		return err
+	}
+
+	differences, err := diffProductConfigs(staged, cfg.ProductConfiguration)
+	if err != nil {
+		return err
+	}
+
+	switch dpc.Options.Format {
+	case "json":
+		err = dpc.printJSON(cfg.ProductName, differences)
+	default:
+		err = dpc.printText(cfg.ProductName, differences)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(differences) > 0 {
+		return fmt.Errorf("found %d difference(s) between %s and the staged configuration of %q", len(differences), dpc.Options.ConfigFile, cfg.ProductName)
+	}
+
+	return nil
+}
+
+func (dpc DiffProductConfig) printJSON(productName string, differences []configDifference) error {
+	if differences == nil {
+		differences = []configDifference{}
+	}
+
+	output, err := json.MarshalIndent(struct {
+		ProductName string             `json:"product-name"`
+		Differences []configDifference `json:"differences"`
+	}{
+		ProductName: productName,
+		Differences: differences,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal differences: %s", err) // un-tested
+	}
+
+	dpc.stdout.Println(string(output))
+	return nil
+}
+
+func (dpc DiffProductConfig) printText(productName string, differences []configDifference) error {
+	if len(differences) == 0 {
+		dpc.stdout.Printf("no differences found between %s and the staged configuration of %q", dpc.Options.ConfigFile, productName)
+		return nil
+	}
+
+	output, err := formatConfigDifferences(differences)
+	if err != nil {
+		return err // un-tested
+	}
+
+	dpc.stdout.Println(output)
+	return nil
+}
+
+// diffProductConfigs compares only what the config file declares, since
+// configure-product leaves everything else on the staged product untouched.
+func diffProductConfigs(staged, desired config.ProductConfiguration) ([]configDifference, error) {
+	differences, err := diffProductProperties(staged.ProductProperties, desired.ProductProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []struct {
+		name    string
+		staged  interface{}
+		desired interface{}
+	}{
+		{"network-properties", staged.NetworkProperties, desired.NetworkProperties},
+		{"resource-config", staged.ResourceConfigProperties, desired.ResourceConfigProperties},
+		{"errand-config", staged.ErrandConfigs, desired.ErrandConfigs},
+		{"syslog-properties", staged.SyslogProperties, desired.SyslogProperties},
+	}
+
+	for _, section := range sections {
+		sectionDifferences, err := diffConfigSection(section.name, section.staged, section.desired)
+		if err != nil {
+			return nil, err
+		}
+		differences = append(differences, sectionDifferences...)
+	}
+
+	return differences, nil
+}
+
+func diffProductProperties(staged, desired map[string]interface{}) ([]configDifference, error) {
+	normalizedDesired, err := normalizeForDiff(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizedStaged, err := normalizeForDiff(staged)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredValues, _ := normalizedDesired.(map[string]interface{})
+	stagedValues, _ := normalizedStaged.(map[string]interface{})
+
+	var names []string
+	for name := range desiredValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var differences []configDifference
+	for _, name := range names {
+		key := productPropertyKey(desiredValues[name])
+		desiredValue := productPropertyValue(desiredValues[name], key)
+		stagedValue := productPropertyValue(stagedValues[name], key)
+
+		if !matchesStaged(stagedValue, desiredValue) {
+			differences = append(differences, configDifference{
+				Section: "product-properties",
+				Key:     name,
+				Staged:  stagedValue,
+				Config:  desiredValue,
+			})
+		}
+	}
+
+	return differences, nil
+}
+
+// productPropertyKeys are the keys a product property can be set with.
+var productPropertyKeys = []string{"value", "selected_option", "option_value"}
+
+// productPropertyKey is the key the config sets a property with. A staged
+// selector has both its label as the value and its selected_option, so it
+// is compared by the one the config sets.
+func productPropertyKey(property interface{}) string {
+	values, ok := property.(map[string]interface{})
+	if !ok {
+		return "value"
+	}
+
+	for _, key := range productPropertyKeys {
+		if _, ok := values[key]; ok {
+			return key
+		}
+	}
+
+	return "value"
+}
+
+func productPropertyValue(property interface{}, key string) interface{} {
+	values, ok := property.(map[string]interface{})
+	if !ok {
+		return property
+	}
+
+	if value, ok := values[key]; ok {
+		return value
+	}
+
+	for _, key := range productPropertyKeys {
+		if value, ok := values[key]; ok {
+			return value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("DiffProductConfig", func() {
+	var (
+		logger      *fakes.Logger
+		fakeService *fakes.StagedConfigService
+		command     commands.DiffProductConfig
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		fakeService = &fakes.StagedConfigService{}
+		fakeService.InfoReturns(api.Info{Version: "2.5.0"}, nil)
+		fakeService.GetStagedProductByNameReturns(api.StagedProductsFindOutput{
+			Product: api.StagedProduct{GUID: "some-product-guid", Type: "some-product"},
+		}, nil)
+		fakeService.GetStagedProductPropertiesReturns(map[string]api.ResponseProperty{
+			".properties.some-string-property": {
+				Value:        "some-value",
+				Configurable: true,
+			},
+			".properties.some-secret-property": {
+				Type:         "secret",
+				Value:        map[string]interface{}{"secret": "***"},
+				IsCredential: true,
+				Configurable: true,
+			},
+		}, nil)
+		fakeService.GetStagedProductNetworksAndAZsReturns(map[string]interface{}{
+			"singleton_availability_zone": map[string]interface{}{"name": "az-one"},
+		}, nil)
+		fakeService.ListStagedProductJobsReturns(map[string]string{
+			"some-job": "some-job-guid",
+		}, nil)
+		fakeService.GetStagedProductJobResourceConfigReturns(api.JobProperties{
+			"instances":     1,
+			"instance_type": map[string]interface{}{"id": "automatic"},
+		}, nil)
+		fakeService.ListStagedProductErrandsReturns(api.ErrandsListOutput{
+			Errands: []api.Errand{
+				{Name: "some-errand", PostDeploy: true},
+			},
+		}, nil)
+		fakeService.GetStagedProductSyslogConfigurationReturns(map[string]interface{}{
+			"enabled": true,
+			"address": "example.com",
+		}, nil)
+
+		command = commands.NewDiffProductConfig(func() []string { return nil }, fakeService, logger)
+	})
+
+	When("the config matches the staged product", func() {
+		It("reports no differences and succeeds", func() {
+			configFile := writeTestConfigFile(`---
+product-name: some-product
+product-properties:
+  .properties.some-string-property:
+    value: some-value
+  .properties.some-secret-property:
+    value:
+      secret: my-super-secret
+network-properties:
+  singleton_availability_zone:
+    name: az-one
+resource-config:
+  some-job:
+    instances: 1
+errand-config:
+  some-errand:
+    post-deploy-state: true
+syslog-properties:
+  enabled: true
+`)
+			err := command.Execute([]string{"--config", configFile})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.GetStagedProductByNameArgsForCall(0)).To(Equal("some-product"))
+
+			Expect(logger.PrintfCallCount()).To(Equal(1))
+			format, content := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("no differences found between %s and the staged configuration of %q"))
+			Expect(content).To(Equal([]interface{}{configFile, "some-product"}))
+		})
+	})
+
+	When("the config differs from the staged product", func() {
+		var configFile string
+
+		BeforeEach(func() {
+			configFile = writeTestConfigFile(`---
+product-name: some-product
+product-properties:
+  .properties.some-string-property:
+    value: ((some-value))
+network-properties:
+  singleton_availability_zone:
+    name: az-one
+resource-config:
+  some-job:
+    instances: 3
+`)
+		})
+
+		It("prints a diff per changed field and returns an error", func() {
+			err := command.Execute([]string{"--config", configFile, "--var", "some-value=other-value"})
+			Expect(err).To(MatchError(ContainSubstring(`found 2 difference(s) between`)))
+
+			Expect(logger.PrintlnCallCount()).To(Equal(1))
+			Expect(logger.PrintlnArgsForCall(0)).To(Equal([]interface{}{`--- staged (product-properties: .properties.some-string-property)
++++ config (product-properties: .properties.some-string-property)
+-some-value
++other-value
+--- staged (resource-config: some-job)
++++ config (resource-config: some-job)
+-instances: 1
++instances: 3`}))
+		})
+
+		It("prints the differences as json when requested", func() {
+			err := command.Execute([]string{"--config", configFile, "--var", "some-value=other-value", "--format", "json"})
+			Expect(err).To(HaveOccurred())
+
+			Expect(logger.PrintlnCallCount()).To(Equal(1))
+			Expect(logger.PrintlnArgsForCall(0)[0]).To(MatchJSON(`{
+				"product-name": "some-product",
+				"differences": [
+					{
+						"section": "product-properties",
+						"key": ".properties.some-string-property",
+						"staged": "some-value",
+						"config": "other-value"
+					},
+					{
+						"section": "resource-config",
+						"key": "some-job",
+						"staged": {"instances": 1},
+						"config": {"instances": 3}
+					}
+				]
+			}`))
+		})
+	})
+
+	When("the config sets a selector by its selected option", func() {
+		BeforeEach(func() {
+			fakeService.GetStagedProductPropertiesReturns(map[string]api.ResponseProperty{
+				".properties.some-selector": {
+					Type:           "selector",
+					Value:          "Some Option Label",
+					SelectedOption: "some-option",
+					Configurable:   true,
+				},
+			}, nil)
+		})
+
+		It("compares the selected option rather than the label", func() {
+			configFile := writeTestConfigFile(`---
+product-name: some-product
+product-properties:
+  .properties.some-selector:
+    selected_option: some-option
+`)
+			err := command.Execute([]string{"--config", configFile})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("reports a different selected option", func() {
+			configFile := writeTestConfigFile(`---
+product-name: some-product
+product-properties:
+  .properties.some-selector:
+    selected_option: other-option
+`)
+			err := command.Execute([]string{"--config", configFile, "--format", "json"})
+			Expect(err).To(HaveOccurred())
+
+			Expect(logger.PrintlnArgsForCall(0)[0]).To(MatchJSON(`{
+				"product-name": "some-product",
+				"differences": [{
+					"section": "product-properties",
+					"key": ".properties.some-selector",
+					"staged": "some-option",
+					"config": "other-option"
+				}]
+			}`))
+		})
+	})
+
+	When("the config sets a property that is not staged", func() {
+		It("reports the property as a difference", func() {
+			configFile := writeTestConfigFile(`---
+product-name: some-product
+product-properties:
+  .properties.missing-property:
+    value: some-value
+`)
+			err := command.Execute([]string{"--config", configFile, "--format", "json"})
+			Expect(err).To(HaveOccurred())
+
+			Expect(logger.PrintlnArgsForCall(0)[0]).To(MatchJSON(`{
+				"product-name": "some-product",
+				"differences": [{
+					"section": "product-properties",
+					"key": ".properties.missing-property",
+					"staged": null,
+					"config": "some-value"
+				}]
+			}`))
+		})
+	})
+
+	Context("failure cases", func() {
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse diff-product-config flags: flag provided but not defined: -badflag"))
+			})
+		})
+
+		When("an unsupported format is provided", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--config", "some-file", "--format", "xml"})
+				Expect(err).To(MatchError(`unsupported format "xml": must be one of text,json`))
+			})
+		})
+
+		When("the config does not have a product name", func() {
+			It("returns an error", func() {
+				configFile := writeTestConfigFile(`product-properties: {}`)
+				err := command.Execute([]string{"--config", configFile})
+				Expect(err).To(MatchError(ContainSubstring(`"product-name" is required`)))
+			})
+		})
+
+		When("the staged product cannot be found", func() {
+			It("returns an error", func() {
+				fakeService.GetStagedProductByNameReturns(api.StagedProductsFindOutput{}, errors.New("could not find product"))
+
+				configFile := writeTestConfigFile(`product-name: some-product`)
+				err := command.Execute([]string{"--config", configFile})
+				Expect(err).To(MatchError("could not find product"))
+			})
+		})
+	})
+
+	Describe("Usage", func() {
+		It("returns usage information for the command", func() {
+			Expect(command.Usage()).To(Equal(jhanda.Usage{
+				Description:      "This authenticated command compares a configure-product config file against the staged product and exits non-zero when they differ. Credentials cannot be read back from Ops Manager and are not compared.",
+				ShortDescription: "**EXPERIMENTAL** compares a configure-product config with the staged product",
+				Flags:            command.Options,
+			}))
+		})
+	})
+})
@@ -13,6 +13,7 @@ import (
 
 	"github.com/hashicorp/go-version"
 	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/cache"
 	"github.com/pivotal-cf/om/validator"
 )
 
@@ -33,45 +34,50 @@ type ProductDownloader interface {
 	Name() string
 	GetAllProductVersions(slug string) ([]string, error)
 	GetLatestProductFile(slug, version, glob string) (FileArtifacter, error)
+	// DownloadProductToFile continues after what file already holds from an
+	// interrupted download, or starts again when it cannot.
 	DownloadProductToFile(fa FileArtifacter, file *os.File) error
-	GetLatestStemcellForProduct(fa FileArtifacter, downloadedProductFileName string) (StemcellArtifacter, error)
+	// GetLatestStemcellForProduct ignores stemcell versions that do not
+	// satisfy constraints, unless they are nil.
+	GetLatestStemcellForProduct(fa FileArtifacter, downloadedProductFileName string, constraints version.Constraints) (StemcellArtifacter, error)
 }
 
 type DownloadProductOptions struct {
-	Source     string   `long:"source"                short:"s"  description:"enables download from external sources when set to [s3|gcs|azure|pivnet]" default:"pivnet"`
+	Source     string   `long:"source"                short:"s"  description:"enables download from external sources when set to [s3|gcs|azure|pivnet|file|http]" default:"pivnet"`
 	ConfigFile string   `long:"config"                short:"c"  description:"path to yml file for configuration (keys must match the following command line flags)"`
 	OutputDir  string   `long:"output-directory"      short:"o"  description:"directory path to which the file will be outputted. File Name will be preserved from Pivotal Network" required:"true"`
 	VarsEnv    []string `long:"vars-env" env:"OM_VARS_ENV" experimental:"true" description:"load variables from environment variables matching the provided prefix (e.g.: 'MY' to load MY_var=value)"`
 	VarsFile   []string `long:"vars-file" short:"l"  description:"load variables from a YAML file"`
 	Vars       []string `long:"var"                              description:"Load variable from the command line. Format: VAR=VAL"`
 
-	PivnetFileGlob      string `long:"pivnet-file-glob"      short:"f"  description:"glob to match files within Pivotal Network product to be downloaded." required:"true"`
-	PivnetProductSlug   string `long:"pivnet-product-slug"   short:"p"  description:"path to product" required:"true"`
-	PivnetDisableSSL    bool   `long:"pivnet-disable-ssl"               description:"whether to disable ssl validation when contacting the Pivotal Network"`
-	PivnetToken         string `long:"pivnet-api-token"      short:"t"  description:"API token to use when interacting with Pivnet. Can be retrieved from your profile page in Pivnet."`
-	ProductVersion      string `long:"product-version"       short:"v"  description:"version of the product-slug to download files from. Incompatible with --product-version-regex flag."`
-	ProductVersionRegex string `long:"product-version-regex" short:"r"  description:"regex pattern matching versions of the product-slug to download files from. Highest-versioned match will be used. Incompatible with --product-version flag."`
+	PivnetFileGlob           string `long:"pivnet-file-glob"      short:"f"  description:"glob to match files within Pivotal Network product to be downloaded." required:"true"`
+	PivnetProductSlug        string `long:"pivnet-product-slug"   short:"p"  description:"path to product" required:"true"`
+	PivnetDisableSSL         bool   `long:"pivnet-disable-ssl"               description:"whether to disable ssl validation when contacting the Pivotal Network"`
+	PivnetToken              string `long:"pivnet-api-token"      short:"t"  description:"API token to use when interacting with Pivnet. Can be retrieved from your profile page in Pivnet."`
+	ProductVersion           string `long:"product-version"       short:"v"  description:"version of the product-slug to download files from. Incompatible with --product-version-regex flag."`
+	ProductVersionRegex      string `long:"product-version-regex" short:"r"  description:"regex pattern matching versions of the product-slug to download files from. Highest-versioned match will be used. Incompatible with --product-version flag."`
+	ProductVersionConstraint string `long:"product-version-constraint" description:"version constraint, such as '~> 2.7.0' or '>= 1.4, < 1.5', for the versions of the product-slug to download files from. Highest-versioned match will be used. Incompatible with --product-version and --product-version-regex flags."`
+	ExcludePrereleases       bool   `long:"exclude-prereleases"        description:"ignore pre-release versions, such as 2.7.0-rc.1 or 2.7.0-build.1, when choosing the highest version matching --product-version-regex or --product-version-constraint"`
 
-	Bucket       string `long:"blobstore-bucket" alias:"s3-bucket,gcs-bucket,azure-container" description:"bucket name where the product resides in the s3|gcs|azure compatible blobstore"`
-	ProductPath  string `long:"blobstore-product-path" alias:"s3-product-path,gcs-product-path,azure-product-path" description:"specify the lookup path where the s3|gcs|azure product artifacts are stored"`
-	StemcellPath string `long:"blobstore-stemcell-path" alias:"s3-stemcell-path,gcs-stemcell-path,azure-stemcell-path" description:"specify the lookup path where the s3|gcs|azure stemcell artifacts are stored"`
+	BlobstoreFlags
 
-	GCSServiceAccountJSON string `long:"gcs-service-account-json" alias:"gcp-service-account-json" description:"the service account key JSON"`
-	GCSProjectID          string `long:"gcs-project-id" alias:"gcp-project-id" description:"the project id for the bucket's gcp account"`
+	ProductPath  string `long:"blobstore-product-path" alias:"s3-product-path,gcs-product-path,azure-product-path" description:"specify the lookup path where the s3|gcs|azure|file|http product artifacts are stored"`
+	StemcellPath string `long:"blobstore-stemcell-path" alias:"s3-stemcell-path,gcs-stemcell-path,azure-stemcell-path" description:"specify the lookup path where the s3|gcs|azure|file|http stemcell artifacts are stored"`
 
-	S3AccessKeyID     string `long:"s3-access-key-id"                 description:"access key for the s3 compatible blobstore"`
-	S3AuthType        string `long:"s3-auth-type"                     description:"can be set to \"iam\" in order to allow use of instance credentials" default:"accesskey"`
-	S3SecretAccessKey string `long:"s3-secret-access-key"             description:"secret key for the s3 compatible blobstore"`
-	S3RegionName      string `long:"s3-region-name"                   description:"bucket region in the s3 compatible blobstore. If not using AWS, this value is 'region'"`
-	S3Endpoint        string `long:"s3-endpoint"                      description:"the endpoint to access the s3 compatible blobstore. If not using AWS, this is required"`
-	S3DisableSSL      bool   `long:"s3-disable-ssl"                   description:"whether to disable ssl validation when contacting the s3 compatible blobstore"`
-	S3EnableV2Signing bool   `long:"s3-enable-v2-signing"             description:"whether to use v2 signing with your s3 compatible blobstore. (if you don't know what this is, leave blank, or set to 'false')"`
+	FileDirectory string `long:"file-directory" description:"the directory, such as an NFS mount, in which the product and stemcell paths are, when the source is file"`
 
-	AzureStorageAccount string `long:"azure-storage-account" description:"the name of the storage account where the container exists"`
-	AzureKey            string `long:"azure-storage-key" description:"the access key for the storage account"`
+	HTTPURL        string `long:"http-url"         description:"the url of the artifact server under which the product and stemcell paths are, when the source is http"`
+	HTTPIndexFile  string `long:"http-index-file"  description:"the path, relative to the http url, of a file listing the artifacts one per line. Without it, the HTML directory listings of the product and stemcell paths are read"`
+	HTTPDisableSSL bool   `long:"http-disable-ssl" description:"whether to disable ssl validation when contacting the artifact server"`
+
+	ParallelConnections int `long:"parallel-connections" description:"how many connections to download the file with from pivnet, s3, gcs or azure, each fetching its own range of bytes. Defaults to 10 for pivnet and 1 for the other sources"`
+
+	CacheDir string `long:"cache-dir" description:"a directory in which downloaded files are kept by their SHA256, which can be shared by several output directories. A file that is already in it is hard-linked, or copied, to the output directory instead of downloaded again. Only files whose SHA256 is known before downloading, such as those from pivnet, are cached"`
 
 	Stemcell     bool   `long:"download-stemcell"                description:"no-op for backwards compatibility"`
 	StemcellIaas string `long:"stemcell-iaas"                    description:"download the latest available stemcell for the product for the specified iaas. for example 'vsphere' or 'vcloud' or 'openstack' or 'google' or 'azure' or 'aws'"`
+
+	StemcellVersionConstraint string `long:"stemcell-version-constraint" description:"version constraint, such as '~> 456.0' or '< 621', that the stemcell downloaded with --stemcell-iaas must also satisfy"`
 }
 
 type DownloadProduct struct {
@@ -80,6 +86,7 @@ type DownloadProduct struct {
 	stderr         *log.Logger
 	stdout         *log.Logger
 	downloadClient ProductDownloader
+	downloadLocks  *downloadLocks
 	Options        DownloadProductOptions
 }
 
@@ -121,11 +128,49 @@ func (c *DownloadProduct) Execute(args []string) error {
 		return err
 	}
 
-	productVersion, err := c.determineProductVersion()
+	downloaded, err := c.download()
+	if err != nil {
+		return err
+	}
+
+	err = c.writeDownloadProductOutput(downloaded)
 	if err != nil {
 		return err
 	}
 
+	if downloaded.StemcellVersion == "" {
+		return nil
+	}
+
+	return c.writeAssignStemcellInput(downloaded.ProductPath, downloaded.StemcellVersion)
+}
+
+// downloadedProduct is what download-file.json records about a download.
+type downloadedProduct struct {
+	ProductPath     string `json:"product_path,omitempty"`
+	ProductSlug     string `json:"product_slug,omitempty"`
+	ProductVersion  string `json:"product_version,omitempty"`
+	StemcellPath    string `json:"stemcell_path,omitempty"`
+	StemcellVersion string `json:"stemcell_version,omitempty"`
+}
+
+// download downloads the product, and its stemcell when --stemcell-iaas is
+// set, with the client that has already been created.
+func (c *DownloadProduct) download() (downloadedProduct, error) {
+	var stemcellConstraints version.Constraints
+	if c.Options.StemcellVersionConstraint != "" {
+		var err error
+		stemcellConstraints, err = version.NewConstraint(c.Options.StemcellVersionConstraint)
+		if err != nil {
+			return downloadedProduct{}, fmt.Errorf("could not parse stemcell version constraint '%s': %s", c.Options.StemcellVersionConstraint, err)
+		}
+	}
+
+	productVersion, err := c.determineProductVersion()
+	if err != nil {
+		return downloadedProduct{}, err
+	}
+
 	productFileName, productFileArtifact, err := c.downloadProductFile(
 		c.Options.PivnetProductSlug,
 		productVersion,
@@ -133,11 +178,17 @@ func (c *DownloadProduct) Execute(args []string) error {
 		fmt.Sprintf("[%s,%s]", c.Options.PivnetProductSlug, productVersion),
 	)
 	if err != nil {
-		return fmt.Errorf("could not download product: %s", err)
+		return downloadedProduct{}, fmt.Errorf("could not download product: %s", err)
+	}
+
+	downloaded := downloadedProduct{
+		ProductPath:    productFileName,
+		ProductSlug:    c.Options.PivnetProductSlug,
+		ProductVersion: productVersion,
 	}
 
 	if c.Options.StemcellIaas == "" {
-		return c.writeDownloadProductOutput(productFileName, productVersion, "", "")
+		return downloaded, nil
 	}
 
 	c.stderr.Printf("Downloading stemcell")
@@ -145,12 +196,12 @@ func (c *DownloadProduct) Execute(args []string) error {
 	nameParts := strings.Split(productFileName, ".")
 	if nameParts[len(nameParts)-1] != "pivotal" {
 		c.stderr.Printf("the downloaded file is not a .pivotal file. Not determining and fetching required stemcell.")
-		return c.writeDownloadProductOutput(productFileName, productVersion, "", "")
+		return downloaded, nil
 	}
 
-	stemcell, err := c.downloadClient.GetLatestStemcellForProduct(productFileArtifact, productFileName)
+	stemcell, err := c.downloadClient.GetLatestStemcellForProduct(productFileArtifact, productFileName, stemcellConstraints)
 	if err != nil {
-		return fmt.Errorf("could not get information about stemcell: %s", err)
+		return downloadedProduct{}, fmt.Errorf("could not get information about stemcell: %s", err)
 	}
 
 	stemcellFileName, _, err := c.downloadProductFile(
@@ -160,56 +211,79 @@ func (c *DownloadProduct) Execute(args []string) error {
 		fmt.Sprintf("[%s,%s]", stemcell.Slug(), stemcell.Version()),
 	)
 	if err != nil {
-		return fmt.Errorf("could not download stemcell: %s\nNo stemcell identified on on PivNet. Remove -stemcell-iaas and/or contact support", err)
+		return downloadedProduct{}, fmt.Errorf("could not download stemcell: %s\nNo stemcell identified on on PivNet. Remove -stemcell-iaas and/or contact support", err)
 	}
 
-	err = c.writeDownloadProductOutput(productFileName, productVersion, stemcellFileName, stemcell.Version())
-	if err != nil {
-		return err
-	}
+	downloaded.StemcellPath = stemcellFileName
+	downloaded.StemcellVersion = stemcell.Version()
 
-	return c.writeAssignStemcellInput(productFileName, stemcell.Version())
+	return downloaded, nil
 }
 
 func (c *DownloadProduct) determineProductVersion() (string, error) {
-	if c.Options.ProductVersionRegex != "" {
-		re, err := regexp.Compile(c.Options.ProductVersionRegex)
+	var (
+		re          *regexp.Regexp
+		constraints version.Constraints
+		matching    string
+		err         error
+	)
+
+	switch {
+	case c.Options.ProductVersionRegex != "":
+		re, err = regexp.Compile(c.Options.ProductVersionRegex)
 		if err != nil {
 			return "", fmt.Errorf("could not compile regex '%s': %s", c.Options.ProductVersionRegex, err)
 		}
-
-		productVersions, err := c.downloadClient.GetAllProductVersions(c.Options.PivnetProductSlug)
+		matching = fmt.Sprintf("product version regex '%s'", c.Options.ProductVersionRegex)
+	case c.Options.ProductVersionConstraint != "":
+		constraints, err = version.NewConstraint(c.Options.ProductVersionConstraint)
 		if err != nil {
-			return "", err
+			return "", fmt.Errorf("could not parse product version constraint '%s': %s", c.Options.ProductVersionConstraint, err)
+		}
+		matching = fmt.Sprintf("product version constraint '%s'", c.Options.ProductVersionConstraint)
+	default:
+		return c.Options.ProductVersion, nil
+	}
+
+	productVersions, err := c.downloadClient.GetAllProductVersions(c.Options.PivnetProductSlug)
+	if err != nil {
+		return "", err
+	}
+
+	var versions version.Collection
+	for _, productVersion := range productVersions {
+		if re != nil && !re.MatchString(productVersion) {
+			continue
 		}
 
-		var versions version.Collection
-		for _, productVersion := range productVersions {
-			if !re.MatchString(productVersion) {
-				continue
-			}
-
-			v, err := version.NewVersion(productVersion)
-			if err != nil {
-				c.stderr.Printf(fmt.Sprintf("warning: could not parse semver version from: %s", productVersion))
-				continue
-			}
-			versions = append(versions, v)
+		v, err := version.NewVersion(productVersion)
+		if err != nil {
+			c.stderr.Printf(fmt.Sprintf("warning: could not parse semver version from: %s", productVersion))
+			continue
 		}
 
-		sort.Sort(versions)
+		if constraints != nil && !constraints.Check(v) {
+			continue
+		}
 
-		if len(versions) == 0 {
-			existingVersions := strings.Join(productVersions, ", ")
-			if existingVersions == "" {
-				existingVersions = "none"
-			}
-			return "", fmt.Errorf("no valid versions found for product '%s' and product version regex '%s'\nexisting versions: %s", c.Options.PivnetProductSlug, c.Options.ProductVersionRegex, existingVersions)
+		if c.Options.ExcludePrereleases && v.Prerelease() != "" {
+			continue
 		}
 
-		return versions[len(versions)-1].Original(), nil
+		versions = append(versions, v)
 	}
-	return c.Options.ProductVersion, nil
+
+	sort.Sort(versions)
+
+	if len(versions) == 0 {
+		existingVersions := strings.Join(productVersions, ", ")
+		if existingVersions == "" {
+			existingVersions = "none"
+		}
+		return "", fmt.Errorf("no valid versions found for product '%s' and %s\nexisting versions: %s", c.Options.PivnetProductSlug, matching, existingVersions)
+	}
+
+	return versions[len(versions)-1].Original(), nil
 }
 
 func (c *DownloadProduct) createClient() error {
@@ -232,32 +306,34 @@ func (c *DownloadProduct) validate() error {
 		return fmt.Errorf("cannot use both --product-version and --product-version-regex; please choose one or the other")
 	}
 
-	if c.Options.ProductVersionRegex == "" && c.Options.ProductVersion == "" {
-		return fmt.Errorf("no version information provided; please provide either --product-version or --product-version-regex")
+	versionFlags := 0
+	for _, value := range []string{c.Options.ProductVersion, c.Options.ProductVersionRegex, c.Options.ProductVersionConstraint} {
+		if value != "" {
+			versionFlags++
+		}
+	}
+
+	if versionFlags > 1 {
+		return fmt.Errorf("cannot use more than one of --product-version, --product-version-regex and --product-version-constraint; please choose one")
+	}
+
+	if versionFlags == 0 {
+		return fmt.Errorf("no version information provided; please provide either --product-version, --product-version-regex or --product-version-constraint")
 	}
 	if c.Options.PivnetToken == "" && c.Options.Source == "pivnet" {
 		return fmt.Errorf(`could not execute "download-product": could not parse download-product flags: missing required flag "--pivnet-api-token"`)
 	}
 
+	if c.Options.ParallelConnections < 0 {
+		return fmt.Errorf("--parallel-connections must be at least 1")
+	}
+
 	return nil
 }
 
-func (c DownloadProduct) writeDownloadProductOutput(productFileName string, productVersion string, stemcellFileName string, stemcellVersion string) error {
+func (c DownloadProduct) writeDownloadProductOutput(downloaded downloadedProduct) error {
 	downloadProductFilename := "download-file.json"
 	c.stderr.Printf("Writing a list of downloaded artifact to %s", downloadProductFilename)
-	downloadProductPayload := struct {
-		ProductPath     string `json:"product_path,omitempty"`
-		ProductSlug     string `json:"product_slug,omitempty"`
-		ProductVersion  string `json:"product_version,omitempty"`
-		StemcellPath    string `json:"stemcell_path,omitempty"`
-		StemcellVersion string `json:"stemcell_version,omitempty"`
-	}{
-		ProductPath:     productFileName,
-		StemcellPath:    stemcellFileName,
-		ProductSlug:     c.Options.PivnetProductSlug,
-		ProductVersion:  productVersion,
-		StemcellVersion: stemcellVersion,
-	}
 
 	outputFile, err := os.Create(filepath.Join(c.Options.OutputDir, downloadProductFilename))
 	if err != nil {
@@ -265,7 +341,7 @@ func (c DownloadProduct) writeDownloadProductOutput(productFileName string, prod
 	}
 	defer outputFile.Close()
 
-	err = json.NewEncoder(outputFile).Encode(downloadProductPayload)
+	err = json.NewEncoder(outputFile).Encode(downloaded)
 	if err != nil {
 		return fmt.Errorf("could not encode JSON for %s: %s", downloadProductFilename, err)
 	}
@@ -316,34 +392,60 @@ func (c *DownloadProduct) downloadProductFile(slug, version, glob, prefixPath st
 		productFilePath = filepath.Join(c.Options.OutputDir, prefixPath+filepath.Base(fileArtifact.Name()))
 	}
 
+	err = c.downloadFileArtifact(fileArtifact, productFilePath)
+	if err != nil {
+		return productFilePath, fileArtifact, err
+	}
+
+	return productFilePath, fileArtifact, nil
+}
+
+// downloadFileArtifact downloads a file that the client has found to
+// productFilePath, unless it is already there with the same sha sum.
+func (c *DownloadProduct) downloadFileArtifact(fileArtifact FileArtifacter, productFilePath string) error {
+	if c.downloadLocks != nil {
+		defer c.downloadLocks.lock(productFilePath)()
+	}
+
 	c.stderr.Printf("attempting to download the file %s from source %s", fileArtifact.Name(), c.downloadClient.Name())
 
 	// check for already downloaded file
 	exist, err := checkFileExists(productFilePath)
 	if err != nil {
-		return productFilePath, nil, err
+		return err
 	}
 
 	if exist {
 		if ok, _ := c.shasumMatches(productFilePath, fileArtifact.SHA256()); ok {
 			c.stderr.Printf("%s already exists, skip downloading", productFilePath)
-			return productFilePath, fileArtifact, nil
+			return nil
 		} else {
 			c.stderr.Printf("%s already exists, sha sum does not match, re-downloading", productFilePath)
 		}
 	}
 
+	sum := fileArtifact.SHA256()
+	if c.Options.CacheDir != "" && sum != "" {
+		if c.linkFromCache(sum, productFilePath) {
+			return nil
+		}
+	}
+
 	partialProductFilePath := productFilePath + ".partial"
-	// create a new file to download
-	productFile, err := os.Create(partialProductFilePath)
+	// keep what an interrupted download has already written, so that it can continue from there
+	productFile, err := os.OpenFile(partialProductFilePath, os.O_RDWR|os.O_CREATE, 0666)
 	if err != nil {
-		return "", nil, fmt.Errorf("could not create file %s: %s", productFilePath, err)
+		return fmt.Errorf("could not create file %s: %s", productFilePath, err)
 	}
 	defer productFile.Close()
 
+	if info, err := productFile.Stat(); err == nil && info.Size() > 0 {
+		c.stderr.Printf("found %d bytes of a previous download in %s, attempting to resume", info.Size(), partialProductFilePath)
+	}
+
 	err = c.downloadClient.DownloadProductToFile(fileArtifact, productFile)
 	if err != nil {
-		return productFilePath, fileArtifact, err
+		return err
 	}
 
 	// check for correct sha on newly downloaded file
@@ -356,11 +458,51 @@ func (c *DownloadProduct) downloadProductFile(slug, version, glob, prefixPath st
 		)
 		c.stderr.Print(e)
 		_ = os.Remove(partialProductFilePath)
-		return productFilePath, fileArtifact, fmt.Errorf(e)
+		return fmt.Errorf(e)
 	}
 
 	_ = os.Rename(partialProductFilePath, productFilePath)
-	return productFilePath, fileArtifact, nil
+
+	if c.Options.CacheDir != "" {
+		if sum == "" {
+			c.stderr.Printf("the sha sum of %s is not known before downloading it, not adding it to the cache", fileArtifact.Name())
+			return nil
+		}
+
+		err = cache.New(c.Options.CacheDir).Add(sum, productFilePath)
+		if err != nil {
+			c.stderr.Printf("could not add %s to the cache: %s", productFilePath, err)
+		}
+	}
+
+	return nil
+}
+
+// linkFromCache links the file with the sha sum from the cache to
+// productFilePath. A cache that cannot be read is a warning, as the file can
+// still be downloaded.
+func (c *DownloadProduct) linkFromCache(sum, productFilePath string) bool {
+	downloadCache := cache.New(c.Options.CacheDir)
+
+	c.stderr.Printf("looking for the file with sha sum %s in the cache %s", sum, c.Options.CacheDir)
+	entry, ok, err := downloadCache.Get(sum)
+	if err != nil {
+		c.stderr.Printf("could not read the cache: %s", err)
+		return false
+	}
+
+	if !ok {
+		return false
+	}
+
+	err = downloadCache.LinkTo(entry, productFilePath)
+	if err != nil {
+		c.stderr.Printf("could not link %s from the cache: %s", entry.Path, err)
+		return false
+	}
+
+	c.stderr.Printf("found %s in the cache, skip downloading", entry.Name)
+	return true
 }
 
 func (c *DownloadProduct) shasumMatches(path, exepectedSum string) (bool, string) {
@@ -2,8 +2,10 @@ package commands_test
 
 import (
 	"archive/zip"
+	"crypto/sha256"
 	"fmt"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/pivotal-cf/jhanda"
@@ -79,6 +81,74 @@ var _ = Describe("DownloadProduct", func() {
 				err = command.Execute(commandArgs)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("keeps what an interrupted download has written to the partial file", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				partialFilePath := filepath.Join(tempDir, "cf-2.0-build.1.pivotal.partial")
+				err = ioutil.WriteFile(partialFilePath, []byte("cf 2."), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				fakeProductDownloader.DownloadProductToFileStub = func(_ commands.FileArtifacter, file *os.File) error {
+					contents, err := ioutil.ReadFile(file.Name())
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(contents)).To(Equal("cf 2."))
+					return nil
+				}
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version", "2.0.0",
+					"--output-directory", tempDir,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(1))
+				Expect(buffer).To(gbytes.Say("found 5 bytes of a previous download"))
+			})
+
+			It("links the file from the --cache-dir instead of downloading it again", func() {
+				cacheDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(cacheDir)
+
+				fa := &fakes.FileArtifacter{}
+				fa.NameReturns("/some-account/some-bucket/cf-2.0-build.1.pivotal")
+				fa.SHA256Returns(fmt.Sprintf("%x", sha256.Sum256([]byte("cf 2.0"))))
+				fakeProductDownloader.GetLatestProductFileReturns(fa, nil)
+				fakeProductDownloader.DownloadProductToFileStub = func(_ commands.FileArtifacter, file *os.File) error {
+					_, err := file.WriteString("cf 2.0")
+					return err
+				}
+
+				download := func() string {
+					tempDir, err := ioutil.TempDir("", "om-tests-")
+					Expect(err).ToNot(HaveOccurred())
+
+					err = command.Execute([]string{
+						"--pivnet-api-token", "token",
+						"--pivnet-file-glob", "*.pivotal",
+						"--pivnet-product-slug", "elastic-runtime",
+						"--product-version", "2.0.0",
+						"--output-directory", tempDir,
+						"--cache-dir", cacheDir,
+					})
+					Expect(err).ToNot(HaveOccurred())
+
+					contents, err := ioutil.ReadFile(filepath.Join(tempDir, "cf-2.0-build.1.pivotal"))
+					Expect(err).ToNot(HaveOccurred())
+					return string(contents)
+				}
+
+				Expect(download()).To(Equal("cf 2.0"))
+				Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(1))
+
+				Expect(download()).To(Equal("cf 2.0"))
+				Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(1))
+				Expect(buffer).To(gbytes.Say("found cf-2.0-build.1.pivotal in the cache, skip downloading"))
+			})
 		})
 
 		When("a valid product-version-regex is provided", func() {
@@ -175,6 +245,87 @@ var _ = Describe("DownloadProduct", func() {
 			})
 		})
 
+		When("a product-version-constraint is provided", func() {
+			BeforeEach(func() {
+				fakeProductDownloader.GetAllProductVersionsReturns(
+					[]string{"2.7.0", "2.7.12", "2.7.3", "2.8.0", "2.7.13-build.2", "2.6.20"},
+					nil,
+				)
+				fa := &fakes.FileArtifacter{}
+				fa.NameReturns("/some-account/some-bucket/cf-2.7.12-build.1.pivotal")
+				fakeProductDownloader.GetLatestProductFileReturnsOnCall(0, fa, nil)
+			})
+
+			DescribeTable("downloads the highest version satisfying the constraint", func(constraint string, expectedVersion string) {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				commandArgs := []string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version-constraint", constraint,
+					"--output-directory", tempDir,
+				}
+
+				err = command.Execute(commandArgs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, version, _ := fakeProductDownloader.GetLatestProductFileArgsForCall(0)
+				Expect(version).To(Equal(expectedVersion))
+			},
+				Entry("with a pessimistic constraint", "~> 2.7.0", "2.7.12"),
+				Entry("with a range", ">= 2.6, < 2.7", "2.6.20"),
+				Entry("with a pre-release in the constraint", "~> 2.7.13-build.1", "2.7.13-build.2"),
+			)
+
+			It("ignores pre-releases matching a regex when they are excluded", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version-regex", `^2\.7\.`,
+					"--exclude-prereleases",
+					"--output-directory", tempDir,
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, version, _ := fakeProductDownloader.GetLatestProductFileArgsForCall(0)
+				Expect(version).To(Equal("2.7.12"))
+			})
+
+			It("returns an error when no version satisfies the constraint", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version-constraint", "~> 3.0",
+					"--output-directory", tempDir,
+				})
+				Expect(err).To(MatchError("no valid versions found for product 'elastic-runtime' and product version constraint '~> 3.0'\nexisting versions: 2.7.0, 2.7.12, 2.7.3, 2.8.0, 2.7.13-build.2, 2.6.20"))
+			})
+
+			It("returns an error when the constraint cannot be parsed", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version-constraint", "about 2.7",
+					"--output-directory", tempDir,
+				})
+				Expect(err).To(MatchError(ContainSubstring("could not parse product version constraint 'about 2.7'")))
+			})
+		})
+
 		When("a file is being downloaded with a SHA sum value from the downloader", func() {
 			When("the shasum is valid for the downloaded file", func() {
 				BeforeEach(func() {
@@ -309,6 +460,80 @@ var _ = Describe("DownloadProduct", func() {
 							}`))
 			})
 
+			It("writes the same assign-stemcell.yml when the source is a blobstore", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				fakeProductDownloader.DownloadProductToFileStub = func(artifacter commands.FileArtifacter, file *os.File) error {
+					createTempZipFile(file)
+					return nil
+				}
+
+				err = command.Execute([]string{
+					"--source", "s3",
+					"--blobstore-bucket", "some-bucket",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version", "2.0.0",
+					"--output-directory", tempDir,
+					"--stemcell-iaas", "google",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeProductDownloader.GetLatestStemcellForProductCallCount()).To(Equal(1))
+				_, productFileName, _ := fakeProductDownloader.GetLatestStemcellForProductArgsForCall(0)
+				Expect(productFileName).To(Equal(filepath.Join(tempDir, "cf-2.0-build.1.pivotal")))
+
+				fileContent, err := ioutil.ReadFile(filepath.Join(tempDir, "assign-stemcell.yml"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(fileContent)).To(MatchJSON(`
+							{
+								"product": "fake-tile",
+								"stemcell": "97.190"
+							}`))
+			})
+
+			It("passes the stemcell-version-constraint to the downloader", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				fakeProductDownloader.DownloadProductToFileStub = func(artifacter commands.FileArtifacter, file *os.File) error {
+					createTempZipFile(file)
+					return nil
+				}
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version", "2.0.0",
+					"--output-directory", tempDir,
+					"--stemcell-iaas", "google",
+					"--stemcell-version-constraint", "~> 97.0",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, constraints := fakeProductDownloader.GetLatestStemcellForProductArgsForCall(0)
+				Expect(constraints.String()).To(Equal("~> 97.0"))
+			})
+
+			It("returns an error before downloading when the stemcell-version-constraint cannot be parsed", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version", "2.0.0",
+					"--output-directory", tempDir,
+					"--stemcell-iaas", "google",
+					"--stemcell-version-constraint", "newest",
+				})
+				Expect(err).To(MatchError(ContainSubstring("could not parse stemcell version constraint 'newest'")))
+				Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(0))
+			})
+
 			Context("and the product is not a tile", func() {
 				BeforeEach(func() {
 					fa := &fakes.FileArtifacter{}
@@ -700,6 +925,40 @@ output-directory: %s
 			})
 		})
 
+		When("a product-version-constraint is set with another version flag", func() {
+			It("fails with an error saying that the user must pick one", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version-regex", ".*",
+					"--product-version-constraint", "~> 2.0",
+					"--output-directory", tempDir,
+				})
+				Expect(err).To(MatchError("cannot use more than one of --product-version, --product-version-regex and --product-version-constraint; please choose one"))
+			})
+		})
+
+		When("parallel-connections is less than one", func() {
+			It("returns an error", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--pivnet-file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version", "2.0.0",
+					"--output-directory", tempDir,
+					"--parallel-connections", "-1",
+				})
+				Expect(err).To(MatchError("--parallel-connections must be at least 1"))
+			})
+		})
+
 		When("neither product-version nor product-version-regex are set", func() {
 			It("fails with an error saying that the user must provide one or the other", func() {
 				tempDir, err := ioutil.TempDir("", "om-tests-")
@@ -711,7 +970,7 @@ output-directory: %s
 					"--pivnet-product-slug", "elastic-runtime",
 					"--output-directory", tempDir,
 				})
-				Expect(err).To(MatchError(ContainSubstring("no version information provided; please provide either --product-version or --product-version-regex")))
+				Expect(err).To(MatchError(ContainSubstring("no version information provided; please provide either --product-version, --product-version-regex or --product-version-constraint")))
 			})
 		})
 
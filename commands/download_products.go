@@ -0,0 +1,348 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/interpolate"
+	"gopkg.in/yaml.v2"
+)
+
+const downloadProductsFilename = "download-products.json"
+
+type DownloadProducts struct {
+	environFunc    func() []string
+	progressWriter io.Writer
+	stderr         *log.Logger
+	stdout         *log.Logger
+	Options        struct {
+		Manifest    string   `long:"manifest"              short:"m"  description:"path to a yml file listing the products to download" required:"true"`
+		Concurrency int      `long:"concurrency"                      description:"how many products to download at once" default:"4"`
+		Source      string   `long:"source"                short:"s"  description:"enables download from external sources when set to [s3|gcs|azure|pivnet|file|http]" default:"pivnet"`
+		ConfigFile  string   `long:"config"                short:"c"  description:"path to yml file for configuration (keys must match the following command line flags)"`
+		OutputDir   string   `long:"output-directory"      short:"o"  description:"directory path to which the files will be outputted. File Names will be preserved from Pivotal Network" required:"true"`
+		VarsEnv     []string `long:"vars-env" env:"OM_VARS_ENV" experimental:"true" description:"load variables from environment variables matching the provided prefix (e.g.: 'MY' to load MY_var=value)"`
+		VarsFile    []string `long:"vars-file" short:"l"  description:"load variables from a YAML file"`
+		Vars        []string `long:"var"                              description:"Load variable from the command line. Format: VAR=VAL"`
+
+		PivnetDisableSSL bool   `long:"pivnet-disable-ssl"               description:"whether to disable ssl validation when contacting the Pivotal Network"`
+		PivnetToken      string `long:"pivnet-api-token"      short:"t"  description:"API token to use when interacting with Pivnet. Can be retrieved from your profile page in Pivnet."`
+
+		ProductPath  string `long:"blobstore-product-path" alias:"s3-product-path,gcs-product-path,azure-product-path" description:"specify the lookup path where the s3|gcs|azure|file|http product artifacts are stored"`
+		StemcellPath string `long:"blobstore-stemcell-path" alias:"s3-stemcell-path,gcs-stemcell-path,azure-stemcell-path" description:"specify the lookup path where the s3|gcs|azure|file|http stemcell artifacts are stored"`
+
+		BlobstoreFlags
+
+		FileDirectory string `long:"file-directory" description:"the directory, such as an NFS mount, in which the product and stemcell paths are, when the source is file"`
+
+		HTTPURL        string `long:"http-url"         description:"the url of the artifact server under which the product and stemcell paths are, when the source is http"`
+		HTTPIndexFile  string `long:"http-index-file"  description:"the path, relative to the http url, of a file listing the artifacts one per line. Without it, the HTML directory listings of the product and stemcell paths are read"`
+		HTTPDisableSSL bool   `long:"http-disable-ssl" description:"whether to disable ssl validation when contacting the artifact server"`
+
+		ParallelConnections int `long:"parallel-connections" description:"how many connections to download each file with from pivnet, s3, gcs or azure, each fetching its own range of bytes. Defaults to 10 for pivnet and 1 for the other sources"`
+
+		CacheDir string `long:"cache-dir" description:"a directory in which downloaded files are kept by their SHA256, as with download-product"`
+	}
+}
+
+// downloadProductsManifest lists the products to download. Its keys match
+// the download-product flags for a single product.
+type downloadProductsManifest struct {
+	Products []downloadProductsManifestProduct `yaml:"products"`
+}
+
+type downloadProductsManifestProduct struct {
+	PivnetProductSlug         string `yaml:"pivnet-product-slug"`
+	PivnetFileGlob            string `yaml:"pivnet-file-glob"`
+	ProductVersion            string `yaml:"product-version"`
+	ProductVersionRegex       string `yaml:"product-version-regex"`
+	ProductVersionConstraint  string `yaml:"product-version-constraint"`
+	ExcludePrereleases        bool   `yaml:"exclude-prereleases"`
+	StemcellIaas              string `yaml:"stemcell-iaas"`
+	StemcellVersionConstraint string `yaml:"stemcell-version-constraint"`
+}
+
+// downloadProductOptions sets the options of the product on the options
+// that are shared by every product.
+func (p downloadProductsManifestProduct) downloadProductOptions(options DownloadProductOptions) DownloadProductOptions {
+	options.PivnetProductSlug = p.PivnetProductSlug
+	options.PivnetFileGlob = p.PivnetFileGlob
+	options.ProductVersion = p.ProductVersion
+	options.ProductVersionRegex = p.ProductVersionRegex
+	options.ProductVersionConstraint = p.ProductVersionConstraint
+	options.ExcludePrereleases = p.ExcludePrereleases
+	options.StemcellIaas = p.StemcellIaas
+	options.StemcellVersionConstraint = p.StemcellVersionConstraint
+	return options
+}
+
+// downloadProductsResult is one entry of download-products.json. It has the
+// keys of download-file.json, and the error when the download failed.
+type downloadProductsResult struct {
+	downloadedProduct
+	Error string `json:"error,omitempty"`
+}
+
+func NewDownloadProducts(
+	environFunc func() []string,
+	stdout *log.Logger,
+	stderr *log.Logger,
+	progressWriter io.Writer,
+) *DownloadProducts {
+	return &DownloadProducts{
+		environFunc:    environFunc,
+		stderr:         stderr,
+		stdout:         stdout,
+		progressWriter: progressWriter,
+	}
+}
+
+func (c DownloadProducts) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command downloads each of the products listed in a manifest, several at once, with one client for the source. It writes download-products.json to the output directory, which lists what was downloaded for each product in the format of download-product's download-file.json",
+		ShortDescription: "**EXPERIMENTAL** downloads the product files listed in a manifest",
+		Flags:            c.Options,
+	}
+}
+
+func (c *DownloadProducts) Execute(args []string) error {
+	err := loadConfigFile(args, &c.Options, c.environFunc)
+	if err != nil {
+		return fmt.Errorf("could not parse download-products flags: %s", err)
+	}
+
+	if c.Options.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	if c.Options.ParallelConnections < 0 {
+		return fmt.Errorf("--parallel-connections must be at least 1")
+	}
+
+	if c.Options.PivnetToken == "" && c.Options.Source == "pivnet" {
+		return fmt.Errorf(`could not parse download-products flags: missing required flag "--pivnet-api-token"`)
+	}
+
+	manifest, err := c.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	plugin, ok := plugins[c.Options.Source]
+	if !ok {
+		return fmt.Errorf("could not find valid source for '%s'", c.Options.Source)
+	}
+
+	// progress bars of downloads running at once would write over each other
+	progressWriter := c.progressWriter
+	if c.Options.Concurrency > 1 {
+		progressWriter = ioutil.Discard
+	}
+
+	downloadClient, err := plugin(c.downloadProductOptions(), progressWriter, c.stdout, c.stderr)
+	if err != nil {
+		return err
+	}
+
+	results := make([]downloadProductsResult, len(manifest.Products))
+	locks := newDownloadLocks()
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < c.Options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				product := manifest.Products[index]
+
+				options := product.downloadProductOptions(c.downloadProductOptions())
+
+				download := &DownloadProduct{
+					environFunc:    c.environFunc,
+					progressWriter: progressWriter,
+					stderr:         log.New(c.stderr.Writer(), fmt.Sprintf("[%s] ", product.PivnetProductSlug), c.stderr.Flags()),
+					stdout:         c.stdout,
+					downloadClient: downloadClient,
+					downloadLocks:  locks,
+					Options:        options,
+				}
+
+				downloaded, err := download.download()
+				if err != nil {
+					results[index].Error = err.Error()
+					downloaded.ProductSlug = product.PivnetProductSlug
+				}
+				results[index].downloadedProduct = downloaded
+			}
+		}()
+	}
+
+	for index := range manifest.Products {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	err = c.writeDownloadProductsOutput(results)
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	table := tablewriter.NewWriter(&output)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"Product", "Version", "File", "Stemcell", "Error"})
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+		table.Append([]string{
+			result.ProductSlug,
+			result.ProductVersion,
+			filepath.Base(result.ProductPath),
+			filepath.Base(result.StemcellPath),
+			result.Error,
+		})
+	}
+	table.Render()
+
+	c.stdout.Print(output.String())
+
+	if failed > 0 {
+		return fmt.Errorf("failed to download %d of %d products", failed, len(results))
+	}
+
+	return nil
+}
+
+// downloadLocks makes downloads to the same path, such as of a stemcell
+// that several products require, happen one at a time. The downloads after
+// the first find the file already there, instead of writing to the same
+// .partial file at once.
+type downloadLocks struct {
+	mutex sync.Mutex
+	paths map[string]*sync.Mutex
+}
+
+func newDownloadLocks() *downloadLocks {
+	return &downloadLocks{paths: map[string]*sync.Mutex{}}
+}
+
+// lock waits until no other download is writing to path, and returns the
+// function that lets the next one.
+func (l *downloadLocks) lock(path string) func() {
+	l.mutex.Lock()
+	pathLock, ok := l.paths[path]
+	if !ok {
+		pathLock = &sync.Mutex{}
+		l.paths[path] = pathLock
+	}
+	l.mutex.Unlock()
+
+	pathLock.Lock()
+	return pathLock.Unlock
+}
+
+func (c DownloadProducts) loadManifest() (downloadProductsManifest, error) {
+	return loadDownloadProductsManifest(c.Options.Manifest, interpolate.Options{
+		VarsFiles:   c.Options.VarsFile,
+		Vars:        c.Options.Vars,
+		EnvironFunc: c.environFunc,
+		VarsEnvs:    c.Options.VarsEnv,
+	})
+}
+
+// loadDownloadProductsManifest interpolates and validates a manifest in the
+// format of download-products, with the vars in options.
+func loadDownloadProductsManifest(manifestFile string, options interpolate.Options) (downloadProductsManifest, error) {
+	options.TemplateFile = manifestFile
+	options.ExpectAllKeys = true
+	contents, err := interpolate.Execute(options)
+	if err != nil {
+		return downloadProductsManifest{}, fmt.Errorf("could not load the manifest: %s", err)
+	}
+
+	var manifest downloadProductsManifest
+	err = yaml.UnmarshalStrict(contents, &manifest)
+	if err != nil {
+		return downloadProductsManifest{}, fmt.Errorf("could not be parsed as valid manifest: %s: %s", manifestFile, err)
+	}
+
+	if len(manifest.Products) == 0 {
+		return downloadProductsManifest{}, fmt.Errorf("manifest %s: no products to download", manifestFile)
+	}
+
+	for index, product := range manifest.Products {
+		versionKeys := 0
+		for _, value := range []string{product.ProductVersion, product.ProductVersionRegex, product.ProductVersionConstraint} {
+			if value != "" {
+				versionKeys++
+			}
+		}
+
+		switch {
+		case product.PivnetProductSlug == "":
+			return downloadProductsManifest{}, fmt.Errorf("manifest %s: \"pivnet-product-slug\" is required for products[%d]", manifestFile, index)
+		case product.PivnetFileGlob == "":
+			return downloadProductsManifest{}, fmt.Errorf("manifest %s: \"pivnet-file-glob\" is required for products[%d]", manifestFile, index)
+		case product.ProductVersion != "" && product.ProductVersionRegex != "":
+			return downloadProductsManifest{}, fmt.Errorf("manifest %s: \"product-version\" and \"product-version-regex\" cannot both be set for products[%d]", manifestFile, index)
+		case versionKeys > 1:
+			return downloadProductsManifest{}, fmt.Errorf("manifest %s: only one of \"product-version\", \"product-version-regex\" and \"product-version-constraint\" can be set for products[%d]", manifestFile, index)
+		case versionKeys == 0:
+			return downloadProductsManifest{}, fmt.Errorf("manifest %s: \"product-version\", \"product-version-regex\" or \"product-version-constraint\" is required for products[%d]", manifestFile, index)
+		}
+	}
+
+	return manifest, nil
+}
+
+// downloadProductOptions gives the client the same options that it would
+// have for download-product, without those of any one product.
+func (c DownloadProducts) downloadProductOptions() DownloadProductOptions {
+	return DownloadProductOptions{
+		Source:              c.Options.Source,
+		BlobstoreFlags:      c.Options.BlobstoreFlags,
+		OutputDir:           c.Options.OutputDir,
+		PivnetDisableSSL:    c.Options.PivnetDisableSSL,
+		PivnetToken:         c.Options.PivnetToken,
+		ProductPath:         c.Options.ProductPath,
+		StemcellPath:        c.Options.StemcellPath,
+		FileDirectory:       c.Options.FileDirectory,
+		HTTPURL:             c.Options.HTTPURL,
+		HTTPIndexFile:       c.Options.HTTPIndexFile,
+		HTTPDisableSSL:      c.Options.HTTPDisableSSL,
+		ParallelConnections: c.Options.ParallelConnections,
+		CacheDir:            c.Options.CacheDir,
+	}
+}
+
+func (c DownloadProducts) writeDownloadProductsOutput(results []downloadProductsResult) error {
+	c.stderr.Printf("Writing a list of downloaded artifacts to %s", downloadProductsFilename)
+
+	outputFile, err := os.Create(filepath.Join(c.Options.OutputDir, downloadProductsFilename))
+	if err != nil {
+		return fmt.Errorf("could not create %s: %s", downloadProductsFilename, err)
+	}
+	defer outputFile.Close()
+
+	err = json.NewEncoder(outputFile).Encode(results)
+	if err != nil {
+		return fmt.Errorf("could not encode JSON for %s: %s", downloadProductsFilename, err)
+	}
+
+	return nil
+}
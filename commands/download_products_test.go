@@ -0,0 +1,307 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("DownloadProducts", func() {
+	var (
+		command               *commands.DownloadProducts
+		fakeProductDownloader *fakes.ProductDownloader
+		buffer                *gbytes.Buffer
+		tempDir               string
+		manifestFile          string
+		clientsCreated        int
+		clientProgressWriter  io.Writer
+	)
+
+	writeManifest := func(contents string) {
+		err := ioutil.WriteFile(manifestFile, []byte(contents), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	readReport := func() []map[string]string {
+		contents, err := ioutil.ReadFile(filepath.Join(tempDir, "download-products.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var report []map[string]string
+		Expect(json.Unmarshal(contents, &report)).To(Succeed())
+		return report
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "om-tests-")
+		Expect(err).ToNot(HaveOccurred())
+		manifestFile = filepath.Join(tempDir, "products.yml")
+
+		clientsCreated = 0
+		fakeProductDownloader = &fakes.ProductDownloader{}
+		fakeProductDownloader.NameReturns("pivnet")
+		fakeProductDownloader.GetLatestProductFileStub = func(slug, version, glob string) (commands.FileArtifacter, error) {
+			fa := &fakes.FileArtifacter{}
+			fa.NameReturns("/some-account/some-bucket/" + slug + "-" + version + ".pivotal")
+			return fa, nil
+		}
+
+		commands.RegisterProductClient("pivnet", func(c commands.DownloadProductOptions, progressWriter io.Writer, stdout *log.Logger, stderr *log.Logger) (commands.ProductDownloader, error) {
+			clientsCreated++
+			clientProgressWriter = progressWriter
+			return fakeProductDownloader, nil
+		})
+
+		buffer = gbytes.NewBuffer()
+		command = commands.NewDownloadProducts(
+			func() []string { return nil },
+			log.New(buffer, "", 0),
+			log.New(buffer, "", 0),
+			buffer,
+		)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	It("downloads every product in the manifest with one client", func() {
+		writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  product-version: 2.7.4
+  pivnet-file-glob: "*.pivotal"
+- pivnet-product-slug: p-mysql
+  product-version-regex: ^2\.
+  pivnet-file-glob: "*.pivotal"
+`)
+		fakeProductDownloader.GetAllProductVersionsReturns([]string{"1.9.0", "2.1.0", "2.0.3"}, nil)
+
+		err := command.Execute([]string{
+			"--manifest", manifestFile,
+			"--pivnet-api-token", "token",
+			"--output-directory", tempDir,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(clientsCreated).To(Equal(1))
+		Expect(clientProgressWriter).To(Equal(ioutil.Discard))
+		Expect(fakeProductDownloader.GetAllProductVersionsCallCount()).To(Equal(1))
+		Expect(fakeProductDownloader.GetAllProductVersionsArgsForCall(0)).To(Equal("p-mysql"))
+		Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(2))
+
+		Expect(filepath.Join(tempDir, "cf-2.7.4.pivotal")).To(BeAnExistingFile())
+		Expect(filepath.Join(tempDir, "p-mysql-2.1.0.pivotal")).To(BeAnExistingFile())
+
+		Expect(readReport()).To(Equal([]map[string]string{
+			{
+				"product_path":    filepath.Join(tempDir, "cf-2.7.4.pivotal"),
+				"product_slug":    "cf",
+				"product_version": "2.7.4",
+			},
+			{
+				"product_path":    filepath.Join(tempDir, "p-mysql-2.1.0.pivotal"),
+				"product_slug":    "p-mysql",
+				"product_version": "2.1.0",
+			},
+		}))
+	})
+
+	It("downloads a stemcell that several products require once", func() {
+		writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  product-version: 2.7.4
+  pivnet-file-glob: "*.pivotal"
+  stemcell-iaas: google
+- pivnet-product-slug: p-mysql
+  product-version: 2.7.1
+  pivnet-file-glob: "*.pivotal"
+  stemcell-iaas: google
+`)
+		stemcell := &fakes.StemcellArtifacter{}
+		stemcell.SlugReturns("stemcells-ubuntu-xenial")
+		stemcell.VersionReturns("456.30")
+		fakeProductDownloader.GetLatestStemcellForProductReturns(stemcell, nil)
+
+		var (
+			mutex             sync.Mutex
+			stemcellDownloads int
+		)
+		fakeProductDownloader.DownloadProductToFileStub = func(fa commands.FileArtifacter, file *os.File) error {
+			if strings.Contains(fa.Name(), "stemcells-ubuntu-xenial") {
+				mutex.Lock()
+				stemcellDownloads++
+				mutex.Unlock()
+
+				// long enough for the other product to want the stemcell too
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			_, err := file.WriteString("contents of " + fa.Name())
+			return err
+		}
+
+		err := command.Execute([]string{
+			"--manifest", manifestFile,
+			"--pivnet-api-token", "token",
+			"--output-directory", tempDir,
+			"--concurrency", "2",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stemcellDownloads).To(Equal(1))
+
+		stemcellPath := filepath.Join(tempDir, "stemcells-ubuntu-xenial-456.30.pivotal")
+		Expect(ioutil.ReadFile(stemcellPath)).To(Equal([]byte("contents of /some-account/some-bucket/stemcells-ubuntu-xenial-456.30.pivotal")))
+		Expect(stemcellPath + ".partial").ToNot(BeAnExistingFile())
+
+		report := readReport()
+		Expect(report[0]["stemcell_path"]).To(Equal(stemcellPath))
+		Expect(report[1]["stemcell_path"]).To(Equal(stemcellPath))
+	})
+
+	It("shows the progress of the download when downloading one product at a time", func() {
+		writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  product-version: 2.7.4
+  pivnet-file-glob: "*.pivotal"
+`)
+
+		err := command.Execute([]string{
+			"--manifest", manifestFile,
+			"--pivnet-api-token", "token",
+			"--output-directory", tempDir,
+			"--concurrency", "1",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(clientProgressWriter).To(Equal(buffer))
+	})
+
+	It("interpolates the manifest with vars", func() {
+		writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  product-version: ((cf_version))
+  pivnet-file-glob: "*.pivotal"
+`)
+
+		err := command.Execute([]string{
+			"--manifest", manifestFile,
+			"--pivnet-api-token", "token",
+			"--output-directory", tempDir,
+			"--var", "cf_version=2.7.4",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, version, _ := fakeProductDownloader.GetLatestProductFileArgsForCall(0)
+		Expect(version).To(Equal("2.7.4"))
+	})
+
+	When("a product fails to download", func() {
+		It("downloads the others and reports the failure", func() {
+			writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  product-version: 2.7.4
+  pivnet-file-glob: "*.pivotal"
+- pivnet-product-slug: p-mysql
+  product-version: 2.1.0
+  pivnet-file-glob: "*.pivotal"
+`)
+			fakeProductDownloader.GetLatestProductFileStub = func(slug, version, glob string) (commands.FileArtifacter, error) {
+				if slug == "cf" {
+					return nil, errors.New("no such release")
+				}
+
+				fa := &fakes.FileArtifacter{}
+				fa.NameReturns("/some-account/some-bucket/" + slug + "-" + version + ".pivotal")
+				return fa, nil
+			}
+
+			err := command.Execute([]string{
+				"--manifest", manifestFile,
+				"--pivnet-api-token", "token",
+				"--output-directory", tempDir,
+			})
+			Expect(err).To(MatchError("failed to download 1 of 2 products"))
+
+			Expect(filepath.Join(tempDir, "p-mysql-2.1.0.pivotal")).To(BeAnExistingFile())
+
+			Expect(readReport()).To(Equal([]map[string]string{
+				{
+					"product_slug": "cf",
+					"error":        "could not download product: no such release",
+				},
+				{
+					"product_path":    filepath.Join(tempDir, "p-mysql-2.1.0.pivotal"),
+					"product_slug":    "p-mysql",
+					"product_version": "2.1.0",
+				},
+			}))
+			Expect(buffer).To(gbytes.Say("could not download product: no such release"))
+		})
+	})
+
+	When("the manifest is invalid", func() {
+		DescribeTable("returns an error without downloading",
+			func(manifest, message string) {
+				writeManifest(manifest)
+
+				err := command.Execute([]string{
+					"--manifest", manifestFile,
+					"--pivnet-api-token", "token",
+					"--output-directory", tempDir,
+				})
+				Expect(err).To(MatchError(ContainSubstring(message)))
+
+				Expect(clientsCreated).To(Equal(0))
+			},
+			Entry("without products", "products: []", "no products to download"),
+			Entry("with an unknown key", "products: [{pivnet-product-slug: cf, product-version: 1.0.0, pivnet-file-glob: '*', stemcell: xenial}]", "could not be parsed as valid manifest"),
+			Entry("without a slug", "products: [{product-version: 1.0.0, pivnet-file-glob: '*'}]", `"pivnet-product-slug" is required for products[0]`),
+			Entry("without a glob", "products: [{pivnet-product-slug: cf, product-version: 1.0.0}]", `"pivnet-file-glob" is required for products[0]`),
+			Entry("without a version", "products: [{pivnet-product-slug: cf, pivnet-file-glob: '*'}]", `"product-version", "product-version-regex" or "product-version-constraint" is required for products[0]`),
+			Entry("with a version and a constraint", "products: [{pivnet-product-slug: cf, pivnet-file-glob: '*', product-version: 1.0.0, product-version-constraint: ~> 1.0}]", `only one of "product-version", "product-version-regex" and "product-version-constraint" can be set for products[0]`),
+			Entry("with both versions", "products: [{pivnet-product-slug: cf, pivnet-file-glob: '*', product-version: 1.0.0, product-version-regex: '.*'}]", `"product-version" and "product-version-regex" cannot both be set for products[0]`),
+		)
+	})
+
+	When("the concurrency is less than one", func() {
+		It("returns an error", func() {
+			err := command.Execute([]string{
+				"--manifest", manifestFile,
+				"--pivnet-api-token", "token",
+				"--output-directory", tempDir,
+				"--concurrency", "0",
+			})
+			Expect(err).To(MatchError("--concurrency must be at least 1"))
+		})
+	})
+
+	When("the pivnet token is missing", func() {
+		It("returns an error", func() {
+			err := command.Execute([]string{
+				"--manifest", manifestFile,
+				"--output-directory", tempDir,
+			})
+			Expect(err).To(MatchError(ContainSubstring(`missing required flag "--pivnet-api-token"`)))
+		})
+	})
+})
@@ -2,6 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/pivotal-cf/jhanda"
 )
@@ -10,13 +16,19 @@ type ExportInstallation struct {
 	logger  logger
 	service exportInstallationService
 	Options struct {
-		OutputFile string `long:"output-file"      short:"o"  required:"true" description:"output path to write installation to"`
+		ConfigFile string `long:"config"           short:"c"                  description:"path to yml file for configuration (keys must match the following command line flags)"`
+		OutputFile string `long:"output-file"      short:"o"  required:"true" description:"output path to write installation to. With --blobstore, the name of the file in the bucket"`
+		KeepLast   int    `long:"keep-last"                                   description:"after exporting, delete all but this many of the most recent exports in the same directory as --output-file (files named like it, with the same name before a number or timestamp, and the same extension)"`
+
+		Blobstore string `long:"blobstore" description:"stream the installation to a blobstore instead of a local file, along with a .sha256 checksum file (options: s3,gcs,azure)"`
+		BlobstoreFlags
 	}
 }
 
 //counterfeiter:generate -o ./fakes/export_installation_service.go --fake-name ExportInstallationService . exportInstallationService
 type exportInstallationService interface {
 	DownloadInstallationAssetCollection(outputFile string) error
+	OpenInstallationAssetCollection() (io.ReadCloser, int64, error)
 }
 
 func NewExportInstallation(service exportInstallationService, logger logger) ExportInstallation {
@@ -35,10 +47,18 @@ func (ei ExportInstallation) Usage() jhanda.Usage {
 }
 
 func (ei ExportInstallation) Execute(args []string) error {
-	if _, err := jhanda.Parse(&ei.Options, args); err != nil {
+	if err := loadConfigFile(args, &ei.Options, nil); err != nil {
 		return fmt.Errorf("could not parse export-installation flags: %s", err)
 	}
 
+	if ei.Options.KeepLast < 0 {
+		return fmt.Errorf("--keep-last cannot be negative")
+	}
+
+	if ei.Options.Blobstore != "" {
+		return ei.exportToBlobstore()
+	}
+
 	ei.logger.Printf("exporting installation")
 
 	err := ei.service.DownloadInstallationAssetCollection(ei.Options.OutputFile)
@@ -48,5 +68,114 @@ func (ei ExportInstallation) Execute(args []string) error {
 
 	ei.logger.Printf("finished exporting installation")
 
+	if ei.Options.KeepLast > 0 {
+		return ei.pruneLocalExports()
+	}
+
+	return nil
+}
+
+// exportToBlobstore streams the installation from Ops Manager straight into
+// the bucket, computing its checksum on the way, so it is never on local disk.
+func (ei ExportInstallation) exportToBlobstore() error {
+	store, err := newBlobstore(ei.Options.Blobstore, ei.Options.blobstoreOptions())
+	if err != nil {
+		return err
+	}
+
+	ei.logger.Printf("exporting installation to %s in %s", ei.Options.OutputFile, store.Name())
+
+	installation, length, err := ei.service.OpenInstallationAssetCollection()
+	if err != nil {
+		return fmt.Errorf("failed to export installation: %s", err)
+	}
+	defer installation.Close()
+
+	reader := newChecksumReader(installation, "")
+	err = store.Put(ei.Options.OutputFile, reader, length)
+	if err != nil {
+		return fmt.Errorf("failed to export installation: %s", err)
+	}
+
+	if reader.read != length {
+		_ = store.Delete(ei.Options.OutputFile)
+		return fmt.Errorf("failed to export installation: invalid response length (expected %d, got %d)", length, reader.read)
+	}
+
+	checksum := formatChecksum(reader.Sum(), ei.Options.OutputFile)
+	err = store.Put(checksumFileName(ei.Options.OutputFile), strings.NewReader(checksum), int64(len(checksum)))
+	if err != nil {
+		return fmt.Errorf("failed to write checksum of installation: %s", err)
+	}
+
+	ei.logger.Printf("finished exporting installation (sha256: %s)", reader.Sum())
+
+	if ei.Options.KeepLast == 0 {
+		return nil
+	}
+
+	prefix := path.Dir(ei.Options.OutputFile) + "/"
+	if prefix == "./" {
+		prefix = ""
+	}
+
+	files, err := store.List(prefix)
+	if err != nil {
+		return fmt.Errorf("could not list previous exports: %s", err)
+	}
+
+	return ei.pruneExports(files, ei.Options.OutputFile, store.Delete)
+}
+
+func (ei ExportInstallation) pruneLocalExports() error {
+	dir := filepath.Dir(ei.Options.OutputFile)
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("could not list previous exports: %s", err)
+	}
+
+	var files []BlobstoreFile
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		files = append(files, BlobstoreFile{
+			Name:         path.Join(filepath.ToSlash(dir), info.Name()),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	}
+
+	return ei.pruneExports(files, filepath.ToSlash(ei.Options.OutputFile), func(name string) error {
+		return os.Remove(filepath.FromSlash(name))
+	})
+}
+
+// pruneExports deletes all but the most recent exports, along with their
+// checksum files.
+func (ei ExportInstallation) pruneExports(files []BlobstoreFile, name string, remove func(name string) error) error {
+	existing := map[string]bool{}
+	for _, file := range files {
+		existing[file.Name] = true
+	}
+
+	for _, export := range exportsToPrune(files, path.Clean(name), ei.Options.KeepLast) {
+		ei.logger.Printf("deleting %s, keeping the last %d exports", export.Name, ei.Options.KeepLast)
+
+		err := remove(export.Name)
+		if err != nil {
+			return fmt.Errorf("could not delete previous export %s: %s", export.Name, err)
+		}
+
+		if existing[checksumFileName(export.Name)] {
+			err = remove(checksumFileName(export.Name))
+			if err != nil {
+				return fmt.Errorf("could not delete previous export %s: %s", checksumFileName(export.Name), err)
+			}
+		}
+	}
+
 	return nil
 }
@@ -3,6 +3,12 @@ package commands_test
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/commands"
@@ -45,6 +51,206 @@ var _ = Describe("ExportInstallation", func() {
 		Expect(fmt.Sprintf(format, v...)).To(Equal("finished exporting installation"))
 	})
 
+	Context("with --keep-last", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			for i, name := range []string{"photos.zip", "installation-backup.zip", "installation-1.zip", "installation-2.zip", "installation-3.zip", "installation-1.zip.sha256", "notes.txt"} {
+				file := filepath.Join(dir, name)
+				Expect(ioutil.WriteFile(file, []byte("old"), 0600)).To(Succeed())
+				modified := time.Now().Add(-time.Duration(10-i) * time.Hour)
+				Expect(os.Chtimes(file, modified, modified)).To(Succeed())
+			}
+
+			fakeService.DownloadInstallationAssetCollectionStub = func(outputFile string) error {
+				return ioutil.WriteFile(outputFile, []byte("new"), 0600)
+			}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("deletes all but the most recent exports in the same directory, leaving other files alone", func() {
+			command := commands.NewExportInstallation(fakeService, logger)
+
+			err := command.Execute([]string{
+				"--output-file", filepath.Join(dir, "installation-4.zip"),
+				"--keep-last", "2",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(dir, "*"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(ConsistOf(
+				filepath.Join(dir, "photos.zip"),
+				filepath.Join(dir, "installation-backup.zip"),
+				filepath.Join(dir, "installation-3.zip"),
+				filepath.Join(dir, "installation-4.zip"),
+				filepath.Join(dir, "notes.txt"),
+			))
+		})
+	})
+
+	Context("with --blobstore", func() {
+		var (
+			blobstore *fakes.Blobstore
+			options   commands.BlobstoreOptions
+			uploaded  map[string]string
+		)
+
+		BeforeEach(func() {
+			uploaded = map[string]string{}
+			blobstore = &fakes.Blobstore{}
+			blobstore.NameReturns("s3")
+			blobstore.PutStub = func(name string, r io.Reader, size int64) error {
+				contents, err := ioutil.ReadAll(r)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(int64(len(contents))).To(Equal(size))
+				uploaded[name] = string(contents)
+				return nil
+			}
+
+			commands.RegisterBlobstore("s3", func(o commands.BlobstoreOptions) (commands.Blobstore, error) {
+				options = o
+				return blobstore, nil
+			})
+
+			fakeService.OpenInstallationAssetCollectionReturns(ioutil.NopCloser(strings.NewReader("some-installation")), 17, nil)
+		})
+
+		It("streams the installation to the bucket with a checksum file", func() {
+			command := commands.NewExportInstallation(fakeService, logger)
+
+			err := command.Execute([]string{
+				"--output-file", "backups/installation.zip",
+				"--blobstore", "s3",
+				"--blobstore-bucket", "some-bucket",
+				"--s3-region-name", "some-region",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.DownloadInstallationAssetCollectionCallCount()).To(Equal(0))
+			Expect(options.Bucket).To(Equal("some-bucket"))
+			Expect(options.S3RegionName).To(Equal("some-region"))
+			Expect(options.S3AuthType).To(Equal("accesskey"))
+
+			Expect(uploaded).To(Equal(map[string]string{
+				"backups/installation.zip":        "some-installation",
+				"backups/installation.zip.sha256": "0812c129f0c6b7946c94068b332c0f12e209f17ce60d384050fe8a3f139a28e1  installation.zip\n",
+			}))
+			Expect(blobstore.ListCallCount()).To(Equal(0))
+		})
+
+		It("reads the blobstore flags from a config file", func() {
+			configFile, err := ioutil.TempFile("", "config-*.yml")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(configFile.Name())
+			_, err = configFile.WriteString("blobstore: s3\nblobstore-bucket: some-bucket\ns3-region-name: some-region\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configFile.Close()).To(Succeed())
+
+			command := commands.NewExportInstallation(fakeService, logger)
+
+			err = command.Execute([]string{
+				"--config", configFile.Name(),
+				"--output-file", "backups/installation.zip",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(options.Bucket).To(Equal("some-bucket"))
+			Expect(options.S3RegionName).To(Equal("some-region"))
+			Expect(uploaded).To(HaveKey("backups/installation.zip"))
+		})
+
+		It("deletes all but the most recent exports and their checksum files with --keep-last, leaving other files alone", func() {
+			now := time.Now()
+			blobstore.ListReturns([]commands.BlobstoreFile{
+				{Name: "backups/other.zip", LastModified: now.Add(-5 * time.Hour)},
+				{Name: "backups/installation-1.zip", LastModified: now.Add(-3 * time.Hour)},
+				{Name: "backups/installation-1.zip.sha256", LastModified: now.Add(-3 * time.Hour)},
+				{Name: "backups/installation-2.zip", LastModified: now.Add(-2 * time.Hour)},
+				{Name: "backups/installation-3.zip", LastModified: now},
+				{Name: "backups/installation-3.zip.sha256", LastModified: now},
+				{Name: "backups/nested/installation-0.zip", LastModified: now.Add(-4 * time.Hour)},
+			}, nil)
+
+			command := commands.NewExportInstallation(fakeService, logger)
+
+			err := command.Execute([]string{
+				"--output-file", "backups/installation-3.zip",
+				"--blobstore", "s3",
+				"--blobstore-bucket", "some-bucket",
+				"--keep-last", "1",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(blobstore.ListArgsForCall(0)).To(Equal("backups/"))
+
+			var deleted []string
+			for i := 0; i < blobstore.DeleteCallCount(); i++ {
+				deleted = append(deleted, blobstore.DeleteArgsForCall(i))
+			}
+			Expect(deleted).To(Equal([]string{
+				"backups/installation-2.zip",
+				"backups/installation-1.zip",
+				"backups/installation-1.zip.sha256",
+			}))
+		})
+
+		When("the installation is shorter than Ops Manager said it would be", func() {
+			It("deletes it and returns an error", func() {
+				blobstore.PutReturns(nil)
+				blobstore.PutStub = nil
+				fakeService.OpenInstallationAssetCollectionReturns(ioutil.NopCloser(strings.NewReader("")), 17, nil)
+
+				command := commands.NewExportInstallation(fakeService, logger)
+
+				err := command.Execute([]string{
+					"--output-file", "installation.zip",
+					"--blobstore", "s3",
+					"--blobstore-bucket", "some-bucket",
+				})
+				Expect(err).To(MatchError("failed to export installation: invalid response length (expected 17, got 0)"))
+				Expect(blobstore.DeleteArgsForCall(0)).To(Equal("installation.zip"))
+			})
+		})
+
+		When("the bucket is not set", func() {
+			It("returns an error", func() {
+				command := commands.NewExportInstallation(fakeService, logger)
+
+				err := command.Execute([]string{"--output-file", "installation.zip", "--blobstore", "s3"})
+				Expect(err).To(MatchError("--blobstore-bucket is required when using the s3 blobstore"))
+			})
+		})
+
+		When("the blobstore is not supported", func() {
+			It("returns an error", func() {
+				command := commands.NewExportInstallation(fakeService, logger)
+
+				err := command.Execute([]string{"--output-file", "installation.zip", "--blobstore", "ftp"})
+				Expect(err).To(MatchError(ContainSubstring(`unsupported blobstore "ftp": must be one of `)))
+			})
+		})
+
+		When("the installation cannot be written to the bucket", func() {
+			It("returns an error", func() {
+				blobstore.PutStub = nil
+				blobstore.PutReturns(errors.New("some error"))
+
+				command := commands.NewExportInstallation(fakeService, logger)
+
+				err := command.Execute([]string{"--output-file", "installation.zip", "--blobstore", "s3", "--blobstore-bucket", "some-bucket"})
+				Expect(err).To(MatchError("failed to export installation: some error"))
+			})
+		})
+	})
+
 	Context("failure cases", func() {
 		When("an unknown flag is provided", func() {
 			It("returns an error", func() {
@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/configparser"
+	"gopkg.in/yaml.v2"
+)
+
+const directorConfigFilename = "director.yml"
+const varsDir = "vars"
+
+type ExportStagedConfigs struct {
+	service exportStagedConfigsService
+	logger  logger
+	Options struct {
+		OutputDir           string `long:"output-dir"           short:"o" required:"true" description:"directory to write director.yml and a <product-name>.yml for each staged product to"`
+		IncludeCredentials  bool   `long:"include-credentials"  short:"c"                 description:"include credentials. note: requires products to have been deployed"`
+		IncludePlaceholders bool   `long:"include-placeholders" short:"r"                 description:"replace obscured credentials with interpolatable placeholders. with --include-credentials, the credentials are written to vars/<product-name>.yml"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/export_staged_configs_service.go --fake-name ExportStagedConfigsService . exportStagedConfigsService
+type exportStagedConfigsService interface {
+	GetDeployedProductCredential(input api.GetDeployedProductCredentialInput) (api.GetDeployedProductCredentialOutput, error)
+	GetStagedDirectorAvailabilityZones() (api.AvailabilityZonesOutput, error)
+	GetStagedDirectorIaasConfigurations(redact bool) (map[string][]map[string]interface{}, error)
+	GetStagedDirectorNetworks() (api.NetworksConfigurationOutput, error)
+	GetStagedDirectorProperties(redact bool) (map[string]interface{}, error)
+	GetStagedProductByName(product string) (api.StagedProductsFindOutput, error)
+	GetStagedProductJobMaxInFlight(productGUID string) (map[string]interface{}, error)
+	GetStagedProductJobResourceConfig(productGUID, jobGUID string) (api.JobProperties, error)
+	GetStagedProductNetworksAndAZs(product string) (map[string]interface{}, error)
+	GetStagedProductProperties(product string) (map[string]api.ResponseProperty, error)
+	GetStagedProductSyslogConfiguration(product string) (map[string]interface{}, error)
+	Info() (api.Info, error)
+	ListDeployedProducts() ([]api.DeployedProductOutput, error)
+	ListStagedProductErrands(productID string) (api.ErrandsListOutput, error)
+	ListStagedProductJobs(productGUID string) (map[string]string, error)
+	ListStagedProducts() (api.StagedProductsOutput, error)
+	ListStagedVMExtensions() ([]api.VMExtension, error)
+	ListVMTypes() ([]api.VMType, error)
+}
+
+func NewExportStagedConfigs(service exportStagedConfigsService, logger logger) ExportStagedConfigs {
+	return ExportStagedConfigs{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (esc ExportStagedConfigs) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command writes the config of the staged director and of every staged product to a directory, in the formats accepted by om configure-director and om configure-product",
+		ShortDescription: "**EXPERIMENTAL** generates configs for the director and all staged products",
+		Flags:            esc.Options,
+	}
+}
+
+func (esc ExportStagedConfigs) Execute(args []string) error {
+	if _, err := jhanda.Parse(&esc.Options, args); err != nil {
+		return fmt.Errorf("could not parse export-staged-configs flags: %s", err)
+	}
+
+	err := os.MkdirAll(esc.Options.OutputDir, 0755)
+	if err != nil {
+		return fmt.Errorf("could not create output directory: %s", err)
+	}
+
+	info, err := esc.service.Info()
+	if err != nil {
+		return err
+	}
+
+	stagedProducts, err := esc.service.ListStagedProducts()
+	if err != nil {
+		return err
+	}
+
+	deployedProducts := map[string]bool{}
+	if esc.Options.IncludeCredentials {
+		deployed, err := esc.service.ListDeployedProducts()
+		if err != nil {
+			return err
+		}
+		for _, product := range deployed {
+			deployedProducts[product.Type] = true
+		}
+	}
+
+	writeVars := esc.Options.IncludeCredentials && esc.Options.IncludePlaceholders
+	if writeVars {
+		err = os.MkdirAll(filepath.Join(esc.Options.OutputDir, varsDir), 0755)
+		if err != nil {
+			return fmt.Errorf("could not create vars directory: %s", err)
+		}
+	}
+
+	for _, product := range stagedProducts.Products {
+		if product.Type == "p-bosh" {
+			continue
+		}
+
+		if esc.Options.IncludeCredentials && !deployedProducts[product.Type] {
+			return fmt.Errorf("cannot retrieve credentials for product '%s': deploy the product and retry", product.Type)
+		}
+
+		productVars := map[string]interface{}{}
+		productConfig, err := getStagedProductConfig(esc.service, info, product.Type, func(productGUID string) configparser.CredentialHandler {
+			switch {
+			case writeVars:
+				return configparser.NewPlaceholderVarsHandler(productGUID, esc.service, productVars)
+			case esc.Options.IncludePlaceholders:
+				return configparser.NewPlaceholderHandler()
+			case esc.Options.IncludeCredentials:
+				return configparser.NewGetCredentialHandler(productGUID, esc.service)
+			}
+			return configparser.NewNilHandler()
+		})
+		if err != nil {
+			return fmt.Errorf("could not get staged config for product '%s': %s", product.Type, err)
+		}
+
+		filename := product.Type + ".yml"
+		err = esc.writeConfig(filename, productConfig)
+		if err != nil {
+			return err
+		}
+
+		if writeVars {
+			err = esc.writeConfig(filepath.Join(varsDir, filename), productVars)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	directorConfig, err := esc.stagedDirectorConfig(esc.Options.IncludePlaceholders, esc.Options.IncludeCredentials)
+	if err != nil {
+		return err
+	}
+
+	err = esc.writeConfig(directorConfigFilename, directorConfig)
+	if err != nil {
+		return err
+	}
+
+	if !writeVars {
+		return nil
+	}
+
+	directorValues, err := esc.stagedDirectorConfig(false, true)
+	if err != nil {
+		return err
+	}
+
+	directorVars := map[string]interface{}{}
+	collectPlaceholderValues(directorConfig, directorValues, directorVars)
+
+	return esc.writeConfig(filepath.Join(varsDir, directorConfigFilename), directorVars)
+}
+
+func (esc ExportStagedConfigs) stagedDirectorConfig(includePlaceholders, noRedact bool) (map[string]interface{}, error) {
+	sdc := StagedDirectorConfig{service: esc.service}
+	sdc.Options.IncludePlaceholders = includePlaceholders
+	sdc.Options.NoRedact = noRedact
+
+	directorConfig, err := sdc.stagedDirectorConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not get staged config for the director: %s", err)
+	}
+
+	return directorConfig, nil
+}
+
+func (esc ExportStagedConfigs) writeConfig(filename string, contents interface{}) error {
+	output, err := yaml.Marshal(contents)
+	if err != nil {
+		return fmt.Errorf("could not marshal %s: %s", filename, err) // un-tested
+	}
+
+	path := filepath.Join(esc.Options.OutputDir, filename)
+	err = ioutil.WriteFile(path, output, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write %s: %s", path, err)
+	}
+
+	esc.logger.Printf("wrote %s", path)
+	return nil
+}
+
+// collectPlaceholderValues walks a config containing placeholders alongside
+// the same config containing values, recording the value of each placeholder.
+func collectPlaceholderValues(placeholders, values interface{}, vars map[string]interface{}) {
+	switch p := placeholders.(type) {
+	case string:
+		if matches := credentialPlaceholder.FindStringSubmatch(p); matches != nil && values != nil {
+			vars[matches[1]] = values
+		}
+	case map[string]interface{}:
+		v, _ := values.(map[string]interface{})
+		for key, value := range p {
+			collectPlaceholderValues(value, v[key], vars)
+		}
+	case []interface{}:
+		v, _ := values.([]interface{})
+		for index, value := range p {
+			if index < len(v) {
+				collectPlaceholderValues(value, v[index], vars)
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("ExportStagedConfigs", func() {
+	var (
+		logger    *fakes.Logger
+		service   *fakes.ExportStagedConfigsService
+		command   commands.ExportStagedConfigs
+		outputDir string
+	)
+
+	readOutput := func(filename string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(outputDir, filename))
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("", "export-staged-configs")
+		Expect(err).ToNot(HaveOccurred())
+
+		logger = &fakes.Logger{}
+		service = &fakes.ExportStagedConfigsService{}
+		service.InfoReturns(api.Info{Version: "2.5.0"}, nil)
+		service.ListStagedProductsReturns(api.StagedProductsOutput{
+			Products: []api.StagedProduct{
+				{GUID: "p-bosh-guid", Type: "p-bosh"},
+				{GUID: "cf-guid", Type: "cf"},
+				{GUID: "p-redis-guid", Type: "p-redis"},
+			},
+		}, nil)
+		service.ListDeployedProductsReturns([]api.DeployedProductOutput{
+			{GUID: "cf-deployed-guid", Type: "cf"},
+			{GUID: "p-redis-deployed-guid", Type: "p-redis"},
+		}, nil)
+		service.GetStagedProductByNameStub = func(name string) (api.StagedProductsFindOutput, error) {
+			return api.StagedProductsFindOutput{Product: api.StagedProduct{GUID: name + "-guid", Type: name}}, nil
+		}
+		service.GetStagedProductPropertiesStub = func(guid string) (map[string]api.ResponseProperty, error) {
+			return map[string]api.ResponseProperty{
+				".properties." + guid: {
+					Value:        "some-value",
+					Configurable: true,
+				},
+				".properties.some-secret": {
+					Type:         "secret",
+					Value:        map[string]interface{}{"secret": "***"},
+					IsCredential: true,
+					Configurable: true,
+				},
+			}, nil
+		}
+		service.GetStagedDirectorPropertiesStub = func(redact bool) (map[string]interface{}, error) {
+			password := "***"
+			if !redact {
+				password = "director-password"
+			}
+			return map[string]interface{}{
+				"director_configuration": map[string]interface{}{
+					"ntp_servers_string": "ntp.example.com",
+					"db_password":        password,
+				},
+			}, nil
+		}
+
+		command = commands.NewExportStagedConfigs(service, logger)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(outputDir)).To(Succeed())
+	})
+
+	It("writes a config file for each staged product and the director", func() {
+		err := command.Execute([]string{"--output-dir", outputDir})
+		Expect(err).ToNot(HaveOccurred())
+
+		files, err := ioutil.ReadDir(outputDir)
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, file := range files {
+			names = append(names, file.Name())
+		}
+		Expect(names).To(Equal([]string{"cf.yml", "director.yml", "p-redis.yml"}))
+
+		Expect(readOutput("cf.yml")).To(MatchYAML(`
+product-name: cf
+product-properties:
+  .properties.cf-guid:
+    value: some-value
+`))
+		Expect(readOutput("director.yml")).To(ContainSubstring("ntp_servers_string: ntp.example.com"))
+		Expect(readOutput("director.yml")).ToNot(ContainSubstring("db_password"))
+
+		Expect(service.ListDeployedProductsCallCount()).To(Equal(0))
+		Expect(service.GetDeployedProductCredentialCallCount()).To(Equal(0))
+
+		Expect(logger.PrintfCallCount()).To(Equal(3))
+		format, content := logger.PrintfArgsForCall(0)
+		Expect(format).To(Equal("wrote %s"))
+		Expect(content).To(Equal([]interface{}{filepath.Join(outputDir, "cf.yml")}))
+	})
+
+	It("creates the output directory when it does not exist", func() {
+		nestedDir := filepath.Join(outputDir, "foundation", "configs")
+
+		err := command.Execute([]string{"--output-dir", nestedDir})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(filepath.Join(nestedDir, "director.yml")).To(BeAnExistingFile())
+	})
+
+	When("--include-placeholders is set", func() {
+		It("writes placeholders for credentials and no vars file", func() {
+			err := command.Execute([]string{"--output-dir", outputDir, "--include-placeholders"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readOutput("cf.yml")).To(ContainSubstring("secret: ((properties_some-secret.secret))"))
+			Expect(readOutput("director.yml")).To(ContainSubstring("db_password: ((properties-configuration_director_configuration_db_password))"))
+			Expect(filepath.Join(outputDir, "vars")).ToNot(BeAnExistingFile())
+		})
+	})
+
+	When("--include-placeholders and --include-credentials are set", func() {
+		BeforeEach(func() {
+			service.GetDeployedProductCredentialStub = func(input api.GetDeployedProductCredentialInput) (api.GetDeployedProductCredentialOutput, error) {
+				return api.GetDeployedProductCredentialOutput{
+					Credential: api.Credential{Value: map[string]string{"secret": "shared-secret"}},
+				}, nil
+			}
+		})
+
+		It("writes the credentials of each config to its own vars file", func() {
+			err := command.Execute([]string{"--output-dir", outputDir, "--include-placeholders", "--include-credentials"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readOutput("cf.yml")).To(ContainSubstring("secret: ((properties_some-secret.secret))"))
+			Expect(readOutput("vars/cf.yml")).To(MatchYAML(`
+properties_some-secret:
+  secret: shared-secret
+`))
+			Expect(readOutput("vars/director.yml")).To(MatchYAML(`
+properties-configuration_director_configuration_db_password: director-password
+`))
+			Expect(filepath.Join(outputDir, "vars.yml")).ToNot(BeAnExistingFile())
+		})
+
+		It("keeps the credentials apart when products have a property with the same name", func() {
+			service.GetDeployedProductCredentialStub = func(input api.GetDeployedProductCredentialInput) (api.GetDeployedProductCredentialOutput, error) {
+				return api.GetDeployedProductCredentialOutput{
+					Credential: api.Credential{Value: map[string]string{"secret": input.DeployedGUID}},
+				}, nil
+			}
+
+			err := command.Execute([]string{"--output-dir", outputDir, "--include-placeholders", "--include-credentials"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readOutput("cf.yml")).To(ContainSubstring("secret: ((properties_some-secret.secret))"))
+			Expect(readOutput("p-redis.yml")).To(ContainSubstring("secret: ((properties_some-secret.secret))"))
+			Expect(readOutput("vars/cf.yml")).To(MatchYAML(`{properties_some-secret: {secret: cf-guid}}`))
+			Expect(readOutput("vars/p-redis.yml")).To(MatchYAML(`{properties_some-secret: {secret: p-redis-guid}}`))
+		})
+	})
+
+	Context("failure cases", func() {
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse export-staged-configs flags: flag provided but not defined: -badflag"))
+			})
+		})
+
+		When("credentials are requested for a product that is not deployed", func() {
+			It("returns an error", func() {
+				service.ListDeployedProductsReturns([]api.DeployedProductOutput{{Type: "cf"}}, nil)
+
+				err := command.Execute([]string{"--output-dir", outputDir, "--include-credentials"})
+				Expect(err).To(MatchError("cannot retrieve credentials for product 'p-redis': deploy the product and retry"))
+			})
+		})
+
+		When("listing staged products fails", func() {
+			It("returns an error", func() {
+				service.ListStagedProductsReturns(api.StagedProductsOutput{}, errors.New("some-error"))
+
+				err := command.Execute([]string{"--output-dir", outputDir})
+				Expect(err).To(MatchError("some-error"))
+			})
+		})
+
+		When("reading a product fails", func() {
+			It("returns an error naming the product", func() {
+				service.GetStagedProductPropertiesReturns(nil, errors.New("some-error"))
+				service.GetStagedProductPropertiesStub = nil
+
+				err := command.Execute([]string{"--output-dir", outputDir})
+				Expect(err).To(MatchError("could not get staged config for product 'cf': some-error"))
+			})
+		})
+
+		When("reading the director fails", func() {
+			It("returns an error", func() {
+				service.GetStagedDirectorAvailabilityZonesReturns(api.AvailabilityZonesOutput{}, errors.New("some-error"))
+
+				err := command.Execute([]string{"--output-dir", outputDir})
+				Expect(err).To(MatchError("could not get staged config for the director: some-error"))
+			})
+		})
+	})
+
+	Describe("Usage", func() {
+		It("returns usage information for the command", func() {
+			Expect(command.Usage()).To(Equal(jhanda.Usage{
+				Description:      "This command writes the config of the staged director and of every staged product to a directory, in the formats accepted by om configure-director and om configure-product",
+				ShortDescription: "**EXPERIMENTAL** generates configs for the director and all staged products",
+				Flags:            command.Options,
+			}))
+		})
+	})
+})
@@ -0,0 +1,8 @@
+package commands
+
+// WithLogSizeStep lets tests grow the wait between polls of --follow
+// without building a log of several megabytes.
+func WithLogSizeStep(command InstallationLog, step int) InstallationLog {
+	command.logSizeStep = step
+	return command
+}
@@ -0,0 +1,397 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal-cf/jhanda"
+	"gopkg.in/yaml.v2"
+)
+
+type ExtractVars struct {
+	logger  logger
+	Options struct {
+		Foundations []string `long:"foundation" short:"f" required:"true" description:"NAME=PATH of a foundation's config file, or of a directory written by export-staged-configs. Provide at least two"`
+		OutputDir   string   `long:"output-dir" short:"o" required:"true" description:"directory to write the shared templates, and vars/<NAME>.yml for each foundation, to"`
+	}
+}
+
+type extractVarsFoundation struct {
+	name  string
+	files map[string]string
+	vars  map[string]interface{}
+}
+
+func NewExtractVars(logger logger) ExtractVars {
+	return ExtractVars{
+		logger: logger,
+	}
+}
+
+func (ev ExtractVars) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command compares the configs exported from two or more foundations, such as with staged-config, staged-director-config or export-staged-configs. Values that differ between foundations are replaced with placeholders in a shared template, and written to a vars file per foundation.",
+		ShortDescription: "**EXPERIMENTAL** factors the differences between foundations' configs out into vars files",
+		Flags:            ev.Options,
+	}
+}
+
+func (ev ExtractVars) Execute(args []string) error {
+	if _, err := jhanda.Parse(&ev.Options, args); err != nil {
+		return fmt.Errorf("could not parse extract-vars flags: %s", err)
+	}
+
+	foundations, err := ev.loadFoundations()
+	if err != nil {
+		return err
+	}
+
+	var filenames []string
+	for filename := range foundations[0].files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, foundation := range foundations[1:] {
+		if len(foundation.files) != len(filenames) {
+			return fmt.Errorf("foundations %q and %q do not contain the same config files", foundations[0].name, foundation.name)
+		}
+		for _, filename := range filenames {
+			if _, ok := foundation.files[filename]; !ok {
+				return fmt.Errorf("foundation %q does not contain %s", foundation.name, filename)
+			}
+		}
+	}
+
+	err = os.MkdirAll(filepath.Join(ev.Options.OutputDir, "vars"), 0755)
+	if err != nil {
+		return fmt.Errorf("could not create output directory: %s", err)
+	}
+
+	placeholders := map[string]string{}
+	for _, filename := range filenames {
+		var values []interface{}
+		for _, foundation := range foundations {
+			contents, err := ioutil.ReadFile(foundation.files[filename])
+			if err != nil {
+				return fmt.Errorf("could not read %s: %s", foundation.files[filename], err)
+			}
+
+			var value interface{}
+			err = yaml.Unmarshal(contents, &value)
+			if err != nil {
+				return fmt.Errorf("could not parse %s: %s", foundation.files[filename], err)
+			}
+			values = append(values, value)
+		}
+
+		var path []string
+		if len(filenames) > 1 {
+			path = append(path, strings.TrimSuffix(filename, filepath.Ext(filename)))
+		}
+
+		template, err := extractVars(path, values, foundations, placeholders, filename)
+		if err != nil {
+			return err
+		}
+
+		err = ev.writeYAML(filepath.Join(ev.Options.OutputDir, filename), template)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, foundation := range foundations {
+		err = ev.writeYAML(filepath.Join(ev.Options.OutputDir, "vars", foundation.name+".yml"), foundation.vars)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ev ExtractVars) loadFoundations() ([]extractVarsFoundation, error) {
+	var foundations []extractVarsFoundation
+	names := map[string]bool{}
+	singleFiles := true
+
+	for _, option := range ev.Options.Foundations {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("could not parse --foundation %q: expected NAME=PATH", option)
+		}
+
+		name, path := parts[0], parts[1]
+		if names[name] {
+			return nil, fmt.Errorf("foundation %q was provided more than once", name)
+		}
+		names[name] = true
+
+		files, isDir, err := configFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		if isDir {
+			singleFiles = false
+		}
+
+		foundations = append(foundations, extractVarsFoundation{
+			name:  name,
+			files: files,
+			vars:  map[string]interface{}{},
+		})
+	}
+
+	if len(foundations) < 2 {
+		return nil, fmt.Errorf("at least two foundations must be provided to compare")
+	}
+
+	// single config files are compared with each other whatever they are
+	// named, and the template is named after the first one
+	if singleFiles {
+		var filename string
+		for name := range foundations[0].files {
+			filename = name
+		}
+
+		for index := range foundations[1:] {
+			foundation := &foundations[index+1]
+			for _, path := range foundation.files {
+				foundation.files = map[string]string{filename: path}
+			}
+		}
+	}
+
+	return foundations, nil
+}
+
+// configFiles returns the config files at path by name, and whether path is
+// a directory. A directory is expected to be laid out as export-staged-configs
+// writes it.
+func configFiles(path string) (map[string]string, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	if !info.IsDir() {
+		return map[string]string{filepath.Base(path): path}, false, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(path, "*.yml"))
+	if err != nil {
+		return nil, true, err // un-tested
+	}
+
+	files := map[string]string{}
+	for _, match := range matches {
+		files[filepath.Base(match)] = match
+	}
+
+	if len(files) == 0 {
+		return nil, true, fmt.Errorf("no config files found in %s", path)
+	}
+
+	return files, true, nil
+}
+
+func (ev ExtractVars) writeYAML(path string, contents interface{}) error {
+	output, err := yaml.Marshal(contents)
+	if err != nil {
+		return fmt.Errorf("could not marshal %s: %s", path, err) // un-tested
+	}
+
+	err = ioutil.WriteFile(path, output, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write %s: %s", path, err)
+	}
+
+	ev.logger.Printf("wrote %s", path)
+	return nil
+}
+
+// extractVars returns the value that the foundations share at path. Where
+// they differ, it records each foundation's value in its vars and returns a
+// placeholder instead. Maps with the same keys and lists of the same length
+// are compared element by element so that only the differing leaves are
+// replaced.
+func extractVars(path []string, values []interface{}, foundations []extractVarsFoundation, placeholders map[string]string, filename string) (interface{}, error) {
+	same := true
+	for _, value := range values[1:] {
+		if !reflect.DeepEqual(values[0], value) {
+			same = false
+			break
+		}
+	}
+	if same {
+		return values[0], nil
+	}
+
+	if maps, ok := sameKeyMaps(values); ok {
+		template := map[interface{}]interface{}{}
+		for _, key := range sortedKeys(maps[0]) {
+			var elements []interface{}
+			for _, m := range maps {
+				elements = append(elements, m[key])
+			}
+
+			value, err := extractVars(append(path[:len(path):len(path)], fmt.Sprintf("%v", key)), elements, foundations, placeholders, filename)
+			if err != nil {
+				return nil, err
+			}
+			template[key] = value
+		}
+		return template, nil
+	}
+
+	if lists, ok := sameLengthLists(values); ok {
+		lists = pairByName(lists)
+
+		var template []interface{}
+		for index := range lists[0] {
+			var elements []interface{}
+			for _, list := range lists {
+				elements = append(elements, list[index])
+			}
+
+			value, err := extractVars(append(path[:len(path):len(path)], listElementName(elements, index)), elements, foundations, placeholders, filename)
+			if err != nil {
+				return nil, err
+			}
+			template = append(template, value)
+		}
+		return template, nil
+	}
+
+	name := varName(path)
+	location := fmt.Sprintf("%s (%s)", filename, strings.Join(path, " > "))
+	if existing, ok := placeholders[name]; ok {
+		return nil, fmt.Errorf("cannot extract a var from %s: the placeholder ((%s)) is already used for a different value in %s", location, name, existing)
+	}
+	placeholders[name] = location
+
+	for index, foundation := range foundations {
+		foundation.vars[name] = values[index]
+	}
+
+	return fmt.Sprintf("((%s))", name), nil
+}
+
+func sameKeyMaps(values []interface{}) ([]map[interface{}]interface{}, bool) {
+	var maps []map[interface{}]interface{}
+	for _, value := range values {
+		m, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return nil, false
+		}
+		if len(maps) > 0 {
+			if len(m) != len(maps[0]) {
+				return nil, false
+			}
+			for key := range maps[0] {
+				if _, ok := m[key]; !ok {
+					return nil, false
+				}
+			}
+		}
+		maps = append(maps, m)
+	}
+	return maps, true
+}
+
+// sortedKeys orders the keys of a map so that, when two leaves would get
+// the same placeholder, the same one always wins and is named in the error.
+func sortedKeys(m map[interface{}]interface{}) []interface{} {
+	var keys []interface{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprintf("%v", keys[i]) < fmt.Sprintf("%v", keys[j])
+	})
+	return keys
+}
+
+func sameLengthLists(values []interface{}) ([][]interface{}, bool) {
+	var lists [][]interface{}
+	for _, value := range values {
+		list, ok := value.([]interface{})
+		if !ok || (len(lists) > 0 && len(list) != len(lists[0])) {
+			return nil, false
+		}
+		lists = append(lists, list)
+	}
+	return lists, true
+}
+
+// pairByName reorders the lists of the other foundations to match the first
+// one when every element has a "name" and the lists have the same names, so
+// that elements are compared by name rather than by position. Otherwise the
+// lists are left to be compared by index.
+func pairByName(lists [][]interface{}) [][]interface{} {
+	var byName []map[string]interface{}
+	for _, list := range lists {
+		elements := map[string]interface{}{}
+		for _, element := range list {
+			m, _ := element.(map[interface{}]interface{})
+			name, _ := m["name"].(string)
+			if _, ok := elements[name]; ok || name == "" {
+				return lists
+			}
+			elements[name] = element
+		}
+		byName = append(byName, elements)
+	}
+
+	paired := [][]interface{}{lists[0]}
+	for _, elements := range byName[1:] {
+		var list []interface{}
+		for _, element := range lists[0] {
+			name := element.(map[interface{}]interface{})["name"].(string)
+			match, ok := elements[name]
+			if !ok {
+				return lists
+			}
+			list = append(list, match)
+		}
+		paired = append(paired, list)
+	}
+	return paired
+}
+
+// listElementName names list elements by their "name" when every foundation
+// agrees on it, so that placeholders do not depend on the order of the list.
+func listElementName(elements []interface{}, index int) string {
+	var name string
+	for _, element := range elements {
+		m, _ := element.(map[interface{}]interface{})
+		elementName, _ := m["name"].(string)
+		if elementName == "" || (name != "" && elementName != name) {
+			return strconv.Itoa(index)
+		}
+		name = elementName
+	}
+	return name
+}
+
+// varName builds a placeholder name from a path the same way as the
+// credential placeholders of staged-config, e.g. product-properties_properties_some-property_value.
+func varName(path []string) string {
+	var segments []string
+	for _, segment := range path {
+		segments = append(segments, strings.Replace(strings.TrimLeft(segment, "."), ".", "_", -1))
+	}
+
+	if len(segments) == 0 {
+		return "config"
+	}
+
+	return strings.Join(segments, "_")
+}
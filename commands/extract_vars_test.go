@@ -0,0 +1,284 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("ExtractVars", func() {
+	var (
+		logger    *fakes.Logger
+		command   commands.ExtractVars
+		tempDir   string
+		outputDir string
+	)
+
+	writeFile := func(path, contents string) string {
+		path = filepath.Join(tempDir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	readOutput := func(path string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(outputDir, path))
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "extract-vars")
+		Expect(err).ToNot(HaveOccurred())
+		outputDir = filepath.Join(tempDir, "output")
+
+		logger = &fakes.Logger{}
+		command = commands.NewExtractVars(logger)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	It("replaces the values that differ with placeholders and writes vars per foundation", func() {
+		sandbox := writeFile("sandbox/cf.yml", `---
+product-name: cf
+product-properties:
+  .cloud_controller.system_domain:
+    value: sys.sandbox.example.com
+  .properties.secret:
+    value:
+      secret: ((properties_secret.secret))
+  .properties.shared:
+    value: same-everywhere
+network-properties:
+  other_availability_zones:
+  - name: us-east-1a
+resource-config:
+  diego_cell:
+    instances: 1
+`)
+		production := writeFile("production/cf.yml", `---
+product-name: cf
+product-properties:
+  .cloud_controller.system_domain:
+    value: sys.example.com
+  .properties.secret:
+    value:
+      secret: ((properties_secret.secret))
+  .properties.shared:
+    value: same-everywhere
+network-properties:
+  other_availability_zones:
+  - name: us-east-1a
+  - name: us-east-1b
+resource-config:
+  diego_cell:
+    instances: 10
+`)
+
+		err := command.Execute([]string{
+			"--foundation", "sandbox=" + sandbox,
+			"--foundation", "production=" + production,
+			"--output-dir", outputDir,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readOutput("cf.yml")).To(MatchYAML(`---
+product-name: cf
+product-properties:
+  .cloud_controller.system_domain:
+    value: ((product-properties_cloud_controller_system_domain_value))
+  .properties.secret:
+    value:
+      secret: ((properties_secret.secret))
+  .properties.shared:
+    value: same-everywhere
+network-properties:
+  other_availability_zones: ((network-properties_other_availability_zones))
+resource-config:
+  diego_cell:
+    instances: ((resource-config_diego_cell_instances))
+`))
+
+		Expect(readOutput("vars/sandbox.yml")).To(MatchYAML(`---
+product-properties_cloud_controller_system_domain_value: sys.sandbox.example.com
+network-properties_other_availability_zones:
+- name: us-east-1a
+resource-config_diego_cell_instances: 1
+`))
+		Expect(readOutput("vars/production.yml")).To(MatchYAML(`---
+product-properties_cloud_controller_system_domain_value: sys.example.com
+network-properties_other_availability_zones:
+- name: us-east-1a
+- name: us-east-1b
+resource-config_diego_cell_instances: 10
+`))
+
+		Expect(logger.PrintfCallCount()).To(Equal(3))
+	})
+
+	It("names list elements by their name", func() {
+		sandbox := writeFile("sandbox/director.yml", `{az-configuration: [{name: az1, cluster: sandbox-cluster}]}`)
+		production := writeFile("production/director.yml", `{az-configuration: [{name: az1, cluster: prod-cluster}]}`)
+
+		err := command.Execute([]string{
+			"--foundation", "sandbox=" + sandbox,
+			"--foundation", "production=" + production,
+			"--output-dir", outputDir,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readOutput("director.yml")).To(MatchYAML(`{az-configuration: [{name: az1, cluster: ((az-configuration_az1_cluster))}]}`))
+		Expect(readOutput("vars/production.yml")).To(MatchYAML(`{az-configuration_az1_cluster: prod-cluster}`))
+	})
+
+	It("pairs list elements by their name when they are in a different order", func() {
+		sandbox := writeFile("sandbox/director.yml", `{az-configuration: [{name: az1, cluster: sandbox-1}, {name: az2, cluster: sandbox-2}]}`)
+		production := writeFile("production/director.yml", `{az-configuration: [{name: az2, cluster: prod-2}, {name: az1, cluster: prod-1}]}`)
+
+		err := command.Execute([]string{
+			"--foundation", "sandbox=" + sandbox,
+			"--foundation", "production=" + production,
+			"--output-dir", outputDir,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readOutput("director.yml")).To(MatchYAML(`{az-configuration: [{name: az1, cluster: ((az-configuration_az1_cluster))}, {name: az2, cluster: ((az-configuration_az2_cluster))}]}`))
+		Expect(readOutput("vars/sandbox.yml")).To(MatchYAML(`{az-configuration_az1_cluster: sandbox-1, az-configuration_az2_cluster: sandbox-2}`))
+		Expect(readOutput("vars/production.yml")).To(MatchYAML(`{az-configuration_az1_cluster: prod-1, az-configuration_az2_cluster: prod-2}`))
+	})
+
+	It("compares single config files whatever they are named", func() {
+		sandbox := writeFile("sandbox/cf.yml", `{product-name: cf, product-properties: {.properties.domain: {value: sandbox.example.com}}}`)
+		production := writeFile("prod/cf-prod.yml", `{product-name: cf, product-properties: {.properties.domain: {value: example.com}}}`)
+
+		err := command.Execute([]string{
+			"--foundation", "sandbox=" + sandbox,
+			"--foundation", "production=" + production,
+			"--output-dir", outputDir,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readOutput("cf.yml")).To(MatchYAML(`{product-name: cf, product-properties: {.properties.domain: {value: ((product-properties_properties_domain_value))}}}`))
+		Expect(readOutput("vars/production.yml")).To(MatchYAML(`{product-properties_properties_domain_value: example.com}`))
+	})
+
+	When("the foundations are directories written by export-staged-configs", func() {
+		It("templates every config file and prefixes placeholders with the file name", func() {
+			writeFile("sandbox/cf.yml", `{product-name: cf, product-properties: {.properties.domain: {value: sandbox.example.com}}}`)
+			writeFile("sandbox/director.yml", `{properties-configuration: {director_configuration: {ntp_servers_string: ntp.example.com}}}`)
+			writeFile("sandbox/vars/cf.yml", `{properties_secret: {secret: sandbox-secret}}`)
+			writeFile("production/cf.yml", `{product-name: cf, product-properties: {.properties.domain: {value: example.com}}}`)
+			writeFile("production/director.yml", `{properties-configuration: {director_configuration: {ntp_servers_string: ntp.example.com}}}`)
+
+			err := command.Execute([]string{
+				"--foundation", "sandbox=" + filepath.Join(tempDir, "sandbox"),
+				"--foundation", "production=" + filepath.Join(tempDir, "production"),
+				"--output-dir", outputDir,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readOutput("cf.yml")).To(MatchYAML(`{product-name: cf, product-properties: {.properties.domain: {value: ((cf_product-properties_properties_domain_value))}}}`))
+			Expect(readOutput("director.yml")).To(MatchYAML(`{properties-configuration: {director_configuration: {ntp_servers_string: ntp.example.com}}}`))
+			Expect(readOutput("vars/sandbox.yml")).To(MatchYAML(`{cf_product-properties_properties_domain_value: sandbox.example.com}`))
+			Expect(filepath.Join(outputDir, "vars.yml")).ToNot(BeAnExistingFile())
+		})
+
+		It("returns an error when a foundation is missing a config file", func() {
+			writeFile("sandbox/cf.yml", `{product-name: cf}`)
+			writeFile("sandbox/p-redis.yml", `{product-name: p-redis}`)
+			writeFile("production/cf.yml", `{product-name: cf}`)
+			writeFile("production/p-mysql.yml", `{product-name: p-mysql}`)
+
+			err := command.Execute([]string{
+				"--foundation", "sandbox=" + filepath.Join(tempDir, "sandbox"),
+				"--foundation", "production=" + filepath.Join(tempDir, "production"),
+				"--output-dir", outputDir,
+			})
+			Expect(err).To(MatchError(`foundation "production" does not contain p-redis.yml`))
+		})
+	})
+
+	Context("failure cases", func() {
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse extract-vars flags: flag provided but not defined: -badflag"))
+			})
+		})
+
+		When("only one foundation is provided", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{
+					"--foundation", "sandbox=" + writeFile("sandbox/cf.yml", `{}`),
+					"--output-dir", outputDir,
+				})
+				Expect(err).To(MatchError("at least two foundations must be provided to compare"))
+			})
+		})
+
+		When("a foundation is not NAME=PATH", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{
+					"--foundation", "sandbox",
+					"--foundation", "production=some-path",
+					"--output-dir", outputDir,
+				})
+				Expect(err).To(MatchError(`could not parse --foundation "sandbox": expected NAME=PATH`))
+			})
+		})
+
+		When("a foundation name is repeated", func() {
+			It("returns an error", func() {
+				path := writeFile("sandbox/cf.yml", `{}`)
+
+				err := command.Execute([]string{
+					"--foundation", "sandbox=" + path,
+					"--foundation", "sandbox=" + path,
+					"--output-dir", outputDir,
+				})
+				Expect(err).To(MatchError(`foundation "sandbox" was provided more than once`))
+			})
+		})
+
+		When("two values would get the same placeholder", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{
+					"--foundation", "sandbox=" + writeFile("sandbox/cf.yml", `{product-properties: {.properties.domain: {value: a}, properties.domain: {value: b}}}`),
+					"--foundation", "production=" + writeFile("production/cf.yml", `{product-properties: {.properties.domain: {value: c}, properties.domain: {value: d}}}`),
+					"--output-dir", outputDir,
+				})
+				Expect(err).To(MatchError("cannot extract a var from cf.yml (product-properties > properties.domain > value): the placeholder ((product-properties_properties_domain_value)) is already used for a different value in cf.yml (product-properties > .properties.domain > value)"))
+			})
+		})
+
+		When("a config file is not valid YAML", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{
+					"--foundation", "sandbox=" + writeFile("sandbox/cf.yml", `{}`),
+					"--foundation", "production=" + writeFile("production/cf.yml", `{invalid`),
+					"--output-dir", outputDir,
+				})
+				Expect(err).To(MatchError(ContainSubstring("could not parse")))
+			})
+		})
+	})
+
+	Describe("Usage", func() {
+		It("returns usage information for the command", func() {
+			Expect(command.Usage()).To(Equal(jhanda.Usage{
+				Description:      "This command compares the configs exported from two or more foundations, such as with staged-config, staged-director-config or export-staged-configs. Values that differ between foundations are replaced with placeholders in a shared template, and written to a vars file per foundation.",
+				ShortDescription: "**EXPERIMENTAL** factors the differences between foundations' configs out into vars files",
+				Flags:            command.Options,
+			}))
+		})
+	})
+})
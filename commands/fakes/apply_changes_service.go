@@ -104,15 +104,16 @@ func (fake *ApplyChangesService) CreateInstallation(arg1 bool, arg2 bool, arg3 [
 		arg3 []string
 		arg4 api.ApplyErrandChanges
 	}{arg1, arg2, arg3Copy, arg4})
+	stub := fake.CreateInstallationStub
+	fakeReturns := fake.createInstallationReturns
 	fake.recordInvocation("CreateInstallation", []interface{}{arg1, arg2, arg3Copy, arg4})
 	fake.createInstallationMutex.Unlock()
-	if fake.CreateInstallationStub != nil {
-		return fake.CreateInstallationStub(arg1, arg2, arg3, arg4)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.createInstallationReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -167,15 +168,16 @@ func (fake *ApplyChangesService) GetInstallation(arg1 int) (api.InstallationsSer
 	fake.getInstallationArgsForCall = append(fake.getInstallationArgsForCall, struct {
 		arg1 int
 	}{arg1})
+	stub := fake.GetInstallationStub
+	fakeReturns := fake.getInstallationReturns
 	fake.recordInvocation("GetInstallation", []interface{}{arg1})
 	fake.getInstallationMutex.Unlock()
-	if fake.GetInstallationStub != nil {
-		return fake.GetInstallationStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getInstallationReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -230,15 +232,16 @@ func (fake *ApplyChangesService) GetInstallationLogs(arg1 int) (api.Installation
 	fake.getInstallationLogsArgsForCall = append(fake.getInstallationLogsArgsForCall, struct {
 		arg1 int
 	}{arg1})
+	stub := fake.GetInstallationLogsStub
+	fakeReturns := fake.getInstallationLogsReturns
 	fake.recordInvocation("GetInstallationLogs", []interface{}{arg1})
 	fake.getInstallationLogsMutex.Unlock()
-	if fake.GetInstallationLogsStub != nil {
-		return fake.GetInstallationLogsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getInstallationLogsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -292,15 +295,16 @@ func (fake *ApplyChangesService) Info() (api.Info, error) {
 	ret, specificReturn := fake.infoReturnsOnCall[len(fake.infoArgsForCall)]
 	fake.infoArgsForCall = append(fake.infoArgsForCall, struct {
 	}{})
+	stub := fake.InfoStub
+	fakeReturns := fake.infoReturns
 	fake.recordInvocation("Info", []interface{}{})
 	fake.infoMutex.Unlock()
-	if fake.InfoStub != nil {
-		return fake.InfoStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.infoReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -347,15 +351,16 @@ func (fake *ApplyChangesService) ListInstallations() ([]api.InstallationsService
 	ret, specificReturn := fake.listInstallationsReturnsOnCall[len(fake.listInstallationsArgsForCall)]
 	fake.listInstallationsArgsForCall = append(fake.listInstallationsArgsForCall, struct {
 	}{})
+	stub := fake.ListInstallationsStub
+	fakeReturns := fake.listInstallationsReturns
 	fake.recordInvocation("ListInstallations", []interface{}{})
 	fake.listInstallationsMutex.Unlock()
-	if fake.ListInstallationsStub != nil {
-		return fake.ListInstallationsStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listInstallationsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -402,15 +407,16 @@ func (fake *ApplyChangesService) RunningInstallation() (api.InstallationsService
 	ret, specificReturn := fake.runningInstallationReturnsOnCall[len(fake.runningInstallationArgsForCall)]
 	fake.runningInstallationArgsForCall = append(fake.runningInstallationArgsForCall, struct {
 	}{})
+	stub := fake.RunningInstallationStub
+	fakeReturns := fake.runningInstallationReturns
 	fake.recordInvocation("RunningInstallation", []interface{}{})
 	fake.runningInstallationMutex.Unlock()
-	if fake.RunningInstallationStub != nil {
-		return fake.RunningInstallationStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.runningInstallationReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -63,6 +63,56 @@ type ConfigureDirectorService struct {
 	deleteVMExtensionReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetStagedDirectorAvailabilityZonesStub        func() (api.AvailabilityZonesOutput, error)
+	getStagedDirectorAvailabilityZonesMutex       sync.RWMutex
+	getStagedDirectorAvailabilityZonesArgsForCall []struct {
+	}
+	getStagedDirectorAvailabilityZonesReturns struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}
+	getStagedDirectorAvailabilityZonesReturnsOnCall map[int]struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}
+	GetStagedDirectorIaasConfigurationsStub        func(bool) (map[string][]map[string]interface{}, error)
+	getStagedDirectorIaasConfigurationsMutex       sync.RWMutex
+	getStagedDirectorIaasConfigurationsArgsForCall []struct {
+		arg1 bool
+	}
+	getStagedDirectorIaasConfigurationsReturns struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}
+	getStagedDirectorIaasConfigurationsReturnsOnCall map[int]struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}
+	GetStagedDirectorNetworksStub        func() (api.NetworksConfigurationOutput, error)
+	getStagedDirectorNetworksMutex       sync.RWMutex
+	getStagedDirectorNetworksArgsForCall []struct {
+	}
+	getStagedDirectorNetworksReturns struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}
+	getStagedDirectorNetworksReturnsOnCall map[int]struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}
+	GetStagedDirectorPropertiesStub        func(bool) (map[string]interface{}, error)
+	getStagedDirectorPropertiesMutex       sync.RWMutex
+	getStagedDirectorPropertiesArgsForCall []struct {
+		arg1 bool
+	}
+	getStagedDirectorPropertiesReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedDirectorPropertiesReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
 	GetStagedProductByNameStub        func(string) (api.StagedProductsFindOutput, error)
 	getStagedProductByNameMutex       sync.RWMutex
 	getStagedProductByNameArgsForCall []struct {
@@ -76,6 +126,20 @@ type ConfigureDirectorService struct {
 		result1 api.StagedProductsFindOutput
 		result2 error
 	}
+	GetStagedProductJobResourceConfigStub        func(string, string) (api.JobProperties, error)
+	getStagedProductJobResourceConfigMutex       sync.RWMutex
+	getStagedProductJobResourceConfigArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getStagedProductJobResourceConfigReturns struct {
+		result1 api.JobProperties
+		result2 error
+	}
+	getStagedProductJobResourceConfigReturnsOnCall map[int]struct {
+		result1 api.JobProperties
+		result2 error
+	}
 	GetStagedProductManifestStub        func(string) (string, error)
 	getStagedProductManifestMutex       sync.RWMutex
 	getStagedProductManifestArgsForCall []struct {
@@ -89,6 +153,19 @@ type ConfigureDirectorService struct {
 		result1 string
 		result2 error
 	}
+	GetStagedProductNetworksAndAZsStub        func(string) (map[string]interface{}, error)
+	getStagedProductNetworksAndAZsMutex       sync.RWMutex
+	getStagedProductNetworksAndAZsArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductNetworksAndAZsReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedProductNetworksAndAZsReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
 	InfoStub        func() (api.Info, error)
 	infoMutex       sync.RWMutex
 	infoArgsForCall []struct {
@@ -113,6 +190,19 @@ type ConfigureDirectorService struct {
 		result1 []api.InstallationsServiceOutput
 		result2 error
 	}
+	ListStagedProductJobsStub        func(string) (map[string]string, error)
+	listStagedProductJobsMutex       sync.RWMutex
+	listStagedProductJobsArgsForCall []struct {
+		arg1 string
+	}
+	listStagedProductJobsReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	listStagedProductJobsReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 error
+	}
 	ListStagedVMExtensionsStub        func() ([]api.VMExtension, error)
 	listStagedVMExtensionsMutex       sync.RWMutex
 	listStagedVMExtensionsArgsForCall []struct {
@@ -204,15 +294,16 @@ func (fake *ConfigureDirectorService) ConfigureJobResourceConfig(arg1 string, ar
 		arg1 string
 		arg2 map[string]interface{}
 	}{arg1, arg2})
+	stub := fake.ConfigureJobResourceConfigStub
+	fakeReturns := fake.configureJobResourceConfigReturns
 	fake.recordInvocation("ConfigureJobResourceConfig", []interface{}{arg1, arg2})
 	fake.configureJobResourceConfigMutex.Unlock()
-	if fake.ConfigureJobResourceConfigStub != nil {
-		return fake.ConfigureJobResourceConfigStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.configureJobResourceConfigReturns
 	return fakeReturns.result1
 }
 
@@ -264,15 +355,16 @@ func (fake *ConfigureDirectorService) CreateCustomVMTypes(arg1 api.CreateVMTypes
 	fake.createCustomVMTypesArgsForCall = append(fake.createCustomVMTypesArgsForCall, struct {
 		arg1 api.CreateVMTypes
 	}{arg1})
+	stub := fake.CreateCustomVMTypesStub
+	fakeReturns := fake.createCustomVMTypesReturns
 	fake.recordInvocation("CreateCustomVMTypes", []interface{}{arg1})
 	fake.createCustomVMTypesMutex.Unlock()
-	if fake.CreateCustomVMTypesStub != nil {
-		return fake.CreateCustomVMTypesStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.createCustomVMTypesReturns
 	return fakeReturns.result1
 }
 
@@ -324,15 +416,16 @@ func (fake *ConfigureDirectorService) CreateStagedVMExtension(arg1 api.CreateVME
 	fake.createStagedVMExtensionArgsForCall = append(fake.createStagedVMExtensionArgsForCall, struct {
 		arg1 api.CreateVMExtension
 	}{arg1})
+	stub := fake.CreateStagedVMExtensionStub
+	fakeReturns := fake.createStagedVMExtensionReturns
 	fake.recordInvocation("CreateStagedVMExtension", []interface{}{arg1})
 	fake.createStagedVMExtensionMutex.Unlock()
-	if fake.CreateStagedVMExtensionStub != nil {
-		return fake.CreateStagedVMExtensionStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.createStagedVMExtensionReturns
 	return fakeReturns.result1
 }
 
@@ -383,15 +476,16 @@ func (fake *ConfigureDirectorService) DeleteCustomVMTypes() error {
 	ret, specificReturn := fake.deleteCustomVMTypesReturnsOnCall[len(fake.deleteCustomVMTypesArgsForCall)]
 	fake.deleteCustomVMTypesArgsForCall = append(fake.deleteCustomVMTypesArgsForCall, struct {
 	}{})
+	stub := fake.DeleteCustomVMTypesStub
+	fakeReturns := fake.deleteCustomVMTypesReturns
 	fake.recordInvocation("DeleteCustomVMTypes", []interface{}{})
 	fake.deleteCustomVMTypesMutex.Unlock()
-	if fake.DeleteCustomVMTypesStub != nil {
-		return fake.DeleteCustomVMTypesStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.deleteCustomVMTypesReturns
 	return fakeReturns.result1
 }
 
@@ -436,15 +530,16 @@ func (fake *ConfigureDirectorService) DeleteVMExtension(arg1 string) error {
 	fake.deleteVMExtensionArgsForCall = append(fake.deleteVMExtensionArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.DeleteVMExtensionStub
+	fakeReturns := fake.deleteVMExtensionReturns
 	fake.recordInvocation("DeleteVMExtension", []interface{}{arg1})
 	fake.deleteVMExtensionMutex.Unlock()
-	if fake.DeleteVMExtensionStub != nil {
-		return fake.DeleteVMExtensionStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.deleteVMExtensionReturns
 	return fakeReturns.result1
 }
 
@@ -490,21 +585,262 @@ func (fake *ConfigureDirectorService) DeleteVMExtensionReturnsOnCall(i int, resu
 	}{result1}
 }
 
+func (fake *ConfigureDirectorService) GetStagedDirectorAvailabilityZones() (api.AvailabilityZonesOutput, error) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorAvailabilityZonesReturnsOnCall[len(fake.getStagedDirectorAvailabilityZonesArgsForCall)]
+	fake.getStagedDirectorAvailabilityZonesArgsForCall = append(fake.getStagedDirectorAvailabilityZonesArgsForCall, struct {
+	}{})
+	stub := fake.GetStagedDirectorAvailabilityZonesStub
+	fakeReturns := fake.getStagedDirectorAvailabilityZonesReturns
+	fake.recordInvocation("GetStagedDirectorAvailabilityZones", []interface{}{})
+	fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorAvailabilityZonesCallCount() int {
+	fake.getStagedDirectorAvailabilityZonesMutex.RLock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.RUnlock()
+	return len(fake.getStagedDirectorAvailabilityZonesArgsForCall)
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorAvailabilityZonesCalls(stub func() (api.AvailabilityZonesOutput, error)) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	fake.GetStagedDirectorAvailabilityZonesStub = stub
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorAvailabilityZonesReturns(result1 api.AvailabilityZonesOutput, result2 error) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	fake.GetStagedDirectorAvailabilityZonesStub = nil
+	fake.getStagedDirectorAvailabilityZonesReturns = struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorAvailabilityZonesReturnsOnCall(i int, result1 api.AvailabilityZonesOutput, result2 error) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	fake.GetStagedDirectorAvailabilityZonesStub = nil
+	if fake.getStagedDirectorAvailabilityZonesReturnsOnCall == nil {
+		fake.getStagedDirectorAvailabilityZonesReturnsOnCall = make(map[int]struct {
+			result1 api.AvailabilityZonesOutput
+			result2 error
+		})
+	}
+	fake.getStagedDirectorAvailabilityZonesReturnsOnCall[i] = struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorIaasConfigurations(arg1 bool) (map[string][]map[string]interface{}, error) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorIaasConfigurationsReturnsOnCall[len(fake.getStagedDirectorIaasConfigurationsArgsForCall)]
+	fake.getStagedDirectorIaasConfigurationsArgsForCall = append(fake.getStagedDirectorIaasConfigurationsArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	stub := fake.GetStagedDirectorIaasConfigurationsStub
+	fakeReturns := fake.getStagedDirectorIaasConfigurationsReturns
+	fake.recordInvocation("GetStagedDirectorIaasConfigurations", []interface{}{arg1})
+	fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorIaasConfigurationsCallCount() int {
+	fake.getStagedDirectorIaasConfigurationsMutex.RLock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.RUnlock()
+	return len(fake.getStagedDirectorIaasConfigurationsArgsForCall)
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorIaasConfigurationsCalls(stub func(bool) (map[string][]map[string]interface{}, error)) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	fake.GetStagedDirectorIaasConfigurationsStub = stub
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorIaasConfigurationsArgsForCall(i int) bool {
+	fake.getStagedDirectorIaasConfigurationsMutex.RLock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.RUnlock()
+	argsForCall := fake.getStagedDirectorIaasConfigurationsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorIaasConfigurationsReturns(result1 map[string][]map[string]interface{}, result2 error) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	fake.GetStagedDirectorIaasConfigurationsStub = nil
+	fake.getStagedDirectorIaasConfigurationsReturns = struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorIaasConfigurationsReturnsOnCall(i int, result1 map[string][]map[string]interface{}, result2 error) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	fake.GetStagedDirectorIaasConfigurationsStub = nil
+	if fake.getStagedDirectorIaasConfigurationsReturnsOnCall == nil {
+		fake.getStagedDirectorIaasConfigurationsReturnsOnCall = make(map[int]struct {
+			result1 map[string][]map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedDirectorIaasConfigurationsReturnsOnCall[i] = struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorNetworks() (api.NetworksConfigurationOutput, error) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorNetworksReturnsOnCall[len(fake.getStagedDirectorNetworksArgsForCall)]
+	fake.getStagedDirectorNetworksArgsForCall = append(fake.getStagedDirectorNetworksArgsForCall, struct {
+	}{})
+	stub := fake.GetStagedDirectorNetworksStub
+	fakeReturns := fake.getStagedDirectorNetworksReturns
+	fake.recordInvocation("GetStagedDirectorNetworks", []interface{}{})
+	fake.getStagedDirectorNetworksMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorNetworksCallCount() int {
+	fake.getStagedDirectorNetworksMutex.RLock()
+	defer fake.getStagedDirectorNetworksMutex.RUnlock()
+	return len(fake.getStagedDirectorNetworksArgsForCall)
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorNetworksCalls(stub func() (api.NetworksConfigurationOutput, error)) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	defer fake.getStagedDirectorNetworksMutex.Unlock()
+	fake.GetStagedDirectorNetworksStub = stub
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorNetworksReturns(result1 api.NetworksConfigurationOutput, result2 error) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	defer fake.getStagedDirectorNetworksMutex.Unlock()
+	fake.GetStagedDirectorNetworksStub = nil
+	fake.getStagedDirectorNetworksReturns = struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorNetworksReturnsOnCall(i int, result1 api.NetworksConfigurationOutput, result2 error) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	defer fake.getStagedDirectorNetworksMutex.Unlock()
+	fake.GetStagedDirectorNetworksStub = nil
+	if fake.getStagedDirectorNetworksReturnsOnCall == nil {
+		fake.getStagedDirectorNetworksReturnsOnCall = make(map[int]struct {
+			result1 api.NetworksConfigurationOutput
+			result2 error
+		})
+	}
+	fake.getStagedDirectorNetworksReturnsOnCall[i] = struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorProperties(arg1 bool) (map[string]interface{}, error) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorPropertiesReturnsOnCall[len(fake.getStagedDirectorPropertiesArgsForCall)]
+	fake.getStagedDirectorPropertiesArgsForCall = append(fake.getStagedDirectorPropertiesArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	stub := fake.GetStagedDirectorPropertiesStub
+	fakeReturns := fake.getStagedDirectorPropertiesReturns
+	fake.recordInvocation("GetStagedDirectorProperties", []interface{}{arg1})
+	fake.getStagedDirectorPropertiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorPropertiesCallCount() int {
+	fake.getStagedDirectorPropertiesMutex.RLock()
+	defer fake.getStagedDirectorPropertiesMutex.RUnlock()
+	return len(fake.getStagedDirectorPropertiesArgsForCall)
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorPropertiesCalls(stub func(bool) (map[string]interface{}, error)) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	defer fake.getStagedDirectorPropertiesMutex.Unlock()
+	fake.GetStagedDirectorPropertiesStub = stub
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorPropertiesArgsForCall(i int) bool {
+	fake.getStagedDirectorPropertiesMutex.RLock()
+	defer fake.getStagedDirectorPropertiesMutex.RUnlock()
+	argsForCall := fake.getStagedDirectorPropertiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorPropertiesReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	defer fake.getStagedDirectorPropertiesMutex.Unlock()
+	fake.GetStagedDirectorPropertiesStub = nil
+	fake.getStagedDirectorPropertiesReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedDirectorPropertiesReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	defer fake.getStagedDirectorPropertiesMutex.Unlock()
+	fake.GetStagedDirectorPropertiesStub = nil
+	if fake.getStagedDirectorPropertiesReturnsOnCall == nil {
+		fake.getStagedDirectorPropertiesReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedDirectorPropertiesReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *ConfigureDirectorService) GetStagedProductByName(arg1 string) (api.StagedProductsFindOutput, error) {
 	fake.getStagedProductByNameMutex.Lock()
 	ret, specificReturn := fake.getStagedProductByNameReturnsOnCall[len(fake.getStagedProductByNameArgsForCall)]
 	fake.getStagedProductByNameArgsForCall = append(fake.getStagedProductByNameArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetStagedProductByNameStub
+	fakeReturns := fake.getStagedProductByNameReturns
 	fake.recordInvocation("GetStagedProductByName", []interface{}{arg1})
 	fake.getStagedProductByNameMutex.Unlock()
-	if fake.GetStagedProductByNameStub != nil {
-		return fake.GetStagedProductByNameStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getStagedProductByNameReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -553,21 +889,87 @@ func (fake *ConfigureDirectorService) GetStagedProductByNameReturnsOnCall(i int,
 	}{result1, result2}
 }
 
+func (fake *ConfigureDirectorService) GetStagedProductJobResourceConfig(arg1 string, arg2 string) (api.JobProperties, error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	ret, specificReturn := fake.getStagedProductJobResourceConfigReturnsOnCall[len(fake.getStagedProductJobResourceConfigArgsForCall)]
+	fake.getStagedProductJobResourceConfigArgsForCall = append(fake.getStagedProductJobResourceConfigArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetStagedProductJobResourceConfigStub
+	fakeReturns := fake.getStagedProductJobResourceConfigReturns
+	fake.recordInvocation("GetStagedProductJobResourceConfig", []interface{}{arg1, arg2})
+	fake.getStagedProductJobResourceConfigMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductJobResourceConfigCallCount() int {
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	return len(fake.getStagedProductJobResourceConfigArgsForCall)
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductJobResourceConfigCalls(stub func(string, string) (api.JobProperties, error)) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = stub
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductJobResourceConfigArgsForCall(i int) (string, string) {
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	argsForCall := fake.getStagedProductJobResourceConfigArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductJobResourceConfigReturns(result1 api.JobProperties, result2 error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = nil
+	fake.getStagedProductJobResourceConfigReturns = struct {
+		result1 api.JobProperties
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductJobResourceConfigReturnsOnCall(i int, result1 api.JobProperties, result2 error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = nil
+	if fake.getStagedProductJobResourceConfigReturnsOnCall == nil {
+		fake.getStagedProductJobResourceConfigReturnsOnCall = make(map[int]struct {
+			result1 api.JobProperties
+			result2 error
+		})
+	}
+	fake.getStagedProductJobResourceConfigReturnsOnCall[i] = struct {
+		result1 api.JobProperties
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *ConfigureDirectorService) GetStagedProductManifest(arg1 string) (string, error) {
 	fake.getStagedProductManifestMutex.Lock()
 	ret, specificReturn := fake.getStagedProductManifestReturnsOnCall[len(fake.getStagedProductManifestArgsForCall)]
 	fake.getStagedProductManifestArgsForCall = append(fake.getStagedProductManifestArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetStagedProductManifestStub
+	fakeReturns := fake.getStagedProductManifestReturns
 	fake.recordInvocation("GetStagedProductManifest", []interface{}{arg1})
 	fake.getStagedProductManifestMutex.Unlock()
-	if fake.GetStagedProductManifestStub != nil {
-		return fake.GetStagedProductManifestStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getStagedProductManifestReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -616,20 +1018,85 @@ func (fake *ConfigureDirectorService) GetStagedProductManifestReturnsOnCall(i in
 	}{result1, result2}
 }
 
+func (fake *ConfigureDirectorService) GetStagedProductNetworksAndAZs(arg1 string) (map[string]interface{}, error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	ret, specificReturn := fake.getStagedProductNetworksAndAZsReturnsOnCall[len(fake.getStagedProductNetworksAndAZsArgsForCall)]
+	fake.getStagedProductNetworksAndAZsArgsForCall = append(fake.getStagedProductNetworksAndAZsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductNetworksAndAZsStub
+	fakeReturns := fake.getStagedProductNetworksAndAZsReturns
+	fake.recordInvocation("GetStagedProductNetworksAndAZs", []interface{}{arg1})
+	fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductNetworksAndAZsCallCount() int {
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	return len(fake.getStagedProductNetworksAndAZsArgsForCall)
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductNetworksAndAZsCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = stub
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductNetworksAndAZsArgsForCall(i int) string {
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	argsForCall := fake.getStagedProductNetworksAndAZsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductNetworksAndAZsReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = nil
+	fake.getStagedProductNetworksAndAZsReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) GetStagedProductNetworksAndAZsReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = nil
+	if fake.getStagedProductNetworksAndAZsReturnsOnCall == nil {
+		fake.getStagedProductNetworksAndAZsReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedProductNetworksAndAZsReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *ConfigureDirectorService) Info() (api.Info, error) {
 	fake.infoMutex.Lock()
 	ret, specificReturn := fake.infoReturnsOnCall[len(fake.infoArgsForCall)]
 	fake.infoArgsForCall = append(fake.infoArgsForCall, struct {
 	}{})
+	stub := fake.InfoStub
+	fakeReturns := fake.infoReturns
 	fake.recordInvocation("Info", []interface{}{})
 	fake.infoMutex.Unlock()
-	if fake.InfoStub != nil {
-		return fake.InfoStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.infoReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -676,15 +1143,16 @@ func (fake *ConfigureDirectorService) ListInstallations() ([]api.InstallationsSe
 	ret, specificReturn := fake.listInstallationsReturnsOnCall[len(fake.listInstallationsArgsForCall)]
 	fake.listInstallationsArgsForCall = append(fake.listInstallationsArgsForCall, struct {
 	}{})
+	stub := fake.ListInstallationsStub
+	fakeReturns := fake.listInstallationsReturns
 	fake.recordInvocation("ListInstallations", []interface{}{})
 	fake.listInstallationsMutex.Unlock()
-	if fake.ListInstallationsStub != nil {
-		return fake.ListInstallationsStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listInstallationsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -726,20 +1194,85 @@ func (fake *ConfigureDirectorService) ListInstallationsReturnsOnCall(i int, resu
 	}{result1, result2}
 }
 
+func (fake *ConfigureDirectorService) ListStagedProductJobs(arg1 string) (map[string]string, error) {
+	fake.listStagedProductJobsMutex.Lock()
+	ret, specificReturn := fake.listStagedProductJobsReturnsOnCall[len(fake.listStagedProductJobsArgsForCall)]
+	fake.listStagedProductJobsArgsForCall = append(fake.listStagedProductJobsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListStagedProductJobsStub
+	fakeReturns := fake.listStagedProductJobsReturns
+	fake.recordInvocation("ListStagedProductJobs", []interface{}{arg1})
+	fake.listStagedProductJobsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureDirectorService) ListStagedProductJobsCallCount() int {
+	fake.listStagedProductJobsMutex.RLock()
+	defer fake.listStagedProductJobsMutex.RUnlock()
+	return len(fake.listStagedProductJobsArgsForCall)
+}
+
+func (fake *ConfigureDirectorService) ListStagedProductJobsCalls(stub func(string) (map[string]string, error)) {
+	fake.listStagedProductJobsMutex.Lock()
+	defer fake.listStagedProductJobsMutex.Unlock()
+	fake.ListStagedProductJobsStub = stub
+}
+
+func (fake *ConfigureDirectorService) ListStagedProductJobsArgsForCall(i int) string {
+	fake.listStagedProductJobsMutex.RLock()
+	defer fake.listStagedProductJobsMutex.RUnlock()
+	argsForCall := fake.listStagedProductJobsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureDirectorService) ListStagedProductJobsReturns(result1 map[string]string, result2 error) {
+	fake.listStagedProductJobsMutex.Lock()
+	defer fake.listStagedProductJobsMutex.Unlock()
+	fake.ListStagedProductJobsStub = nil
+	fake.listStagedProductJobsReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureDirectorService) ListStagedProductJobsReturnsOnCall(i int, result1 map[string]string, result2 error) {
+	fake.listStagedProductJobsMutex.Lock()
+	defer fake.listStagedProductJobsMutex.Unlock()
+	fake.ListStagedProductJobsStub = nil
+	if fake.listStagedProductJobsReturnsOnCall == nil {
+		fake.listStagedProductJobsReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 error
+		})
+	}
+	fake.listStagedProductJobsReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *ConfigureDirectorService) ListStagedVMExtensions() ([]api.VMExtension, error) {
 	fake.listStagedVMExtensionsMutex.Lock()
 	ret, specificReturn := fake.listStagedVMExtensionsReturnsOnCall[len(fake.listStagedVMExtensionsArgsForCall)]
 	fake.listStagedVMExtensionsArgsForCall = append(fake.listStagedVMExtensionsArgsForCall, struct {
 	}{})
+	stub := fake.ListStagedVMExtensionsStub
+	fakeReturns := fake.listStagedVMExtensionsReturns
 	fake.recordInvocation("ListStagedVMExtensions", []interface{}{})
 	fake.listStagedVMExtensionsMutex.Unlock()
-	if fake.ListStagedVMExtensionsStub != nil {
-		return fake.ListStagedVMExtensionsStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listStagedVMExtensionsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -786,15 +1319,16 @@ func (fake *ConfigureDirectorService) ListVMTypes() ([]api.VMType, error) {
 	ret, specificReturn := fake.listVMTypesReturnsOnCall[len(fake.listVMTypesArgsForCall)]
 	fake.listVMTypesArgsForCall = append(fake.listVMTypesArgsForCall, struct {
 	}{})
+	stub := fake.ListVMTypesStub
+	fakeReturns := fake.listVMTypesReturns
 	fake.recordInvocation("ListVMTypes", []interface{}{})
 	fake.listVMTypesMutex.Unlock()
-	if fake.ListVMTypesStub != nil {
-		return fake.ListVMTypesStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listVMTypesReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -843,15 +1377,16 @@ func (fake *ConfigureDirectorService) UpdateStagedDirectorAvailabilityZones(arg1
 		arg1 api.AvailabilityZoneInput
 		arg2 bool
 	}{arg1, arg2})
+	stub := fake.UpdateStagedDirectorAvailabilityZonesStub
+	fakeReturns := fake.updateStagedDirectorAvailabilityZonesReturns
 	fake.recordInvocation("UpdateStagedDirectorAvailabilityZones", []interface{}{arg1, arg2})
 	fake.updateStagedDirectorAvailabilityZonesMutex.Unlock()
-	if fake.UpdateStagedDirectorAvailabilityZonesStub != nil {
-		return fake.UpdateStagedDirectorAvailabilityZonesStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedDirectorAvailabilityZonesReturns
 	return fakeReturns.result1
 }
 
@@ -903,15 +1438,16 @@ func (fake *ConfigureDirectorService) UpdateStagedDirectorIAASConfigurations(arg
 	fake.updateStagedDirectorIAASConfigurationsArgsForCall = append(fake.updateStagedDirectorIAASConfigurationsArgsForCall, struct {
 		arg1 api.IAASConfigurationsInput
 	}{arg1})
+	stub := fake.UpdateStagedDirectorIAASConfigurationsStub
+	fakeReturns := fake.updateStagedDirectorIAASConfigurationsReturns
 	fake.recordInvocation("UpdateStagedDirectorIAASConfigurations", []interface{}{arg1})
 	fake.updateStagedDirectorIAASConfigurationsMutex.Unlock()
-	if fake.UpdateStagedDirectorIAASConfigurationsStub != nil {
-		return fake.UpdateStagedDirectorIAASConfigurationsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedDirectorIAASConfigurationsReturns
 	return fakeReturns.result1
 }
 
@@ -963,15 +1499,16 @@ func (fake *ConfigureDirectorService) UpdateStagedDirectorNetworkAndAZ(arg1 api.
 	fake.updateStagedDirectorNetworkAndAZArgsForCall = append(fake.updateStagedDirectorNetworkAndAZArgsForCall, struct {
 		arg1 api.NetworkAndAZConfiguration
 	}{arg1})
+	stub := fake.UpdateStagedDirectorNetworkAndAZStub
+	fakeReturns := fake.updateStagedDirectorNetworkAndAZReturns
 	fake.recordInvocation("UpdateStagedDirectorNetworkAndAZ", []interface{}{arg1})
 	fake.updateStagedDirectorNetworkAndAZMutex.Unlock()
-	if fake.UpdateStagedDirectorNetworkAndAZStub != nil {
-		return fake.UpdateStagedDirectorNetworkAndAZStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedDirectorNetworkAndAZReturns
 	return fakeReturns.result1
 }
 
@@ -1023,15 +1560,16 @@ func (fake *ConfigureDirectorService) UpdateStagedDirectorNetworks(arg1 api.Netw
 	fake.updateStagedDirectorNetworksArgsForCall = append(fake.updateStagedDirectorNetworksArgsForCall, struct {
 		arg1 api.NetworkInput
 	}{arg1})
+	stub := fake.UpdateStagedDirectorNetworksStub
+	fakeReturns := fake.updateStagedDirectorNetworksReturns
 	fake.recordInvocation("UpdateStagedDirectorNetworks", []interface{}{arg1})
 	fake.updateStagedDirectorNetworksMutex.Unlock()
-	if fake.UpdateStagedDirectorNetworksStub != nil {
-		return fake.UpdateStagedDirectorNetworksStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedDirectorNetworksReturns
 	return fakeReturns.result1
 }
 
@@ -1083,15 +1621,16 @@ func (fake *ConfigureDirectorService) UpdateStagedDirectorProperties(arg1 api.Di
 	fake.updateStagedDirectorPropertiesArgsForCall = append(fake.updateStagedDirectorPropertiesArgsForCall, struct {
 		arg1 api.DirectorProperties
 	}{arg1})
+	stub := fake.UpdateStagedDirectorPropertiesStub
+	fakeReturns := fake.updateStagedDirectorPropertiesReturns
 	fake.recordInvocation("UpdateStagedDirectorProperties", []interface{}{arg1})
 	fake.updateStagedDirectorPropertiesMutex.Unlock()
-	if fake.UpdateStagedDirectorPropertiesStub != nil {
-		return fake.UpdateStagedDirectorPropertiesStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedDirectorPropertiesReturns
 	return fakeReturns.result1
 }
 
@@ -1150,14 +1689,28 @@ func (fake *ConfigureDirectorService) Invocations() map[string][][]interface{} {
 	defer fake.deleteCustomVMTypesMutex.RUnlock()
 	fake.deleteVMExtensionMutex.RLock()
 	defer fake.deleteVMExtensionMutex.RUnlock()
+	fake.getStagedDirectorAvailabilityZonesMutex.RLock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.RUnlock()
+	fake.getStagedDirectorIaasConfigurationsMutex.RLock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.RUnlock()
+	fake.getStagedDirectorNetworksMutex.RLock()
+	defer fake.getStagedDirectorNetworksMutex.RUnlock()
+	fake.getStagedDirectorPropertiesMutex.RLock()
+	defer fake.getStagedDirectorPropertiesMutex.RUnlock()
 	fake.getStagedProductByNameMutex.RLock()
 	defer fake.getStagedProductByNameMutex.RUnlock()
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
 	fake.getStagedProductManifestMutex.RLock()
 	defer fake.getStagedProductManifestMutex.RUnlock()
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
 	fake.infoMutex.RLock()
 	defer fake.infoMutex.RUnlock()
 	fake.listInstallationsMutex.RLock()
 	defer fake.listInstallationsMutex.RUnlock()
+	fake.listStagedProductJobsMutex.RLock()
+	defer fake.listStagedProductJobsMutex.RUnlock()
 	fake.listStagedVMExtensionsMutex.RLock()
 	defer fake.listStagedVMExtensionsMutex.RUnlock()
 	fake.listVMTypesMutex.RLock()
@@ -20,6 +20,97 @@ type ConfigureProductService struct {
 	configureJobResourceConfigReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetStagedProductByNameStub        func(string) (api.StagedProductsFindOutput, error)
+	getStagedProductByNameMutex       sync.RWMutex
+	getStagedProductByNameArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductByNameReturns struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}
+	getStagedProductByNameReturnsOnCall map[int]struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}
+	GetStagedProductJobMaxInFlightStub        func(string) (map[string]interface{}, error)
+	getStagedProductJobMaxInFlightMutex       sync.RWMutex
+	getStagedProductJobMaxInFlightArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductJobMaxInFlightReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedProductJobMaxInFlightReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	GetStagedProductJobResourceConfigStub        func(string, string) (api.JobProperties, error)
+	getStagedProductJobResourceConfigMutex       sync.RWMutex
+	getStagedProductJobResourceConfigArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getStagedProductJobResourceConfigReturns struct {
+		result1 api.JobProperties
+		result2 error
+	}
+	getStagedProductJobResourceConfigReturnsOnCall map[int]struct {
+		result1 api.JobProperties
+		result2 error
+	}
+	GetStagedProductNetworksAndAZsStub        func(string) (map[string]interface{}, error)
+	getStagedProductNetworksAndAZsMutex       sync.RWMutex
+	getStagedProductNetworksAndAZsArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductNetworksAndAZsReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedProductNetworksAndAZsReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	GetStagedProductPropertiesStub        func(string) (map[string]api.ResponseProperty, error)
+	getStagedProductPropertiesMutex       sync.RWMutex
+	getStagedProductPropertiesArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductPropertiesReturns struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}
+	getStagedProductPropertiesReturnsOnCall map[int]struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}
+	GetStagedProductSyslogConfigurationStub        func(string) (map[string]interface{}, error)
+	getStagedProductSyslogConfigurationMutex       sync.RWMutex
+	getStagedProductSyslogConfigurationArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductSyslogConfigurationReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedProductSyslogConfigurationReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	InfoStub        func() (api.Info, error)
+	infoMutex       sync.RWMutex
+	infoArgsForCall []struct {
+	}
+	infoReturns struct {
+		result1 api.Info
+		result2 error
+	}
+	infoReturnsOnCall map[int]struct {
+		result1 api.Info
+		result2 error
+	}
 	ListInstallationsStub        func() ([]api.InstallationsServiceOutput, error)
 	listInstallationsMutex       sync.RWMutex
 	listInstallationsArgsForCall []struct {
@@ -44,6 +135,19 @@ type ConfigureProductService struct {
 		result1 api.PendingChangesOutput
 		result2 error
 	}
+	ListStagedProductErrandsStub        func(string) (api.ErrandsListOutput, error)
+	listStagedProductErrandsMutex       sync.RWMutex
+	listStagedProductErrandsArgsForCall []struct {
+		arg1 string
+	}
+	listStagedProductErrandsReturns struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}
+	listStagedProductErrandsReturnsOnCall map[int]struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}
 	ListStagedProductJobsStub        func(string) (map[string]string, error)
 	listStagedProductJobsMutex       sync.RWMutex
 	listStagedProductJobsArgsForCall []struct {
@@ -139,15 +243,16 @@ func (fake *ConfigureProductService) ConfigureJobResourceConfig(arg1 string, arg
 		arg1 string
 		arg2 map[string]interface{}
 	}{arg1, arg2})
+	stub := fake.ConfigureJobResourceConfigStub
+	fakeReturns := fake.configureJobResourceConfigReturns
 	fake.recordInvocation("ConfigureJobResourceConfig", []interface{}{arg1, arg2})
 	fake.configureJobResourceConfigMutex.Unlock()
-	if fake.ConfigureJobResourceConfigStub != nil {
-		return fake.ConfigureJobResourceConfigStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.configureJobResourceConfigReturns
 	return fakeReturns.result1
 }
 
@@ -193,20 +298,462 @@ func (fake *ConfigureProductService) ConfigureJobResourceConfigReturnsOnCall(i i
 	}{result1}
 }
 
+func (fake *ConfigureProductService) GetStagedProductByName(arg1 string) (api.StagedProductsFindOutput, error) {
+	fake.getStagedProductByNameMutex.Lock()
+	ret, specificReturn := fake.getStagedProductByNameReturnsOnCall[len(fake.getStagedProductByNameArgsForCall)]
+	fake.getStagedProductByNameArgsForCall = append(fake.getStagedProductByNameArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductByNameStub
+	fakeReturns := fake.getStagedProductByNameReturns
+	fake.recordInvocation("GetStagedProductByName", []interface{}{arg1})
+	fake.getStagedProductByNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) GetStagedProductByNameCallCount() int {
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	return len(fake.getStagedProductByNameArgsForCall)
+}
+
+func (fake *ConfigureProductService) GetStagedProductByNameCalls(stub func(string) (api.StagedProductsFindOutput, error)) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = stub
+}
+
+func (fake *ConfigureProductService) GetStagedProductByNameArgsForCall(i int) string {
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	argsForCall := fake.getStagedProductByNameArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureProductService) GetStagedProductByNameReturns(result1 api.StagedProductsFindOutput, result2 error) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = nil
+	fake.getStagedProductByNameReturns = struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductByNameReturnsOnCall(i int, result1 api.StagedProductsFindOutput, result2 error) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = nil
+	if fake.getStagedProductByNameReturnsOnCall == nil {
+		fake.getStagedProductByNameReturnsOnCall = make(map[int]struct {
+			result1 api.StagedProductsFindOutput
+			result2 error
+		})
+	}
+	fake.getStagedProductByNameReturnsOnCall[i] = struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobMaxInFlight(arg1 string) (map[string]interface{}, error) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	ret, specificReturn := fake.getStagedProductJobMaxInFlightReturnsOnCall[len(fake.getStagedProductJobMaxInFlightArgsForCall)]
+	fake.getStagedProductJobMaxInFlightArgsForCall = append(fake.getStagedProductJobMaxInFlightArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductJobMaxInFlightStub
+	fakeReturns := fake.getStagedProductJobMaxInFlightReturns
+	fake.recordInvocation("GetStagedProductJobMaxInFlight", []interface{}{arg1})
+	fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobMaxInFlightCallCount() int {
+	fake.getStagedProductJobMaxInFlightMutex.RLock()
+	defer fake.getStagedProductJobMaxInFlightMutex.RUnlock()
+	return len(fake.getStagedProductJobMaxInFlightArgsForCall)
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobMaxInFlightCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	defer fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	fake.GetStagedProductJobMaxInFlightStub = stub
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobMaxInFlightArgsForCall(i int) string {
+	fake.getStagedProductJobMaxInFlightMutex.RLock()
+	defer fake.getStagedProductJobMaxInFlightMutex.RUnlock()
+	argsForCall := fake.getStagedProductJobMaxInFlightArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobMaxInFlightReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	defer fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	fake.GetStagedProductJobMaxInFlightStub = nil
+	fake.getStagedProductJobMaxInFlightReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobMaxInFlightReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	defer fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	fake.GetStagedProductJobMaxInFlightStub = nil
+	if fake.getStagedProductJobMaxInFlightReturnsOnCall == nil {
+		fake.getStagedProductJobMaxInFlightReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedProductJobMaxInFlightReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobResourceConfig(arg1 string, arg2 string) (api.JobProperties, error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	ret, specificReturn := fake.getStagedProductJobResourceConfigReturnsOnCall[len(fake.getStagedProductJobResourceConfigArgsForCall)]
+	fake.getStagedProductJobResourceConfigArgsForCall = append(fake.getStagedProductJobResourceConfigArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetStagedProductJobResourceConfigStub
+	fakeReturns := fake.getStagedProductJobResourceConfigReturns
+	fake.recordInvocation("GetStagedProductJobResourceConfig", []interface{}{arg1, arg2})
+	fake.getStagedProductJobResourceConfigMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobResourceConfigCallCount() int {
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	return len(fake.getStagedProductJobResourceConfigArgsForCall)
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobResourceConfigCalls(stub func(string, string) (api.JobProperties, error)) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = stub
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobResourceConfigArgsForCall(i int) (string, string) {
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	argsForCall := fake.getStagedProductJobResourceConfigArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobResourceConfigReturns(result1 api.JobProperties, result2 error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = nil
+	fake.getStagedProductJobResourceConfigReturns = struct {
+		result1 api.JobProperties
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductJobResourceConfigReturnsOnCall(i int, result1 api.JobProperties, result2 error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = nil
+	if fake.getStagedProductJobResourceConfigReturnsOnCall == nil {
+		fake.getStagedProductJobResourceConfigReturnsOnCall = make(map[int]struct {
+			result1 api.JobProperties
+			result2 error
+		})
+	}
+	fake.getStagedProductJobResourceConfigReturnsOnCall[i] = struct {
+		result1 api.JobProperties
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductNetworksAndAZs(arg1 string) (map[string]interface{}, error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	ret, specificReturn := fake.getStagedProductNetworksAndAZsReturnsOnCall[len(fake.getStagedProductNetworksAndAZsArgsForCall)]
+	fake.getStagedProductNetworksAndAZsArgsForCall = append(fake.getStagedProductNetworksAndAZsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductNetworksAndAZsStub
+	fakeReturns := fake.getStagedProductNetworksAndAZsReturns
+	fake.recordInvocation("GetStagedProductNetworksAndAZs", []interface{}{arg1})
+	fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) GetStagedProductNetworksAndAZsCallCount() int {
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	return len(fake.getStagedProductNetworksAndAZsArgsForCall)
+}
+
+func (fake *ConfigureProductService) GetStagedProductNetworksAndAZsCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = stub
+}
+
+func (fake *ConfigureProductService) GetStagedProductNetworksAndAZsArgsForCall(i int) string {
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	argsForCall := fake.getStagedProductNetworksAndAZsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureProductService) GetStagedProductNetworksAndAZsReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = nil
+	fake.getStagedProductNetworksAndAZsReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductNetworksAndAZsReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = nil
+	if fake.getStagedProductNetworksAndAZsReturnsOnCall == nil {
+		fake.getStagedProductNetworksAndAZsReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedProductNetworksAndAZsReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductProperties(arg1 string) (map[string]api.ResponseProperty, error) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	ret, specificReturn := fake.getStagedProductPropertiesReturnsOnCall[len(fake.getStagedProductPropertiesArgsForCall)]
+	fake.getStagedProductPropertiesArgsForCall = append(fake.getStagedProductPropertiesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductPropertiesStub
+	fakeReturns := fake.getStagedProductPropertiesReturns
+	fake.recordInvocation("GetStagedProductProperties", []interface{}{arg1})
+	fake.getStagedProductPropertiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) GetStagedProductPropertiesCallCount() int {
+	fake.getStagedProductPropertiesMutex.RLock()
+	defer fake.getStagedProductPropertiesMutex.RUnlock()
+	return len(fake.getStagedProductPropertiesArgsForCall)
+}
+
+func (fake *ConfigureProductService) GetStagedProductPropertiesCalls(stub func(string) (map[string]api.ResponseProperty, error)) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	defer fake.getStagedProductPropertiesMutex.Unlock()
+	fake.GetStagedProductPropertiesStub = stub
+}
+
+func (fake *ConfigureProductService) GetStagedProductPropertiesArgsForCall(i int) string {
+	fake.getStagedProductPropertiesMutex.RLock()
+	defer fake.getStagedProductPropertiesMutex.RUnlock()
+	argsForCall := fake.getStagedProductPropertiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureProductService) GetStagedProductPropertiesReturns(result1 map[string]api.ResponseProperty, result2 error) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	defer fake.getStagedProductPropertiesMutex.Unlock()
+	fake.GetStagedProductPropertiesStub = nil
+	fake.getStagedProductPropertiesReturns = struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductPropertiesReturnsOnCall(i int, result1 map[string]api.ResponseProperty, result2 error) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	defer fake.getStagedProductPropertiesMutex.Unlock()
+	fake.GetStagedProductPropertiesStub = nil
+	if fake.getStagedProductPropertiesReturnsOnCall == nil {
+		fake.getStagedProductPropertiesReturnsOnCall = make(map[int]struct {
+			result1 map[string]api.ResponseProperty
+			result2 error
+		})
+	}
+	fake.getStagedProductPropertiesReturnsOnCall[i] = struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductSyslogConfiguration(arg1 string) (map[string]interface{}, error) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	ret, specificReturn := fake.getStagedProductSyslogConfigurationReturnsOnCall[len(fake.getStagedProductSyslogConfigurationArgsForCall)]
+	fake.getStagedProductSyslogConfigurationArgsForCall = append(fake.getStagedProductSyslogConfigurationArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductSyslogConfigurationStub
+	fakeReturns := fake.getStagedProductSyslogConfigurationReturns
+	fake.recordInvocation("GetStagedProductSyslogConfiguration", []interface{}{arg1})
+	fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) GetStagedProductSyslogConfigurationCallCount() int {
+	fake.getStagedProductSyslogConfigurationMutex.RLock()
+	defer fake.getStagedProductSyslogConfigurationMutex.RUnlock()
+	return len(fake.getStagedProductSyslogConfigurationArgsForCall)
+}
+
+func (fake *ConfigureProductService) GetStagedProductSyslogConfigurationCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	defer fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	fake.GetStagedProductSyslogConfigurationStub = stub
+}
+
+func (fake *ConfigureProductService) GetStagedProductSyslogConfigurationArgsForCall(i int) string {
+	fake.getStagedProductSyslogConfigurationMutex.RLock()
+	defer fake.getStagedProductSyslogConfigurationMutex.RUnlock()
+	argsForCall := fake.getStagedProductSyslogConfigurationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureProductService) GetStagedProductSyslogConfigurationReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	defer fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	fake.GetStagedProductSyslogConfigurationStub = nil
+	fake.getStagedProductSyslogConfigurationReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) GetStagedProductSyslogConfigurationReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	defer fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	fake.GetStagedProductSyslogConfigurationStub = nil
+	if fake.getStagedProductSyslogConfigurationReturnsOnCall == nil {
+		fake.getStagedProductSyslogConfigurationReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedProductSyslogConfigurationReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) Info() (api.Info, error) {
+	fake.infoMutex.Lock()
+	ret, specificReturn := fake.infoReturnsOnCall[len(fake.infoArgsForCall)]
+	fake.infoArgsForCall = append(fake.infoArgsForCall, struct {
+	}{})
+	stub := fake.InfoStub
+	fakeReturns := fake.infoReturns
+	fake.recordInvocation("Info", []interface{}{})
+	fake.infoMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) InfoCallCount() int {
+	fake.infoMutex.RLock()
+	defer fake.infoMutex.RUnlock()
+	return len(fake.infoArgsForCall)
+}
+
+func (fake *ConfigureProductService) InfoCalls(stub func() (api.Info, error)) {
+	fake.infoMutex.Lock()
+	defer fake.infoMutex.Unlock()
+	fake.InfoStub = stub
+}
+
+func (fake *ConfigureProductService) InfoReturns(result1 api.Info, result2 error) {
+	fake.infoMutex.Lock()
+	defer fake.infoMutex.Unlock()
+	fake.InfoStub = nil
+	fake.infoReturns = struct {
+		result1 api.Info
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) InfoReturnsOnCall(i int, result1 api.Info, result2 error) {
+	fake.infoMutex.Lock()
+	defer fake.infoMutex.Unlock()
+	fake.InfoStub = nil
+	if fake.infoReturnsOnCall == nil {
+		fake.infoReturnsOnCall = make(map[int]struct {
+			result1 api.Info
+			result2 error
+		})
+	}
+	fake.infoReturnsOnCall[i] = struct {
+		result1 api.Info
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *ConfigureProductService) ListInstallations() ([]api.InstallationsServiceOutput, error) {
 	fake.listInstallationsMutex.Lock()
 	ret, specificReturn := fake.listInstallationsReturnsOnCall[len(fake.listInstallationsArgsForCall)]
 	fake.listInstallationsArgsForCall = append(fake.listInstallationsArgsForCall, struct {
 	}{})
+	stub := fake.ListInstallationsStub
+	fakeReturns := fake.listInstallationsReturns
 	fake.recordInvocation("ListInstallations", []interface{}{})
 	fake.listInstallationsMutex.Unlock()
-	if fake.ListInstallationsStub != nil {
-		return fake.ListInstallationsStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listInstallationsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -253,15 +800,16 @@ func (fake *ConfigureProductService) ListStagedPendingChanges() (api.PendingChan
 	ret, specificReturn := fake.listStagedPendingChangesReturnsOnCall[len(fake.listStagedPendingChangesArgsForCall)]
 	fake.listStagedPendingChangesArgsForCall = append(fake.listStagedPendingChangesArgsForCall, struct {
 	}{})
+	stub := fake.ListStagedPendingChangesStub
+	fakeReturns := fake.listStagedPendingChangesReturns
 	fake.recordInvocation("ListStagedPendingChanges", []interface{}{})
 	fake.listStagedPendingChangesMutex.Unlock()
-	if fake.ListStagedPendingChangesStub != nil {
-		return fake.ListStagedPendingChangesStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listStagedPendingChangesReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -303,21 +851,86 @@ func (fake *ConfigureProductService) ListStagedPendingChangesReturnsOnCall(i int
 	}{result1, result2}
 }
 
+func (fake *ConfigureProductService) ListStagedProductErrands(arg1 string) (api.ErrandsListOutput, error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	ret, specificReturn := fake.listStagedProductErrandsReturnsOnCall[len(fake.listStagedProductErrandsArgsForCall)]
+	fake.listStagedProductErrandsArgsForCall = append(fake.listStagedProductErrandsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListStagedProductErrandsStub
+	fakeReturns := fake.listStagedProductErrandsReturns
+	fake.recordInvocation("ListStagedProductErrands", []interface{}{arg1})
+	fake.listStagedProductErrandsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConfigureProductService) ListStagedProductErrandsCallCount() int {
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	return len(fake.listStagedProductErrandsArgsForCall)
+}
+
+func (fake *ConfigureProductService) ListStagedProductErrandsCalls(stub func(string) (api.ErrandsListOutput, error)) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = stub
+}
+
+func (fake *ConfigureProductService) ListStagedProductErrandsArgsForCall(i int) string {
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	argsForCall := fake.listStagedProductErrandsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConfigureProductService) ListStagedProductErrandsReturns(result1 api.ErrandsListOutput, result2 error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = nil
+	fake.listStagedProductErrandsReturns = struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConfigureProductService) ListStagedProductErrandsReturnsOnCall(i int, result1 api.ErrandsListOutput, result2 error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = nil
+	if fake.listStagedProductErrandsReturnsOnCall == nil {
+		fake.listStagedProductErrandsReturnsOnCall = make(map[int]struct {
+			result1 api.ErrandsListOutput
+			result2 error
+		})
+	}
+	fake.listStagedProductErrandsReturnsOnCall[i] = struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *ConfigureProductService) ListStagedProductJobs(arg1 string) (map[string]string, error) {
 	fake.listStagedProductJobsMutex.Lock()
 	ret, specificReturn := fake.listStagedProductJobsReturnsOnCall[len(fake.listStagedProductJobsArgsForCall)]
 	fake.listStagedProductJobsArgsForCall = append(fake.listStagedProductJobsArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.ListStagedProductJobsStub
+	fakeReturns := fake.listStagedProductJobsReturns
 	fake.recordInvocation("ListStagedProductJobs", []interface{}{arg1})
 	fake.listStagedProductJobsMutex.Unlock()
-	if fake.ListStagedProductJobsStub != nil {
-		return fake.ListStagedProductJobsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listStagedProductJobsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -371,15 +984,16 @@ func (fake *ConfigureProductService) ListStagedProducts() (api.StagedProductsOut
 	ret, specificReturn := fake.listStagedProductsReturnsOnCall[len(fake.listStagedProductsArgsForCall)]
 	fake.listStagedProductsArgsForCall = append(fake.listStagedProductsArgsForCall, struct {
 	}{})
+	stub := fake.ListStagedProductsStub
+	fakeReturns := fake.listStagedProductsReturns
 	fake.recordInvocation("ListStagedProducts", []interface{}{})
 	fake.listStagedProductsMutex.Unlock()
-	if fake.ListStagedProductsStub != nil {
-		return fake.ListStagedProductsStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listStagedProductsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -430,15 +1044,16 @@ func (fake *ConfigureProductService) UpdateStagedProductErrands(arg1 string, arg
 		arg3 interface{}
 		arg4 interface{}
 	}{arg1, arg2, arg3, arg4})
+	stub := fake.UpdateStagedProductErrandsStub
+	fakeReturns := fake.updateStagedProductErrandsReturns
 	fake.recordInvocation("UpdateStagedProductErrands", []interface{}{arg1, arg2, arg3, arg4})
 	fake.updateStagedProductErrandsMutex.Unlock()
-	if fake.UpdateStagedProductErrandsStub != nil {
-		return fake.UpdateStagedProductErrandsStub(arg1, arg2, arg3, arg4)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedProductErrandsReturns
 	return fakeReturns.result1
 }
 
@@ -491,15 +1106,16 @@ func (fake *ConfigureProductService) UpdateStagedProductJobMaxInFlight(arg1 stri
 		arg1 string
 		arg2 map[string]interface{}
 	}{arg1, arg2})
+	stub := fake.UpdateStagedProductJobMaxInFlightStub
+	fakeReturns := fake.updateStagedProductJobMaxInFlightReturns
 	fake.recordInvocation("UpdateStagedProductJobMaxInFlight", []interface{}{arg1, arg2})
 	fake.updateStagedProductJobMaxInFlightMutex.Unlock()
-	if fake.UpdateStagedProductJobMaxInFlightStub != nil {
-		return fake.UpdateStagedProductJobMaxInFlightStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedProductJobMaxInFlightReturns
 	return fakeReturns.result1
 }
 
@@ -551,15 +1167,16 @@ func (fake *ConfigureProductService) UpdateStagedProductNetworksAndAZs(arg1 api.
 	fake.updateStagedProductNetworksAndAZsArgsForCall = append(fake.updateStagedProductNetworksAndAZsArgsForCall, struct {
 		arg1 api.UpdateStagedProductNetworksAndAZsInput
 	}{arg1})
+	stub := fake.UpdateStagedProductNetworksAndAZsStub
+	fakeReturns := fake.updateStagedProductNetworksAndAZsReturns
 	fake.recordInvocation("UpdateStagedProductNetworksAndAZs", []interface{}{arg1})
 	fake.updateStagedProductNetworksAndAZsMutex.Unlock()
-	if fake.UpdateStagedProductNetworksAndAZsStub != nil {
-		return fake.UpdateStagedProductNetworksAndAZsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedProductNetworksAndAZsReturns
 	return fakeReturns.result1
 }
 
@@ -611,15 +1228,16 @@ func (fake *ConfigureProductService) UpdateStagedProductProperties(arg1 api.Upda
 	fake.updateStagedProductPropertiesArgsForCall = append(fake.updateStagedProductPropertiesArgsForCall, struct {
 		arg1 api.UpdateStagedProductPropertiesInput
 	}{arg1})
+	stub := fake.UpdateStagedProductPropertiesStub
+	fakeReturns := fake.updateStagedProductPropertiesReturns
 	fake.recordInvocation("UpdateStagedProductProperties", []interface{}{arg1})
 	fake.updateStagedProductPropertiesMutex.Unlock()
-	if fake.UpdateStagedProductPropertiesStub != nil {
-		return fake.UpdateStagedProductPropertiesStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateStagedProductPropertiesReturns
 	return fakeReturns.result1
 }
 
@@ -671,15 +1289,16 @@ func (fake *ConfigureProductService) UpdateSyslogConfiguration(arg1 api.UpdateSy
 	fake.updateSyslogConfigurationArgsForCall = append(fake.updateSyslogConfigurationArgsForCall, struct {
 		arg1 api.UpdateSyslogConfigurationInput
 	}{arg1})
+	stub := fake.UpdateSyslogConfigurationStub
+	fakeReturns := fake.updateSyslogConfigurationReturns
 	fake.recordInvocation("UpdateSyslogConfiguration", []interface{}{arg1})
 	fake.updateSyslogConfigurationMutex.Unlock()
-	if fake.UpdateSyslogConfigurationStub != nil {
-		return fake.UpdateSyslogConfigurationStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateSyslogConfigurationReturns
 	return fakeReturns.result1
 }
 
@@ -730,10 +1349,26 @@ func (fake *ConfigureProductService) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.configureJobResourceConfigMutex.RLock()
 	defer fake.configureJobResourceConfigMutex.RUnlock()
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	fake.getStagedProductJobMaxInFlightMutex.RLock()
+	defer fake.getStagedProductJobMaxInFlightMutex.RUnlock()
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	fake.getStagedProductPropertiesMutex.RLock()
+	defer fake.getStagedProductPropertiesMutex.RUnlock()
+	fake.getStagedProductSyslogConfigurationMutex.RLock()
+	defer fake.getStagedProductSyslogConfigurationMutex.RUnlock()
+	fake.infoMutex.RLock()
+	defer fake.infoMutex.RUnlock()
 	fake.listInstallationsMutex.RLock()
 	defer fake.listInstallationsMutex.RUnlock()
 	fake.listStagedPendingChangesMutex.RLock()
 	defer fake.listStagedPendingChangesMutex.RUnlock()
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
 	fake.listStagedProductJobsMutex.RLock()
 	defer fake.listStagedProductJobsMutex.RUnlock()
 	fake.listStagedProductsMutex.RLock()
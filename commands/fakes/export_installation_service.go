@@ -2,6 +2,7 @@
 package fakes
 
 import (
+	"io"
 	"sync"
 )
 
@@ -17,6 +18,20 @@ type ExportInstallationService struct {
 	downloadInstallationAssetCollectionReturnsOnCall map[int]struct {
 		result1 error
 	}
+	OpenInstallationAssetCollectionStub        func() (io.ReadCloser, int64, error)
+	openInstallationAssetCollectionMutex       sync.RWMutex
+	openInstallationAssetCollectionArgsForCall []struct {
+	}
+	openInstallationAssetCollectionReturns struct {
+		result1 io.ReadCloser
+		result2 int64
+		result3 error
+	}
+	openInstallationAssetCollectionReturnsOnCall map[int]struct {
+		result1 io.ReadCloser
+		result2 int64
+		result3 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -27,15 +42,16 @@ func (fake *ExportInstallationService) DownloadInstallationAssetCollection(arg1
 	fake.downloadInstallationAssetCollectionArgsForCall = append(fake.downloadInstallationAssetCollectionArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.DownloadInstallationAssetCollectionStub
+	fakeReturns := fake.downloadInstallationAssetCollectionReturns
 	fake.recordInvocation("DownloadInstallationAssetCollection", []interface{}{arg1})
 	fake.downloadInstallationAssetCollectionMutex.Unlock()
-	if fake.DownloadInstallationAssetCollectionStub != nil {
-		return fake.DownloadInstallationAssetCollectionStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.downloadInstallationAssetCollectionReturns
 	return fakeReturns.result1
 }
 
@@ -81,11 +97,72 @@ func (fake *ExportInstallationService) DownloadInstallationAssetCollectionReturn
 	}{result1}
 }
 
+func (fake *ExportInstallationService) OpenInstallationAssetCollection() (io.ReadCloser, int64, error) {
+	fake.openInstallationAssetCollectionMutex.Lock()
+	ret, specificReturn := fake.openInstallationAssetCollectionReturnsOnCall[len(fake.openInstallationAssetCollectionArgsForCall)]
+	fake.openInstallationAssetCollectionArgsForCall = append(fake.openInstallationAssetCollectionArgsForCall, struct {
+	}{})
+	stub := fake.OpenInstallationAssetCollectionStub
+	fakeReturns := fake.openInstallationAssetCollectionReturns
+	fake.recordInvocation("OpenInstallationAssetCollection", []interface{}{})
+	fake.openInstallationAssetCollectionMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *ExportInstallationService) OpenInstallationAssetCollectionCallCount() int {
+	fake.openInstallationAssetCollectionMutex.RLock()
+	defer fake.openInstallationAssetCollectionMutex.RUnlock()
+	return len(fake.openInstallationAssetCollectionArgsForCall)
+}
+
+func (fake *ExportInstallationService) OpenInstallationAssetCollectionCalls(stub func() (io.ReadCloser, int64, error)) {
+	fake.openInstallationAssetCollectionMutex.Lock()
+	defer fake.openInstallationAssetCollectionMutex.Unlock()
+	fake.OpenInstallationAssetCollectionStub = stub
+}
+
+func (fake *ExportInstallationService) OpenInstallationAssetCollectionReturns(result1 io.ReadCloser, result2 int64, result3 error) {
+	fake.openInstallationAssetCollectionMutex.Lock()
+	defer fake.openInstallationAssetCollectionMutex.Unlock()
+	fake.OpenInstallationAssetCollectionStub = nil
+	fake.openInstallationAssetCollectionReturns = struct {
+		result1 io.ReadCloser
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *ExportInstallationService) OpenInstallationAssetCollectionReturnsOnCall(i int, result1 io.ReadCloser, result2 int64, result3 error) {
+	fake.openInstallationAssetCollectionMutex.Lock()
+	defer fake.openInstallationAssetCollectionMutex.Unlock()
+	fake.OpenInstallationAssetCollectionStub = nil
+	if fake.openInstallationAssetCollectionReturnsOnCall == nil {
+		fake.openInstallationAssetCollectionReturnsOnCall = make(map[int]struct {
+			result1 io.ReadCloser
+			result2 int64
+			result3 error
+		})
+	}
+	fake.openInstallationAssetCollectionReturnsOnCall[i] = struct {
+		result1 io.ReadCloser
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *ExportInstallationService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.downloadInstallationAssetCollectionMutex.RLock()
 	defer fake.downloadInstallationAssetCollectionMutex.RUnlock()
+	fake.openInstallationAssetCollectionMutex.RLock()
+	defer fake.openInstallationAssetCollectionMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
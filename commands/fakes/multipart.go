@@ -2,6 +2,7 @@
 package fakes
 
 import (
+	"io"
 	"sync"
 
 	"github.com/pivotal-cf/om/formcontent"
@@ -32,6 +33,20 @@ type Multipart struct {
 	addFileReturnsOnCall map[int]struct {
 		result1 error
 	}
+	AddReaderStub        func(string, string, io.Reader, int64) error
+	addReaderMutex       sync.RWMutex
+	addReaderArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 io.Reader
+		arg4 int64
+	}
+	addReaderReturns struct {
+		result1 error
+	}
+	addReaderReturnsOnCall map[int]struct {
+		result1 error
+	}
 	FinalizeStub        func() formcontent.ContentSubmission
 	finalizeMutex       sync.RWMutex
 	finalizeArgsForCall []struct {
@@ -57,15 +72,16 @@ func (fake *Multipart) AddField(arg1 string, arg2 string) error {
 		arg1 string
 		arg2 string
 	}{arg1, arg2})
+	stub := fake.AddFieldStub
+	fakeReturns := fake.addFieldReturns
 	fake.recordInvocation("AddField", []interface{}{arg1, arg2})
 	fake.addFieldMutex.Unlock()
-	if fake.AddFieldStub != nil {
-		return fake.AddFieldStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.addFieldReturns
 	return fakeReturns.result1
 }
 
@@ -118,15 +134,16 @@ func (fake *Multipart) AddFile(arg1 string, arg2 string) error {
 		arg1 string
 		arg2 string
 	}{arg1, arg2})
+	stub := fake.AddFileStub
+	fakeReturns := fake.addFileReturns
 	fake.recordInvocation("AddFile", []interface{}{arg1, arg2})
 	fake.addFileMutex.Unlock()
-	if fake.AddFileStub != nil {
-		return fake.AddFileStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.addFileReturns
 	return fakeReturns.result1
 }
 
@@ -172,20 +189,85 @@ func (fake *Multipart) AddFileReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Multipart) AddReader(arg1 string, arg2 string, arg3 io.Reader, arg4 int64) error {
+	fake.addReaderMutex.Lock()
+	ret, specificReturn := fake.addReaderReturnsOnCall[len(fake.addReaderArgsForCall)]
+	fake.addReaderArgsForCall = append(fake.addReaderArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 io.Reader
+		arg4 int64
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.AddReaderStub
+	fakeReturns := fake.addReaderReturns
+	fake.recordInvocation("AddReader", []interface{}{arg1, arg2, arg3, arg4})
+	fake.addReaderMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Multipart) AddReaderCallCount() int {
+	fake.addReaderMutex.RLock()
+	defer fake.addReaderMutex.RUnlock()
+	return len(fake.addReaderArgsForCall)
+}
+
+func (fake *Multipart) AddReaderCalls(stub func(string, string, io.Reader, int64) error) {
+	fake.addReaderMutex.Lock()
+	defer fake.addReaderMutex.Unlock()
+	fake.AddReaderStub = stub
+}
+
+func (fake *Multipart) AddReaderArgsForCall(i int) (string, string, io.Reader, int64) {
+	fake.addReaderMutex.RLock()
+	defer fake.addReaderMutex.RUnlock()
+	argsForCall := fake.addReaderArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Multipart) AddReaderReturns(result1 error) {
+	fake.addReaderMutex.Lock()
+	defer fake.addReaderMutex.Unlock()
+	fake.AddReaderStub = nil
+	fake.addReaderReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Multipart) AddReaderReturnsOnCall(i int, result1 error) {
+	fake.addReaderMutex.Lock()
+	defer fake.addReaderMutex.Unlock()
+	fake.AddReaderStub = nil
+	if fake.addReaderReturnsOnCall == nil {
+		fake.addReaderReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addReaderReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Multipart) Finalize() formcontent.ContentSubmission {
 	fake.finalizeMutex.Lock()
 	ret, specificReturn := fake.finalizeReturnsOnCall[len(fake.finalizeArgsForCall)]
 	fake.finalizeArgsForCall = append(fake.finalizeArgsForCall, struct {
 	}{})
+	stub := fake.FinalizeStub
+	fakeReturns := fake.finalizeReturns
 	fake.recordInvocation("Finalize", []interface{}{})
 	fake.finalizeMutex.Unlock()
-	if fake.FinalizeStub != nil {
-		return fake.FinalizeStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.finalizeReturns
 	return fakeReturns.result1
 }
 
@@ -228,9 +310,10 @@ func (fake *Multipart) Reset() {
 	fake.resetMutex.Lock()
 	fake.resetArgsForCall = append(fake.resetArgsForCall, struct {
 	}{})
+	stub := fake.ResetStub
 	fake.recordInvocation("Reset", []interface{}{})
 	fake.resetMutex.Unlock()
-	if fake.ResetStub != nil {
+	if stub != nil {
 		fake.ResetStub()
 	}
 }
@@ -254,6 +337,8 @@ func (fake *Multipart) Invocations() map[string][][]interface{} {
 	defer fake.addFieldMutex.RUnlock()
 	fake.addFileMutex.RLock()
 	defer fake.addFileMutex.RUnlock()
+	fake.addReaderMutex.RLock()
+	defer fake.addReaderMutex.RUnlock()
 	fake.finalizeMutex.RLock()
 	defer fake.finalizeMutex.RUnlock()
 	fake.resetMutex.RLock()
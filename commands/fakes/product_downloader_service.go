@@ -5,6 +5,7 @@ import (
 	"os"
 	"sync"
 
+	version "github.com/hashicorp/go-version"
 	"github.com/pivotal-cf/om/commands"
 )
 
@@ -49,11 +50,12 @@ type ProductDownloader struct {
 		result1 commands.FileArtifacter
 		result2 error
 	}
-	GetLatestStemcellForProductStub        func(commands.FileArtifacter, string) (commands.StemcellArtifacter, error)
+	GetLatestStemcellForProductStub        func(commands.FileArtifacter, string, version.Constraints) (commands.StemcellArtifacter, error)
 	getLatestStemcellForProductMutex       sync.RWMutex
 	getLatestStemcellForProductArgsForCall []struct {
 		arg1 commands.FileArtifacter
 		arg2 string
+		arg3 version.Constraints
 	}
 	getLatestStemcellForProductReturns struct {
 		result1 commands.StemcellArtifacter
@@ -84,15 +86,16 @@ func (fake *ProductDownloader) DownloadProductToFile(arg1 commands.FileArtifacte
 		arg1 commands.FileArtifacter
 		arg2 *os.File
 	}{arg1, arg2})
+	stub := fake.DownloadProductToFileStub
+	fakeReturns := fake.downloadProductToFileReturns
 	fake.recordInvocation("DownloadProductToFile", []interface{}{arg1, arg2})
 	fake.downloadProductToFileMutex.Unlock()
-	if fake.DownloadProductToFileStub != nil {
-		return fake.DownloadProductToFileStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.downloadProductToFileReturns
 	return fakeReturns.result1
 }
 
@@ -144,15 +147,16 @@ func (fake *ProductDownloader) GetAllProductVersions(arg1 string) ([]string, err
 	fake.getAllProductVersionsArgsForCall = append(fake.getAllProductVersionsArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetAllProductVersionsStub
+	fakeReturns := fake.getAllProductVersionsReturns
 	fake.recordInvocation("GetAllProductVersions", []interface{}{arg1})
 	fake.getAllProductVersionsMutex.Unlock()
-	if fake.GetAllProductVersionsStub != nil {
-		return fake.GetAllProductVersionsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getAllProductVersionsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -209,15 +213,16 @@ func (fake *ProductDownloader) GetLatestProductFile(arg1 string, arg2 string, ar
 		arg2 string
 		arg3 string
 	}{arg1, arg2, arg3})
+	stub := fake.GetLatestProductFileStub
+	fakeReturns := fake.getLatestProductFileReturns
 	fake.recordInvocation("GetLatestProductFile", []interface{}{arg1, arg2, arg3})
 	fake.getLatestProductFileMutex.Unlock()
-	if fake.GetLatestProductFileStub != nil {
-		return fake.GetLatestProductFileStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getLatestProductFileReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -266,22 +271,24 @@ func (fake *ProductDownloader) GetLatestProductFileReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
-func (fake *ProductDownloader) GetLatestStemcellForProduct(arg1 commands.FileArtifacter, arg2 string) (commands.StemcellArtifacter, error) {
+func (fake *ProductDownloader) GetLatestStemcellForProduct(arg1 commands.FileArtifacter, arg2 string, arg3 version.Constraints) (commands.StemcellArtifacter, error) {
 	fake.getLatestStemcellForProductMutex.Lock()
 	ret, specificReturn := fake.getLatestStemcellForProductReturnsOnCall[len(fake.getLatestStemcellForProductArgsForCall)]
 	fake.getLatestStemcellForProductArgsForCall = append(fake.getLatestStemcellForProductArgsForCall, struct {
 		arg1 commands.FileArtifacter
 		arg2 string
-	}{arg1, arg2})
-	fake.recordInvocation("GetLatestStemcellForProduct", []interface{}{arg1, arg2})
+		arg3 version.Constraints
+	}{arg1, arg2, arg3})
+	stub := fake.GetLatestStemcellForProductStub
+	fakeReturns := fake.getLatestStemcellForProductReturns
+	fake.recordInvocation("GetLatestStemcellForProduct", []interface{}{arg1, arg2, arg3})
 	fake.getLatestStemcellForProductMutex.Unlock()
-	if fake.GetLatestStemcellForProductStub != nil {
-		return fake.GetLatestStemcellForProductStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getLatestStemcellForProductReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -291,17 +298,17 @@ func (fake *ProductDownloader) GetLatestStemcellForProductCallCount() int {
 	return len(fake.getLatestStemcellForProductArgsForCall)
 }
 
-func (fake *ProductDownloader) GetLatestStemcellForProductCalls(stub func(commands.FileArtifacter, string) (commands.StemcellArtifacter, error)) {
+func (fake *ProductDownloader) GetLatestStemcellForProductCalls(stub func(commands.FileArtifacter, string, version.Constraints) (commands.StemcellArtifacter, error)) {
 	fake.getLatestStemcellForProductMutex.Lock()
 	defer fake.getLatestStemcellForProductMutex.Unlock()
 	fake.GetLatestStemcellForProductStub = stub
 }
 
-func (fake *ProductDownloader) GetLatestStemcellForProductArgsForCall(i int) (commands.FileArtifacter, string) {
+func (fake *ProductDownloader) GetLatestStemcellForProductArgsForCall(i int) (commands.FileArtifacter, string, version.Constraints) {
 	fake.getLatestStemcellForProductMutex.RLock()
 	defer fake.getLatestStemcellForProductMutex.RUnlock()
 	argsForCall := fake.getLatestStemcellForProductArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *ProductDownloader) GetLatestStemcellForProductReturns(result1 commands.StemcellArtifacter, result2 error) {
@@ -335,15 +342,16 @@ func (fake *ProductDownloader) Name() string {
 	ret, specificReturn := fake.nameReturnsOnCall[len(fake.nameArgsForCall)]
 	fake.nameArgsForCall = append(fake.nameArgsForCall, struct {
 	}{})
+	stub := fake.NameStub
+	fakeReturns := fake.nameReturns
 	fake.recordInvocation("Name", []interface{}{})
 	fake.nameMutex.Unlock()
-	if fake.NameStub != nil {
-		return fake.NameStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.nameReturns
 	return fakeReturns.result1
 }
 
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pivotal-cf/jhanda"
+)
+
+// ParseFlags is jhanda.Parse for options that embed other options, such as
+// BlobstoreFlags. jhanda only reads the fields of the struct it is given, so
+// the options are parsed into a struct with the embedded fields at the top
+// level, and copied back.
+func ParseFlags(receiver interface{}, args []string) ([]string, error) {
+	options := reflect.ValueOf(receiver)
+	if options.Kind() != reflect.Ptr || !embedsFlags(options.Elem()) {
+		return jhanda.Parse(receiver, args)
+	}
+
+	return parseFlattened(options.Elem(), args, false)
+}
+
+// parseCommandLineFlags is ParseFlags for the command line of a command
+// whose other flags are in its config file, so that a required flag need
+// not be on the command line.
+func parseCommandLineFlags(receiver interface{}, args []string) error {
+	_, err := parseFlattened(reflect.ValueOf(receiver).Elem(), args, true)
+	return err
+}
+
+func parseFlattened(options reflect.Value, args []string, optional bool) ([]string, error) {
+	flat := flattenFlags(options, optional)
+	rest, err := jhanda.Parse(flat.Addr().Interface(), args)
+
+	fields := flagFields(options)
+	for i, field := range fields {
+		field.Set(flat.Field(i))
+	}
+
+	return rest, err
+}
+
+// usageFlags gives jhanda.PrintUsage the options with the fields of embedded
+// options at the top level, so that they are listed with the others.
+func usageFlags(options interface{}) interface{} {
+	value := reflect.ValueOf(options)
+	if !embedsFlags(value) {
+		return options
+	}
+
+	return flattenFlags(value, false).Interface()
+}
+
+func embedsFlags(options reflect.Value) bool {
+	if options.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < options.NumField(); i++ {
+		if options.Type().Field(i).Anonymous && options.Field(i).Kind() == reflect.Struct {
+			return true
+		}
+	}
+	return false
+}
+
+func flattenFlags(options reflect.Value, optional bool) reflect.Value {
+	var structFields []reflect.StructField
+	for _, field := range flagStructFields(options.Type()) {
+		tag := field.Tag
+		if optional {
+			tag = reflect.StructTag(strings.Replace(string(tag), `required:"true"`, "", 1))
+		}
+
+		structFields = append(structFields, reflect.StructField{
+			Name: field.Name,
+			Type: field.Type,
+			Tag:  tag,
+		})
+	}
+
+	flat := reflect.New(reflect.StructOf(structFields)).Elem()
+	for i, field := range flagFields(options) {
+		flat.Field(i).Set(field)
+	}
+
+	return flat
+}
+
+func flagStructFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, flagStructFields(field.Type)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func flagFields(v reflect.Value) []reflect.Value {
+	var fields []reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Anonymous && v.Field(i).Kind() == reflect.Struct {
+			fields = append(fields, flagFields(v.Field(i))...)
+			continue
+		}
+		fields = append(fields, v.Field(i))
+	}
+	return fields
+}
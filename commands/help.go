@@ -119,7 +119,7 @@ func (h Help) buildCommandContext(command string) (TemplateContext, error) {
 		argsPlaceholder string
 	)
 	if usage.Flags != nil {
-		flagUsage, err := jhanda.PrintUsage(usage.Flags)
+		flagUsage, err := jhanda.PrintUsage(usageFlags(usage.Flags))
 		if err != nil {
 			return TemplateContext{}, err
 		}
@@ -46,6 +46,10 @@ Usage: om [options] bake
 `
 )
 
+type BakeLemonFlags struct {
+	Lemon int `short:"l" long:"lemon"  description:"teaspoons of lemon juice"`
+}
+
 var _ = Describe("Help", func() {
 	var (
 		output *bytes.Buffer
@@ -103,6 +107,28 @@ var _ = Describe("Help", func() {
 				Expect(output.String()).To(ContainSubstring(COMMAND_USAGE))
 			})
 
+			It("prints the flags of embedded options with the others", func() {
+				bake := &fakeCommand{
+					usage: jhanda.Usage{
+						Description:      "This command will help you bake a cake.",
+						ShortDescription: "bakes you a cake",
+						Flags: struct {
+							Flour  int   `short:"f" long:"flour"  description:"cups of flour"`
+							Butter []int `short:"b" long:"butter" description:"sticks of butter"`
+							BakeLemonFlags
+						}{},
+					},
+				}
+
+				help := commands.NewHelp(output, strings.TrimSpace(flags), jhanda.CommandSet{"bake": bake})
+				err := help.Execute([]string{"bake"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(output.String()).To(ContainSubstring("--lemon, -l"))
+				Expect(output.String()).To(ContainSubstring("teaspoons of lemon juice"))
+				Expect(output.String()).To(ContainSubstring(COMMAND_USAGE))
+			})
+
 			When("the command does not exist", func() {
 				It("returns an error", func() {
 					help := commands.NewHelp(output, flags, jhanda.CommandSet{})
@@ -2,9 +2,11 @@ package commands
 
 import (
 	"archive/zip"
+	"bytes"
 	"fmt"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -17,10 +19,15 @@ type ImportInstallation struct {
 	logger     logger
 	service    importInstallationService
 	passphrase string
+	blobstore  Blobstore
 	Options    struct {
 		ConfigFile      string `long:"config"                short:"c"                  description:"path to yml file for configuration (keys must match the following command line flags)"`
-		Installation    string `long:"installation"          short:"i"  required:"true" description:"path to installation."`
+		Installation    string `long:"installation"          short:"i"  required:"true" description:"path to installation. With --blobstore, the name of the file in the bucket"`
 		PollingInterval int    `long:"polling-interval"      short:"pi"                 description:"interval (in seconds) to check OpsManager availability" default:"10"`
+		ValidateOnly    bool   `long:"validate-only"                                    description:"only check the installation can be imported, without contacting Ops Manager (see inspect-installation)"`
+
+		Blobstore string `long:"blobstore" description:"stream the installation from a blobstore instead of a local file, verifying its .sha256 checksum file if there is one (options: s3,gcs,azure)"`
+		BlobstoreFlags
 	}
 }
 
@@ -53,6 +60,10 @@ func (ii *ImportInstallation) Execute(args []string) error {
 		return err
 	}
 
+	if ii.Options.ValidateOnly {
+		return ii.validateOnly()
+	}
+
 	ensureAvailabilityOutput, err := ii.service.EnsureAvailability(api.EnsureAvailabilityInput{})
 	if err != nil {
 		return fmt.Errorf("could not check Ops Manager status: %s", err)
@@ -65,9 +76,17 @@ func (ii *ImportInstallation) Execute(args []string) error {
 
 	ii.logger.Printf("processing installation")
 
-	err = ii.multipart.AddFile("installation[file]", ii.Options.Installation)
-	if err != nil {
-		return fmt.Errorf("failed to load installation: %s", err)
+	if ii.blobstore != nil {
+		installation, err := ii.openFromBlobstore()
+		if err != nil {
+			return fmt.Errorf("failed to load installation: %s", err)
+		}
+		defer installation.Close()
+	} else {
+		err = ii.multipart.AddFile("installation[file]", ii.Options.Installation)
+		if err != nil {
+			return fmt.Errorf("failed to load installation: %s", err)
+		}
 	}
 
 	err = ii.multipart.AddField("passphrase", ii.passphrase)
@@ -103,6 +122,45 @@ func (ii *ImportInstallation) Execute(args []string) error {
 	return nil
 }
 
+func (ii ImportInstallation) validateOnly() error {
+	report := inspectInstallation(ii.Options.Installation, ii.passphrase)
+
+	var output bytes.Buffer
+	report.writeText(&output)
+	ii.logger.Print(output.String())
+
+	if !report.valid() {
+		return fmt.Errorf("installation %s is not valid and would fail to import", ii.Options.Installation)
+	}
+
+	ii.logger.Printf("the installation is valid and can be imported")
+	return nil
+}
+
+// openFromBlobstore adds the installation to the form as a stream from the
+// blobstore. When there is a checksum file next to it, a mismatch fails the
+// upload before the last of the installation is sent to Ops Manager.
+func (ii ImportInstallation) openFromBlobstore() (io.Closer, error) {
+	checksum, err := ReadBlobstoreChecksum(ii.blobstore, ii.Options.Installation)
+	if err != nil {
+		ii.logger.Printf("could not read %s, so the checksum of the installation will not be verified: %s", checksumFileName(ii.Options.Installation), err)
+		checksum = ""
+	}
+
+	installation, length, err := ii.blobstore.Open(ii.Options.Installation)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ii.multipart.AddReader("installation[file]", ii.Options.Installation, newChecksumReader(installation, checksum), length)
+	if err != nil {
+		installation.Close()
+		return nil, err
+	}
+
+	return installation, nil
+}
+
 func (ii ImportInstallation) ensureAvailability() error {
 	var tryCount int
 
@@ -134,6 +192,15 @@ func (ii *ImportInstallation) validate(args []string) error {
 		return fmt.Errorf("could not parse import-installation flags: %s", err)
 	}
 
+	if ii.Options.Blobstore != "" {
+		if ii.Options.ValidateOnly {
+			return fmt.Errorf("--validate-only cannot be used with --blobstore, as the installation has to be read from a local file")
+		}
+
+		ii.blobstore, err = newBlobstore(ii.Options.Blobstore, ii.Options.blobstoreOptions())
+		return err
+	}
+
 	if _, err := os.Stat(ii.Options.Installation); err != nil {
 		return fmt.Errorf("file: \"%s\" does not exist. Please check the name and try again.", ii.Options.Installation)
 	}
@@ -4,6 +4,7 @@ import (
 	"archive/zip"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
 
@@ -225,6 +226,173 @@ var _ = Describe("ImportInstallation", func() {
 		})
 	})
 
+	When("--blobstore is set", func() {
+		var (
+			blobstore *fakes.Blobstore
+			files     map[string]string
+		)
+
+		BeforeEach(func() {
+			files = map[string]string{
+				"backups/installation.zip":        "some-installation",
+				"backups/installation.zip.sha256": "0812c129f0c6b7946c94068b332c0f12e209f17ce60d384050fe8a3f139a28e1  installation.zip\n",
+			}
+
+			blobstore = &fakes.Blobstore{}
+			blobstore.OpenStub = func(name string) (io.ReadCloser, int64, error) {
+				contents, ok := files[name]
+				if !ok {
+					return nil, 0, errors.New("not found")
+				}
+				return ioutil.NopCloser(strings.NewReader(contents)), int64(len(contents)), nil
+			}
+			commands.RegisterBlobstore("gcs", func(commands.BlobstoreOptions) (commands.Blobstore, error) {
+				return blobstore, nil
+			})
+
+			fakeService.EnsureAvailabilityReturnsOnCall(0, api.EnsureAvailabilityOutput{Status: api.EnsureAvailabilityStatusUnstarted}, nil)
+			fakeService.EnsureAvailabilityReturnsOnCall(1, api.EnsureAvailabilityOutput{Status: api.EnsureAvailabilityStatusComplete}, nil)
+		})
+
+		importFromBlobstore := func() (string, error) {
+			command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+			err := command.Execute([]string{
+				"--polling-interval", "0",
+				"--installation", "backups/installation.zip",
+				"--blobstore", "gcs",
+				"--blobstore-bucket", "some-bucket",
+			})
+			if err != nil {
+				return "", err
+			}
+
+			Expect(multipart.AddFileCallCount()).To(Equal(0))
+			Expect(multipart.AddReaderCallCount()).To(Equal(1))
+
+			key, fileName, reader, length := multipart.AddReaderArgsForCall(0)
+			Expect(key).To(Equal("installation[file]"))
+			Expect(fileName).To(Equal("backups/installation.zip"))
+			Expect(length).To(Equal(int64(17)))
+
+			contents, err := ioutil.ReadAll(reader)
+			return string(contents), err
+		}
+
+		It("streams the installation from the bucket, verifying its checksum", func() {
+			contents, err := importFromBlobstore()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("some-installation"))
+		})
+
+		It("fails the upload when the checksum does not match", func() {
+			files["backups/installation.zip"] = "some-corrupt-inst"
+
+			_, err := importFromBlobstore()
+			Expect(err).To(MatchError(ContainSubstring("does not match the expected checksum (0812c129f0c6b7946c94068b332c0f12e209f17ce60d384050fe8a3f139a28e1)")))
+		})
+
+		It("warns when there is no checksum file", func() {
+			delete(files, "backups/installation.zip.sha256")
+
+			contents, err := importFromBlobstore()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("some-installation"))
+
+			format, v := logger.PrintfArgsForCall(1)
+			Expect(fmt.Sprintf(format, v...)).To(Equal("could not read backups/installation.zip.sha256, so the checksum of the installation will not be verified: not found"))
+		})
+
+		When("the installation is not in the bucket", func() {
+			It("returns an error", func() {
+				delete(files, "backups/installation.zip")
+
+				_, err := importFromBlobstore()
+				Expect(err).To(MatchError("failed to load installation: not found"))
+			})
+		})
+	})
+
+	When("--validate-only is set", func() {
+		It("reports on the installation without contacting Ops Manager", func() {
+			validInstallation := createZipFile([]struct{ Name, Body string }{
+				{"installation.yml", string(encryptInstallation(installationSettings, "some-passphrase"))},
+			})
+			defer os.Remove(validInstallation)
+
+			command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+
+			err := command.Execute([]string{
+				"--installation", validInstallation,
+				"--validate-only",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.EnsureAvailabilityCallCount()).To(Equal(0))
+			Expect(fakeService.UploadInstallationAssetCollectionCallCount()).To(Equal(0))
+
+			Expect(fmt.Sprint(logger.PrintArgsForCall(0))).To(ContainSubstring("installation: " + validInstallation + "\n"))
+			Expect(fmt.Sprint(logger.PrintArgsForCall(0))).To(ContainSubstring("decryption passphrase: valid\n"))
+
+			format, v := logger.PrintfArgsForCall(0)
+			Expect(fmt.Sprintf(format, v...)).To(Equal("the installation is valid and can be imported"))
+		})
+
+		When("the installation is not valid", func() {
+			It("returns an error", func() {
+				invalidInstallation := createZipFile([]struct{ Name, Body string }{
+					{"installation.yml", string(encryptInstallation("not yaml: [", "some-passphrase"))},
+				})
+				defer os.Remove(invalidInstallation)
+
+				command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+
+				err := command.Execute([]string{
+					"--installation", invalidInstallation,
+					"--validate-only",
+				})
+				Expect(err).To(MatchError("installation " + invalidInstallation + " is not valid and would fail to import"))
+				Expect(fmt.Sprint(logger.PrintArgsForCall(0))).To(ContainSubstring("  - the decryption passphrase cannot decrypt installation.yml\n"))
+
+				Expect(fakeService.EnsureAvailabilityCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the passphrase cannot be checked", func() {
+			It("warns without failing", func() {
+				uncheckedInstallation := createZipFile([]struct{ Name, Body string }{
+					{"installation.yml", "not yaml: ["},
+				})
+				defer os.Remove(uncheckedInstallation)
+
+				command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+
+				err := command.Execute([]string{
+					"--installation", uncheckedInstallation,
+					"--validate-only",
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fmt.Sprint(logger.PrintArgsForCall(0))).To(ContainSubstring("decryption passphrase: not checked\n"))
+				Expect(fmt.Sprint(logger.PrintArgsForCall(0))).To(ContainSubstring("installation.yml is not in a format the decryption passphrase can be checked against"))
+				Expect(fakeService.UploadInstallationAssetCollectionCallCount()).To(Equal(0))
+			})
+		})
+
+		When("--blobstore is also set", func() {
+			It("returns an error", func() {
+				command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+
+				err := command.Execute([]string{
+					"--installation", "backups/installation.zip",
+					"--blobstore", "gcs",
+					"--blobstore-bucket", "some-bucket",
+					"--validate-only",
+				})
+				Expect(err).To(MatchError("--validate-only cannot be used with --blobstore, as the installation has to be read from a local file"))
+				Expect(fakeService.EnsureAvailabilityCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	When("EnsureAvailability returns 'connection refused'", func() {
 		var command *commands.ImportInstallation
 
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pivotal-cf/jhanda"
+)
+
+type InspectInstallation struct {
+	passphrase string
+	output     io.Writer
+	Options    struct {
+		Installation string `long:"installation" short:"i" required:"true" description:"path to installation"`
+		Format       string `long:"format"       short:"f" default:"text"  description:"Format to print as (options: text,json)"`
+	}
+}
+
+func NewInspectInstallation(passphrase string, output io.Writer) InspectInstallation {
+	return InspectInstallation{
+		passphrase: passphrase,
+		output:     output,
+	}
+}
+
+func (ii InspectInstallation) Execute(args []string) error {
+	if _, err := jhanda.Parse(&ii.Options, args); err != nil {
+		return fmt.Errorf("could not parse inspect-installation flags: %s", err)
+	}
+
+	if ii.Options.Format != "text" && ii.Options.Format != "json" {
+		return fmt.Errorf("unsupported format %q: must be one of text,json", ii.Options.Format)
+	}
+
+	report := inspectInstallation(ii.Options.Installation, ii.passphrase)
+
+	if ii.Options.Format == "json" {
+		err := report.writeJSON(ii.output)
+		if err != nil {
+			return err
+		}
+	} else {
+		report.writeText(ii.output)
+	}
+
+	if !report.valid() {
+		return fmt.Errorf("installation %s is not valid", ii.Options.Installation)
+	}
+
+	return nil
+}
+
+func (ii InspectInstallation) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command opens an exported installation without an Ops Manager and reports whether it is valid, its installation schema version and the products it came from, and whether the global decryption-passphrase can decrypt it.",
+		ShortDescription: "**EXPERIMENTAL** reports on an exported installation before it is imported",
+		Flags:            ii.Options,
+	}
+}
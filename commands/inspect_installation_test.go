@@ -0,0 +1,269 @@
+package commands_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/commands"
+)
+
+const installationSettings = `installation_schema_version: "2.7"
+products:
+- identifier: p-bosh
+  product_version: 2.7.3-build.185
+- identifier: cf
+  product_version: 2.7.4
+`
+
+// encryptInstallation encrypts like `openssl enc -aes-256-cbc -md sha256 -salt`,
+// with the same salt every time, so that decrypting it with another
+// passphrase always fails the same way.
+func encryptInstallation(plaintext, passphrase string) []byte {
+	salt := []byte("somesalt")
+
+	var derived, previous []byte
+	for len(derived) < 48 {
+		h := sha256.New()
+		h.Write(previous)
+		h.Write([]byte(passphrase))
+		h.Write(salt)
+		previous = h.Sum(nil)
+		derived = append(derived, previous...)
+	}
+
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append([]byte(plaintext), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	block, err := aes.NewCipher(derived[:32])
+	Expect(err).ToNot(HaveOccurred())
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, derived[32:48]).CryptBlocks(ciphertext, padded)
+
+	return append(append([]byte("Salted__"), salt...), ciphertext...)
+}
+
+func createInstallationZip(files map[string][]byte) string {
+	tmpFile, err := ioutil.TempFile("", "installation*.zip")
+	Expect(err).ToNot(HaveOccurred())
+	defer tmpFile.Close()
+
+	writer := zip.NewWriter(tmpFile)
+	for name, contents := range files {
+		f, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write(contents)
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+
+	return tmpFile.Name()
+}
+
+var _ = Describe("InspectInstallation", func() {
+	var (
+		output       *bytes.Buffer
+		installation string
+	)
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+		installation = createInstallationZip(map[string][]byte{
+			"installation.yml":              encryptInstallation(installationSettings, "some-passphrase"),
+			"metadata/cf.yml":               []byte("name: cf\nproduct_version: 2.7.4\n"),
+			"metadata/cf-2.6.yml":           []byte("name: cf\nproduct_version: 2.6.10\n"),
+			"deployments/bosh-state.json":   []byte(`{}`),
+			"deployments/cf-some-guid.yml":  []byte(`name: cf-some-guid`),
+			"metadata/p-isolation-segm.yml": []byte("name: p-isolation-segment\nproduct_version: 2.7.1\n"),
+		})
+	})
+
+	AfterEach(func() {
+		Expect(os.Remove(installation)).To(Succeed())
+	})
+
+	It("reports the installation schema version and deployed products when the passphrase decrypts the installation", func() {
+		command := commands.NewInspectInstallation("some-passphrase", output)
+
+		err := command.Execute([]string{"--installation", installation, "--format", "json"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(MatchJSON(`{
+			"installation": "` + installation + `",
+			"installation_schema_version": "2.7",
+			"products": [
+				{"name": "cf", "version": "2.7.4"},
+				{"name": "p-bosh", "version": "2.7.3-build.185"}
+			],
+			"decryption_passphrase": "valid",
+			"problems": [],
+			"warnings": []
+		}`))
+	})
+
+	It("prints the report as text", func() {
+		command := commands.NewInspectInstallation("some-passphrase", output)
+
+		err := command.Execute([]string{"--installation", installation})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(ContainSubstring("installation schema version: 2.7\n"))
+		Expect(output.String()).To(ContainSubstring("decryption passphrase: valid\n"))
+		Expect(output.String()).To(MatchRegexp(`\|\s+p-bosh\s+\|\s+2.7.3-build.185\s+\|`))
+		Expect(output.String()).ToNot(ContainSubstring("problems"))
+	})
+
+	When("the passphrase decrypts the installation into something that is not YAML", func() {
+		It("reports the products from the metadata and returns an error", func() {
+			garbled := createInstallationZip(map[string][]byte{
+				"installation.yml":                 encryptInstallation("not yaml: [", "some-passphrase"),
+				"metadata/cf.yml":                  []byte("name: cf\nproduct_version: 2.7.4\n"),
+				"metadata/p-isolation-segment.yml": []byte("name: p-isolation-segment\nproduct_version: 2.7.1\n"),
+			})
+			defer os.Remove(garbled)
+
+			command := commands.NewInspectInstallation("some-passphrase", output)
+
+			err := command.Execute([]string{"--installation", garbled, "--format", "json"})
+			Expect(err).To(MatchError("installation " + garbled + " is not valid"))
+
+			var report map[string]interface{}
+			Expect(json.Unmarshal(output.Bytes(), &report)).To(Succeed())
+			Expect(report["installation_schema_version"]).To(Equal("unknown"))
+			Expect(report["decryption_passphrase"]).To(Equal("invalid"))
+			Expect(report["products"]).To(Equal([]interface{}{
+				map[string]interface{}{"name": "cf", "version": "2.7.4"},
+				map[string]interface{}{"name": "p-isolation-segment", "version": "2.7.1"},
+			}))
+			Expect(report["problems"]).To(Equal([]interface{}{"the decryption passphrase cannot decrypt installation.yml"}))
+		})
+	})
+
+	When("the passphrase does not decrypt the installation", func() {
+		It("warns that it is either wrong or the installation was encrypted another way", func() {
+			command := commands.NewInspectInstallation("wrong-passphrase", output)
+
+			err := command.Execute([]string{"--installation", installation, "--format", "json"})
+			Expect(err).ToNot(HaveOccurred())
+
+			var report map[string]interface{}
+			Expect(json.Unmarshal(output.Bytes(), &report)).To(Succeed())
+			Expect(report["decryption_passphrase"]).To(Equal("not checked"))
+			Expect(report["products"]).To(Equal([]interface{}{
+				map[string]interface{}{"name": "cf", "version": "2.6.10"},
+				map[string]interface{}{"name": "cf", "version": "2.7.4"},
+				map[string]interface{}{"name": "p-isolation-segment", "version": "2.7.1"},
+			}))
+			Expect(report["problems"]).To(BeEmpty())
+			Expect(report["warnings"]).To(Equal([]interface{}{"the decryption passphrase did not decrypt installation.yml with the key derivations om knows, so either it is wrong or installation.yml was encrypted another way"}))
+		})
+	})
+
+	When("there is no passphrase", func() {
+		It("does not check it, and warns about it", func() {
+			command := commands.NewInspectInstallation("", output)
+
+			err := command.Execute([]string{"--installation", installation})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("decryption passphrase: not checked\n"))
+			Expect(output.String()).To(ContainSubstring("warnings:\n  - no decryption passphrase was provided, so it was not checked against installation.yml\n"))
+		})
+	})
+
+	When("installation.yml is not encrypted", func() {
+		It("reads it without the passphrase", func() {
+			plain := createInstallationZip(map[string][]byte{
+				"installation.yml": []byte(installationSettings),
+			})
+			defer os.Remove(plain)
+
+			command := commands.NewInspectInstallation("", output)
+
+			err := command.Execute([]string{"--installation", plain})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("installation schema version: 2.7\n"))
+			Expect(output.String()).To(ContainSubstring("decryption passphrase: not needed\n"))
+		})
+	})
+
+	When("the installation is missing installation.yml", func() {
+		It("returns an error", func() {
+			missing := createInstallationZip(map[string][]byte{
+				"metadata/cf.yml": []byte("name: cf\nproduct_version: 2.7.4\n"),
+			})
+			defer os.Remove(missing)
+
+			command := commands.NewInspectInstallation("some-passphrase", output)
+
+			err := command.Execute([]string{"--installation", missing})
+			Expect(err).To(MatchError("installation " + missing + " is not valid"))
+			Expect(output.String()).To(ContainSubstring("problems:\n  - the installation does not contain installation.yml\n"))
+		})
+	})
+
+	When("a file in the installation is corrupt", func() {
+		It("returns an error", func() {
+			contents, err := ioutil.ReadFile(installation)
+			Expect(err).ToNot(HaveOccurred())
+			index := bytes.Index(contents, []byte(`name: cf-some-guid`))
+			Expect(index).To(BeNumerically(">", 0))
+			contents[index] = 'N'
+			Expect(ioutil.WriteFile(installation, contents, 0600)).To(Succeed())
+
+			command := commands.NewInspectInstallation("some-passphrase", output)
+
+			err = command.Execute([]string{"--installation", installation})
+			Expect(err).To(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("  - could not read deployments/cf-some-guid.yml: zip: checksum error\n"))
+		})
+	})
+
+	When("the installation is not a zip file", func() {
+		It("returns an error", func() {
+			Expect(ioutil.WriteFile(installation, []byte("not a zip"), 0600)).To(Succeed())
+
+			command := commands.NewInspectInstallation("some-passphrase", output)
+
+			err := command.Execute([]string{"--installation", installation})
+			Expect(err).To(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("  - could not open the installation as a zip file: zip: not a valid zip file\n"))
+		})
+	})
+
+	When("the format is not supported", func() {
+		It("returns an error", func() {
+			command := commands.NewInspectInstallation("", output)
+
+			err := command.Execute([]string{"--installation", installation, "--format", "yaml"})
+			Expect(err).To(MatchError(`unsupported format "yaml": must be one of text,json`))
+		})
+	})
+
+	When("an unknown flag is provided", func() {
+		It("returns an error", func() {
+			command := commands.NewInspectInstallation("", output)
+
+			err := command.Execute([]string{"--badflag"})
+			Expect(err).To(MatchError("could not parse inspect-installation flags: flag provided but not defined: -badflag"))
+		})
+	})
+
+	Describe("Usage", func() {
+		It("returns usage information for the command", func() {
+			command := commands.NewInspectInstallation("", nil)
+			Expect(command.Usage()).To(Equal(jhanda.Usage{
+				Description:      "This command opens an exported installation without an Ops Manager and reports whether it is valid, its installation schema version and the products it came from, and whether the global decryption-passphrase can decrypt it.",
+				ShortDescription: "**EXPERIMENTAL** reports on an exported installation before it is imported",
+				Flags:            command.Options,
+			}))
+		})
+	})
+})
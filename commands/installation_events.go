@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+var (
+	installationLogStep = regexp.MustCompile(`^===== (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} \w+) (Running|Finished) "(.*)"(?:; Duration: (\d+)s; Exit Status: (\d+))?$`)
+	installationLogTask = regexp.MustCompile(`^Task (\d+)$`)
+	deploymentGUID      = regexp.MustCompile(`^(.+)-[0-9a-f]+$`)
+)
+
+const installationLogTimeFormat = "2006-01-02 15:04:05 MST"
+
+type installationEvent struct {
+	Type            string `json:"type"`
+	Time            string `json:"time,omitempty"`
+	Step            string `json:"step"`
+	Product         string `json:"product,omitempty"`
+	Deployment      string `json:"deployment,omitempty"`
+	Errand          string `json:"errand,omitempty"`
+	TaskID          int    `json:"task_id,omitempty"`
+	DurationSeconds *int   `json:"duration_seconds,omitempty"`
+	ExitStatus      *int   `json:"exit_status,omitempty"`
+	Result          string `json:"result,omitempty"`
+}
+
+type installationStep struct {
+	command  string
+	event    installationEvent
+	started  string
+	duration time.Duration
+	result   string
+	tasks    map[int]bool
+}
+
+// InstallationEventWriter parses the installation log as it is flushed into
+// structured events, one JSON object per line. Every bosh command Ops Manager
+// runs starts and finishes with a "=====" line, which is used to time each step.
+// The timeline is written to its own writer, so that the events stay valid
+// JSON lines.
+type InstallationEventWriter struct {
+	writer         io.Writer
+	timelineWriter io.Writer
+	offset         int
+	steps          []*installationStep
+	current        *installationStep
+}
+
+func NewInstallationEventWriter(writer io.Writer, timelineWriter io.Writer) *InstallationEventWriter {
+	return &InstallationEventWriter{
+		writer:         writer,
+		timelineWriter: timelineWriter,
+	}
+}
+
+func (iew *InstallationEventWriter) Flush(logs string) error {
+	if iew.offset > len(logs) {
+		return fmt.Errorf("installation log is shorter than what has already been read")
+	}
+
+	unread := logs[iew.offset:]
+	end := strings.LastIndex(unread, "\n")
+	if end < 0 {
+		return nil
+	}
+
+	for _, line := range strings.Split(unread[:end], "\n") {
+		err := iew.parseLine(strings.TrimRight(line, "\r"))
+		if err != nil {
+			return err
+		}
+	}
+
+	iew.offset += end + 1
+	return nil
+}
+
+// FlushFinished flushes the log of an installation that has finished, so
+// that its last line is parsed even though no newline will follow it.
+func (iew *InstallationEventWriter) FlushFinished(logs string) error {
+	err := iew.Flush(logs)
+	if err != nil {
+		return err
+	}
+
+	rest := logs[iew.offset:]
+	if rest == "" {
+		return nil
+	}
+
+	iew.offset = len(logs)
+	return iew.parseLine(strings.TrimRight(rest, "\r"))
+}
+
+func (iew *InstallationEventWriter) parseLine(line string) error {
+	if matches := installationLogStep.FindStringSubmatch(line); matches != nil {
+		timestamp := matches[1]
+		if parsed, err := time.Parse(installationLogTimeFormat, timestamp); err == nil {
+			timestamp = parsed.UTC().Format(time.RFC3339)
+		}
+
+		if matches[2] == "Running" {
+			return iew.startStep(matches[3], timestamp)
+		}
+
+		duration, _ := strconv.Atoi(matches[4])
+		exitStatus, _ := strconv.Atoi(matches[5])
+		return iew.finishStep(matches[3], timestamp, duration, exitStatus)
+	}
+
+	if matches := installationLogTask.FindStringSubmatch(line); matches != nil && iew.current != nil {
+		taskID, _ := strconv.Atoi(matches[1])
+		if iew.current.tasks[taskID] {
+			return nil
+		}
+		iew.current.tasks[taskID] = true
+
+		event := iew.current.event
+		event.Type = "task_started"
+		event.Time = ""
+		event.TaskID = taskID
+		return iew.write(event)
+	}
+
+	return nil
+}
+
+func (iew *InstallationEventWriter) startStep(command, timestamp string) error {
+	step := &installationStep{
+		command: command,
+		event:   describeInstallationStep(command),
+		started: timestamp,
+		tasks:   map[int]bool{},
+	}
+	iew.steps = append(iew.steps, step)
+	iew.current = step
+
+	event := step.event
+	event.Type = "step_started"
+	event.Time = timestamp
+	return iew.write(event)
+}
+
+func (iew *InstallationEventWriter) finishStep(command, timestamp string, duration, exitStatus int) error {
+	var step *installationStep
+	for i := len(iew.steps) - 1; i >= 0; i-- {
+		if iew.steps[i].command == command && iew.steps[i].result == "" {
+			step = iew.steps[i]
+			break
+		}
+	}
+	if step == nil {
+		step = &installationStep{
+			command: command,
+			event:   describeInstallationStep(command),
+			tasks:   map[int]bool{},
+		}
+		iew.steps = append(iew.steps, step)
+	}
+	if iew.current == step {
+		iew.current = nil
+	}
+
+	step.duration = time.Duration(duration) * time.Second
+	step.result = "succeeded"
+	if exitStatus != 0 {
+		step.result = "failed"
+	}
+
+	event := step.event
+	event.Type = "step_finished"
+	event.Time = timestamp
+	event.DurationSeconds = &duration
+	event.ExitStatus = &exitStatus
+	event.Result = step.result
+	return iew.write(event)
+}
+
+func (iew *InstallationEventWriter) write(event installationEvent) error {
+	contents, err := json.Marshal(event)
+	if err != nil {
+		return err // un-tested
+	}
+
+	_, err = fmt.Fprintf(iew.writer, "%s\n", contents)
+	return err
+}
+
+// WriteTimeline renders how long each step of the installation took, in the
+// order they ran. Steps that have not finished are shown as incomplete.
+func (iew *InstallationEventWriter) WriteTimeline() {
+	table := tablewriter.NewWriter(iew.timelineWriter)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Step", "Product", "Started", "Duration", "Result"})
+
+	var total time.Duration
+	for _, step := range iew.steps {
+		duration := ""
+		result := step.result
+		if result == "" {
+			result = "incomplete"
+		} else {
+			duration = step.duration.String()
+			total += step.duration
+		}
+
+		table.Append([]string{step.event.Step, step.event.Product, step.started, duration, result})
+	}
+
+	table.Append([]string{"total", "", "", total.String(), ""})
+	table.Render()
+}
+
+// describeInstallationStep names a step after the bosh command being run, and
+// the product after the deployment, whose name is the product GUID.
+func describeInstallationStep(command string) installationEvent {
+	fields := strings.Fields(command)
+
+	var event installationEvent
+	var args []string
+	for i, field := range fields {
+		if i == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(field, "--deployment=") {
+			event.Deployment = strings.TrimPrefix(field, "--deployment=")
+			continue
+		}
+
+		if !strings.HasPrefix(field, "-") {
+			args = append(args, field)
+		}
+	}
+
+	switch {
+	case len(args) == 0 && len(fields) > 0:
+		event.Step = filepath.Base(fields[0])
+	case len(args) == 0:
+		event.Step = command
+	case args[0] == "run-errand" && len(args) > 1:
+		event.Errand = args[1]
+		event.Step = "run-errand " + event.Errand
+	default:
+		event.Step = args[0]
+	}
+
+	if matches := deploymentGUID.FindStringSubmatch(event.Deployment); matches != nil {
+		event.Product = matches[1]
+	}
+	if event.Step == "create-env" {
+		event.Product = "p-bosh"
+	}
+
+	return event
+}
@@ -0,0 +1,129 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/commands"
+)
+
+const installationLog = `===== 2019-06-26 17:40:01 UTC Running "/usr/local/bin/bosh --no-color --non-interactive --tty create-env /var/tempest/workspaces/default/deployments/bosh.yml"
+Deployment manifest: '/var/tempest/workspaces/default/deployments/bosh.yml'
+===== 2019-06-26 17:50:01 UTC Finished "/usr/local/bin/bosh --no-color --non-interactive --tty create-env /var/tempest/workspaces/default/deployments/bosh.yml"; Duration: 600s; Exit Status: 0
+===== 2019-06-26 17:51:05 UTC Running "/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-3095a0a264aa5900d9b6 deploy /var/tempest/workspaces/default/deployments/cf-3095a0a264aa5900d9b6.yml"
+Using environment '10.0.0.5' as client 'ops_manager'
+Task 45
+
+Task 45 | 17:51:07 | Preparing deployment: Preparing deployment (00:00:02)
+Task 45 done
+===== 2019-06-26 17:59:13 UTC Finished "/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-3095a0a264aa5900d9b6 deploy /var/tempest/workspaces/default/deployments/cf-3095a0a264aa5900d9b6.yml"; Duration: 488s; Exit Status: 0
+===== 2019-06-26 18:00:00 UTC Running "/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-3095a0a264aa5900d9b6 run-errand smoke_tests"
+Task 46
+===== 2019-06-26 18:02:00 UTC Finished "/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-3095a0a264aa5900d9b6 run-errand smoke_tests"; Duration: 120s; Exit Status: 1
+`
+
+var _ = Describe("InstallationEventWriter", func() {
+	var (
+		buffer *bytes.Buffer
+		writer *commands.InstallationEventWriter
+	)
+
+	events := func() []map[string]interface{} {
+		var events []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+			var event map[string]interface{}
+			Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+			events = append(events, event)
+		}
+		return events
+	}
+
+	BeforeEach(func() {
+		buffer = bytes.NewBuffer([]byte{})
+		writer = commands.NewInstallationEventWriter(buffer, buffer)
+	})
+
+	Describe("Flush", func() {
+		It("writes an event for each step and bosh task as JSON lines", func() {
+			err := writer.Flush(installationLog)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(events()).To(Equal([]map[string]interface{}{
+				{"type": "step_started", "time": "2019-06-26T17:40:01Z", "step": "create-env", "product": "p-bosh"},
+				{"type": "step_finished", "time": "2019-06-26T17:50:01Z", "step": "create-env", "product": "p-bosh", "duration_seconds": float64(600), "exit_status": float64(0), "result": "succeeded"},
+				{"type": "step_started", "time": "2019-06-26T17:51:05Z", "step": "deploy", "product": "cf", "deployment": "cf-3095a0a264aa5900d9b6"},
+				{"type": "task_started", "step": "deploy", "product": "cf", "deployment": "cf-3095a0a264aa5900d9b6", "task_id": float64(45)},
+				{"type": "step_finished", "time": "2019-06-26T17:59:13Z", "step": "deploy", "product": "cf", "deployment": "cf-3095a0a264aa5900d9b6", "duration_seconds": float64(488), "exit_status": float64(0), "result": "succeeded"},
+				{"type": "step_started", "time": "2019-06-26T18:00:00Z", "step": "run-errand smoke_tests", "product": "cf", "deployment": "cf-3095a0a264aa5900d9b6", "errand": "smoke_tests"},
+				{"type": "task_started", "step": "run-errand smoke_tests", "product": "cf", "deployment": "cf-3095a0a264aa5900d9b6", "errand": "smoke_tests", "task_id": float64(46)},
+				{"type": "step_finished", "time": "2019-06-26T18:02:00Z", "step": "run-errand smoke_tests", "product": "cf", "deployment": "cf-3095a0a264aa5900d9b6", "errand": "smoke_tests", "duration_seconds": float64(120), "exit_status": float64(1), "result": "failed"},
+			}))
+		})
+
+		It("only parses each line once as the log grows, and waits for lines to be complete", func() {
+			lines := strings.SplitAfter(installationLog, "\n")
+
+			err := writer.Flush(lines[0] + lines[1][:10])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events()).To(HaveLen(1))
+
+			err = writer.Flush(strings.Join(lines[:4], ""))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events()).To(HaveLen(3))
+			Expect(events()[2]["step"]).To(Equal("deploy"))
+		})
+	})
+
+	Describe("FlushFinished", func() {
+		It("parses the last line of the log even though it does not end with a newline", func() {
+			logs := strings.TrimSuffix(installationLog, "\n")
+
+			err := writer.Flush(logs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events()).To(HaveLen(7))
+
+			err = writer.FlushFinished(logs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events()).To(HaveLen(8))
+			Expect(events()[7]["type"]).To(Equal("step_finished"))
+			Expect(events()[7]["result"]).To(Equal("failed"))
+
+			err = writer.FlushFinished(logs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events()).To(HaveLen(8))
+		})
+	})
+
+	Describe("WriteTimeline", func() {
+		It("renders the duration of each step, including those that have not finished", func() {
+			lines := strings.SplitAfter(installationLog, "\n")
+
+			err := writer.Flush(strings.Join(lines[:11], ""))
+			Expect(err).ToNot(HaveOccurred())
+			buffer.Reset()
+
+			writer.WriteTimeline()
+
+			Expect(buffer.String()).To(MatchRegexp(`create-env\s+\|\s+p-bosh\s+\|\s+2019-06-26T17:40:01Z\s+\|\s+10m0s\s+\|\s+succeeded`))
+			Expect(buffer.String()).To(MatchRegexp(`deploy\s+\|\s+cf\s+\|\s+2019-06-26T17:51:05Z\s+\|\s+8m8s\s+\|\s+succeeded`))
+			Expect(buffer.String()).To(MatchRegexp(`run-errand smoke_tests\s+\|\s+cf\s+\|\s+2019-06-26T18:00:00Z\s+\|\s+\|\s+incomplete`))
+			Expect(buffer.String()).To(MatchRegexp(`total\s+\|\s+\|\s+\|\s+18m8s`))
+		})
+
+		It("writes the timeline to its own writer, so the events stay JSON lines", func() {
+			timeline := bytes.NewBuffer([]byte{})
+			writer = commands.NewInstallationEventWriter(buffer, timeline)
+
+			err := writer.Flush(installationLog)
+			Expect(err).ToNot(HaveOccurred())
+
+			writer.WriteTimeline()
+
+			Expect(events()).To(HaveLen(8))
+			Expect(timeline.String()).To(MatchRegexp(`total\s+\|\s+\|\s+\|\s+20m8s`))
+		})
+	})
+})
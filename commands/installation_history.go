@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+)
+
+type InstallationHistory struct {
+	service installationsService
+	output  io.Writer
+	Options struct {
+		Since  string `long:"since"                            description:"only include installations started on or after this date (format: 2006-01-02 or RFC3339)"`
+		Until  string `long:"until"                            description:"only include installations started before this date (format: 2006-01-02 or RFC3339)"`
+		User   string `long:"user"                             description:"only include installations started by this user"`
+		Status string `long:"status"                           description:"only include installations with this status (options: succeeded,failed,running)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,csv)"`
+	}
+}
+
+type installationHistoryEntry struct {
+	ID              int        `json:"id"`
+	User            string     `json:"user"`
+	Status          string     `json:"status"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	DurationSeconds *int       `json:"duration_seconds"`
+}
+
+type installationHistorySummary struct {
+	Total               int     `json:"total"`
+	Succeeded           int     `json:"succeeded"`
+	Failed              int     `json:"failed"`
+	Running             int     `json:"running"`
+	SuccessRate         float64 `json:"success_rate"`
+	MeanDurationSeconds int     `json:"mean_duration_seconds"`
+}
+
+func NewInstallationHistory(service installationsService, output io.Writer) InstallationHistory {
+	return InstallationHistory{
+		service: service,
+		output:  output,
+	}
+}
+
+func (ih InstallationHistory) Execute(args []string) error {
+	if _, err := jhanda.Parse(&ih.Options, args); err != nil {
+		return fmt.Errorf("could not parse installation-history flags: %s", err)
+	}
+
+	switch ih.Options.Format {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("unsupported format %q: must be one of table,json,csv", ih.Options.Format)
+	}
+
+	switch ih.Options.Status {
+	case "", api.StatusSucceeded, api.StatusFailed, api.StatusRunning:
+	default:
+		return fmt.Errorf("unsupported status %q: must be one of succeeded,failed,running", ih.Options.Status)
+	}
+
+	since, err := parseHistoryDate("--since", ih.Options.Since)
+	if err != nil {
+		return err
+	}
+
+	until, err := parseHistoryDate("--until", ih.Options.Until)
+	if err != nil {
+		return err
+	}
+
+	installations, err := ih.service.ListInstallations()
+	if err != nil {
+		return err
+	}
+
+	var entries []installationHistoryEntry
+	for _, installation := range installations {
+		if ih.Options.User != "" && installation.UserName != ih.Options.User {
+			continue
+		}
+		if ih.Options.Status != "" && installation.Status != ih.Options.Status {
+			continue
+		}
+		if installation.StartedAt == nil && (since != nil || until != nil) {
+			continue
+		}
+		if since != nil && installation.StartedAt.Before(*since) {
+			continue
+		}
+		if until != nil && !installation.StartedAt.Before(*until) {
+			continue
+		}
+
+		entry := installationHistoryEntry{
+			ID:         installation.ID,
+			User:       installation.UserName,
+			Status:     installation.Status,
+			StartedAt:  installation.StartedAt,
+			FinishedAt: installation.FinishedAt,
+		}
+		if installation.StartedAt != nil && installation.FinishedAt != nil {
+			duration := int(installation.FinishedAt.Sub(*installation.StartedAt).Seconds())
+			entry.DurationSeconds = &duration
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+
+	summary := summarizeInstallations(entries)
+
+	switch ih.Options.Format {
+	case "json":
+		return ih.presentJSON(entries, summary)
+	case "csv":
+		return ih.presentCSV(entries)
+	}
+
+	ih.presentTable(entries, summary)
+	return nil
+}
+
+// summarizeInstallations computes the success rate over the installations
+// that have finished, and the mean duration of those that have a duration.
+func summarizeInstallations(entries []installationHistoryEntry) installationHistorySummary {
+	summary := installationHistorySummary{Total: len(entries)}
+
+	var totalDuration, durations int
+	for _, entry := range entries {
+		switch entry.Status {
+		case api.StatusSucceeded:
+			summary.Succeeded++
+		case api.StatusFailed:
+			summary.Failed++
+		case api.StatusRunning:
+			summary.Running++
+		}
+
+		if entry.DurationSeconds != nil && entry.Status != api.StatusRunning {
+			totalDuration += *entry.DurationSeconds
+			durations++
+		}
+	}
+
+	if finished := summary.Succeeded + summary.Failed; finished > 0 {
+		summary.SuccessRate = float64(summary.Succeeded) / float64(finished)
+	}
+	if durations > 0 {
+		summary.MeanDurationSeconds = totalDuration / durations
+	}
+
+	return summary
+}
+
+func (ih InstallationHistory) presentJSON(entries []installationHistoryEntry, summary installationHistorySummary) error {
+	if entries == nil {
+		entries = []installationHistoryEntry{}
+	}
+
+	output, err := json.MarshalIndent(struct {
+		Installations []installationHistoryEntry `json:"installations"`
+		Summary       installationHistorySummary `json:"summary"`
+	}{entries, summary}, "", "  ")
+	if err != nil {
+		return err // un-tested
+	}
+
+	_, err = fmt.Fprintf(ih.output, "%s\n", output)
+	return err
+}
+
+func (ih InstallationHistory) presentCSV(entries []installationHistoryEntry) error {
+	writer := csv.NewWriter(ih.output)
+
+	err := writer.Write([]string{"id", "user", "status", "started_at", "finished_at", "duration_seconds"})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = writer.Write(entry.row())
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (ih InstallationHistory) presentTable(entries []installationHistoryEntry, summary installationHistorySummary) {
+	table := tablewriter.NewWriter(ih.output)
+	table.SetHeader([]string{"ID", "User", "Status", "Started At", "Finished At", "Duration"})
+	for _, entry := range entries {
+		row := entry.row()
+		if entry.DurationSeconds != nil {
+			row[5] = (time.Duration(*entry.DurationSeconds) * time.Second).String()
+		}
+		table.Append(row)
+	}
+	table.Render()
+
+	summaryTable := tablewriter.NewWriter(ih.output)
+	summaryTable.SetHeader([]string{"Total", "Succeeded", "Failed", "Running", "Success Rate", "Mean Duration"})
+	summaryTable.Append([]string{
+		strconv.Itoa(summary.Total),
+		strconv.Itoa(summary.Succeeded),
+		strconv.Itoa(summary.Failed),
+		strconv.Itoa(summary.Running),
+		fmt.Sprintf("%.1f%%", summary.SuccessRate*100),
+		(time.Duration(summary.MeanDurationSeconds) * time.Second).String(),
+	})
+	summaryTable.Render()
+}
+
+func (e installationHistoryEntry) row() []string {
+	var startedAt, finishedAt, duration string
+	if e.StartedAt != nil {
+		startedAt = e.StartedAt.Format(time.RFC3339)
+	}
+	if e.FinishedAt != nil {
+		finishedAt = e.FinishedAt.Format(time.RFC3339)
+	}
+	if e.DurationSeconds != nil {
+		duration = strconv.Itoa(*e.DurationSeconds)
+	}
+
+	return []string{strconv.Itoa(e.ID), e.User, e.Status, startedAt, finishedAt, duration}
+}
+
+func parseHistoryDate(flag, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not parse %s %q: expected a date such as 2006-01-02 or 2006-01-02T15:04:05Z", flag, value)
+}
+
+func (ih InstallationHistory) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command lists the installations on the Ops Manager, filtered by date, user and status, along with how long each took, the success rate and the mean duration of an installation.",
+		ShortDescription: "**EXPERIMENTAL** reports installation durations and success rate",
+		Flags:            ih.Options,
+	}
+}
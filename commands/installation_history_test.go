@@ -0,0 +1,177 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("InstallationHistory", func() {
+	var (
+		service *fakes.InstallationsService
+		output  *bytes.Buffer
+		command commands.InstallationHistory
+	)
+
+	BeforeEach(func() {
+		service = &fakes.InstallationsService{}
+		service.ListInstallationsReturns([]api.InstallationsServiceOutput{
+			{
+				ID:         4,
+				UserName:   "admin",
+				Status:     "running",
+				StartedAt:  parseTime("2019-10-03T10:00:00Z"),
+				FinishedAt: nil,
+			},
+			{
+				ID:         3,
+				UserName:   "ci",
+				Status:     "failed",
+				StartedAt:  parseTime("2019-10-02T10:00:00Z"),
+				FinishedAt: parseTime("2019-10-02T10:30:00Z"),
+			},
+			{
+				ID:         2,
+				UserName:   "ci",
+				Status:     "succeeded",
+				StartedAt:  parseTime("2019-09-01T10:00:00Z"),
+				FinishedAt: parseTime("2019-09-01T11:30:00Z"),
+			},
+			{
+				ID:         1,
+				UserName:   "admin",
+				Status:     "succeeded",
+				StartedAt:  parseTime("2019-06-01T10:00:00Z"),
+				FinishedAt: parseTime("2019-06-01T11:00:00Z"),
+			},
+		}, nil)
+
+		output = bytes.NewBuffer(nil)
+		command = commands.NewInstallationHistory(service, output)
+	})
+
+	It("prints the installations with their durations and a summary", func() {
+		err := command.Execute([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(MatchRegexp(`\|\s+1\s+\|\s+admin\s+\|\s+succeeded\s+\|\s+2019-06-01T10:00:00Z\s+\|\s+2019-06-01T11:00:00Z\s+\|\s+1h0m0s\s+\|`))
+		Expect(output.String()).To(MatchRegexp(`\|\s+4\s+\|\s+admin\s+\|\s+running\s+\|\s+2019-10-03T10:00:00Z\s+\|\s+\|\s+\|`))
+		Expect(output.String()).To(MatchRegexp(`\|\s+4\s+\|\s+2\s+\|\s+1\s+\|\s+1\s+\|\s+66.7%\s+\|\s+1h0m0s\s+\|`))
+	})
+
+	It("prints the installations and summary as json", func() {
+		err := command.Execute([]string{"--format", "json", "--since", "2019-07-01"})
+		Expect(err).ToNot(HaveOccurred())
+
+		var report struct {
+			Installations []map[string]interface{}
+			Summary       map[string]interface{}
+		}
+		Expect(json.Unmarshal(output.Bytes(), &report)).To(Succeed())
+
+		Expect(report.Installations).To(HaveLen(3))
+		Expect(report.Installations[0]).To(Equal(map[string]interface{}{
+			"id":               float64(2),
+			"user":             "ci",
+			"status":           "succeeded",
+			"started_at":       "2019-09-01T10:00:00Z",
+			"finished_at":      "2019-09-01T11:30:00Z",
+			"duration_seconds": float64(5400),
+		}))
+		Expect(report.Installations[2]["duration_seconds"]).To(BeNil())
+		Expect(report.Summary).To(Equal(map[string]interface{}{
+			"total":                 float64(3),
+			"succeeded":             float64(1),
+			"failed":                float64(1),
+			"running":               float64(1),
+			"success_rate":          0.5,
+			"mean_duration_seconds": float64(3600),
+		}))
+	})
+
+	It("prints the installations as csv", func() {
+		err := command.Execute([]string{"--format", "csv", "--user", "ci"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(Equal(`id,user,status,started_at,finished_at,duration_seconds
+2,ci,succeeded,2019-09-01T10:00:00Z,2019-09-01T11:30:00Z,5400
+3,ci,failed,2019-10-02T10:00:00Z,2019-10-02T10:30:00Z,1800
+`))
+	})
+
+	It("filters by status and date range", func() {
+		err := command.Execute([]string{"--format", "csv", "--status", "succeeded", "--since", "2019-06-01", "--until", "2019-09-01T10:00:00Z"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(Equal(`id,user,status,started_at,finished_at,duration_seconds
+1,admin,succeeded,2019-06-01T10:00:00Z,2019-06-01T11:00:00Z,3600
+`))
+	})
+
+	It("prints an empty list when nothing matches", func() {
+		err := command.Execute([]string{"--format", "json", "--user", "nobody"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(MatchJSON(`{
+			"installations": [],
+			"summary": {"total": 0, "succeeded": 0, "failed": 0, "running": 0, "success_rate": 0, "mean_duration_seconds": 0}
+		}`))
+	})
+
+	Context("failure cases", func() {
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse installation-history flags: flag provided but not defined: -badflag"))
+			})
+		})
+
+		When("the format is not supported", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--format", "xml"})
+				Expect(err).To(MatchError(`unsupported format "xml": must be one of table,json,csv`))
+			})
+		})
+
+		When("the status is not supported", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--status", "done"})
+				Expect(err).To(MatchError(`unsupported status "done": must be one of succeeded,failed,running`))
+			})
+		})
+
+		When("the date cannot be parsed", func() {
+			It("returns an error", func() {
+				err := command.Execute([]string{"--since", "last week"})
+				Expect(err).To(MatchError(`could not parse --since "last week": expected a date such as 2006-01-02 or 2006-01-02T15:04:05Z`))
+				Expect(service.ListInstallationsCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the installations cannot be listed", func() {
+			It("returns an error", func() {
+				service.ListInstallationsReturns(nil, errors.New("some-error"))
+
+				err := command.Execute([]string{})
+				Expect(err).To(MatchError("some-error"))
+			})
+		})
+	})
+
+	Describe("Usage", func() {
+		It("returns usage information for the command", func() {
+			Expect(command.Usage()).To(Equal(jhanda.Usage{
+				Description:      "This authenticated command lists the installations on the Ops Manager, filtered by date, user and status, along with how long each took, the success rate and the mean duration of an installation.",
+				ShortDescription: "**EXPERIMENTAL** reports installation durations and success rate",
+				Flags:            command.Options,
+			}))
+		})
+	})
+})
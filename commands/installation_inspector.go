@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	passphraseValid      = "valid"
+	passphraseInvalid    = "invalid"
+	passphraseNotChecked = "not checked"
+	passphraseNotNeeded  = "not needed"
+)
+
+type installationReportProduct struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// installationReport is what can be found out about an exported installation
+// without an Ops Manager, to catch a bad archive before it is imported.
+type installationReport struct {
+	Installation  string                      `json:"installation"`
+	SchemaVersion string                      `json:"installation_schema_version"`
+	Products      []installationReportProduct `json:"products"`
+	Passphrase    string                      `json:"decryption_passphrase"`
+	Problems      []string                    `json:"problems"`
+	Warnings      []string                    `json:"warnings"`
+}
+
+func (r installationReport) valid() bool {
+	return len(r.Problems) == 0
+}
+
+// inspectInstallation reads every file in the installation zip, so that a
+// truncated or corrupt archive is found. The installation schema version, and
+// the deployed products, are read from installation.yml when the passphrase
+// can decrypt it; otherwise the products are those of the metadata directory.
+// A passphrase that could not be checked is a warning, as the installation
+// may still fail to import.
+func inspectInstallation(installationPath, passphrase string) installationReport {
+	report := installationReport{
+		Installation:  installationPath,
+		SchemaVersion: "unknown",
+		Products:      []installationReportProduct{},
+		Problems:      []string{},
+		Warnings:      []string{},
+		Passphrase:    passphraseNotChecked,
+	}
+
+	zipReader, err := zip.OpenReader(installationPath)
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("could not open the installation as a zip file: %s", err))
+		return report
+	}
+	defer zipReader.Close()
+
+	var installation []byte
+	var templates []installationReportProduct
+	for _, file := range zipReader.File {
+		contents, err := readZipFile(file)
+		if err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("could not read %s: %s", file.Name, err))
+			continue
+		}
+
+		switch {
+		case file.Name == "installation.yml":
+			installation = contents
+		case path.Dir(file.Name) == "metadata" && path.Ext(file.Name) == ".yml":
+			var metadata struct {
+				Name    string `yaml:"name"`
+				Version string `yaml:"product_version"`
+			}
+			if err := yaml.Unmarshal(contents, &metadata); err != nil || metadata.Name == "" {
+				report.Problems = append(report.Problems, fmt.Sprintf("could not find the product name in %s", file.Name))
+				continue
+			}
+			templates = append(templates, installationReportProduct{Name: metadata.Name, Version: metadata.Version})
+		}
+	}
+
+	if installation == nil {
+		report.Problems = append(report.Problems, "the installation does not contain installation.yml")
+		return report
+	}
+
+	settings, status := decryptInstallationSettings(installation, passphrase)
+	report.Passphrase = status
+	switch {
+	case status == passphraseInvalid:
+		report.Problems = append(report.Problems, "the decryption passphrase cannot decrypt installation.yml")
+	case status == passphraseNotChecked && passphrase == "":
+		report.Warnings = append(report.Warnings, "no decryption passphrase was provided, so it was not checked against installation.yml")
+	case status == passphraseNotChecked && isOpenSSLEncrypted(installation):
+		report.Warnings = append(report.Warnings, "the decryption passphrase did not decrypt installation.yml with the key derivations om knows, so either it is wrong or installation.yml was encrypted another way")
+	case status == passphraseNotChecked:
+		report.Warnings = append(report.Warnings, "installation.yml is not in a format the decryption passphrase can be checked against")
+	}
+
+	if settings.SchemaVersion != "" {
+		report.SchemaVersion = settings.SchemaVersion
+	}
+
+	if settings.Products != nil {
+		for _, product := range settings.Products {
+			report.Products = append(report.Products, installationReportProduct{Name: product.Identifier, Version: product.Version})
+		}
+	} else {
+		report.Products = append(report.Products, templates...)
+	}
+
+	sort.Slice(report.Products, func(i, j int) bool {
+		if report.Products[i].Name == report.Products[j].Name {
+			return report.Products[i].Version < report.Products[j].Version
+		}
+		return report.Products[i].Name < report.Products[j].Name
+	})
+
+	return report
+}
+
+// readZipFile reads all of a file, which also checks it against its CRC.
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	if file.Name == "installation.yml" || path.Dir(file.Name) == "metadata" {
+		return ioutil.ReadAll(reader)
+	}
+
+	_, err = io.Copy(ioutil.Discard, reader)
+	return nil, err
+}
+
+type installationSettings struct {
+	SchemaVersion string `yaml:"installation_schema_version"`
+	Products      []struct {
+		Identifier string `yaml:"identifier"`
+		Version    string `yaml:"product_version"`
+	} `yaml:"products"`
+}
+
+// decryptInstallationSettings parses installation.yml, decrypting it when it
+// is in the salted format of `openssl enc -aes-256-cbc`. The key derivation
+// of that format is not recorded, and only EVP_BytesToKey with sha256 or md5
+// is tried. The passphrase is only reported as invalid when one of them
+// decrypts installation.yml with valid padding into something that is not
+// YAML. It is reported as not checked when none of them decrypts it, as it
+// may have been encrypted with another key derivation, such as PBKDF2, and
+// when installation.yml is in any other format.
+func decryptInstallationSettings(contents []byte, passphrase string) (installationSettings, string) {
+	var settings installationSettings
+
+	if !isOpenSSLEncrypted(contents) {
+		var plain map[string]interface{}
+		if yaml.Unmarshal(contents, &plain) == nil && plain != nil {
+			_ = yaml.Unmarshal(contents, &settings)
+			return settings, passphraseNotNeeded
+		}
+		return settings, passphraseNotChecked
+	}
+
+	if passphrase == "" {
+		return settings, passphraseNotChecked
+	}
+
+	status := passphraseNotChecked
+	for _, digest := range []func() hash.Hash{sha256.New, md5.New} {
+		plaintext, err := decryptOpenSSL(contents, passphrase, digest)
+		if err != nil {
+			continue
+		}
+
+		var plain map[string]interface{}
+		if yaml.Unmarshal(plaintext, &plain) != nil || plain == nil {
+			status = passphraseInvalid
+			continue
+		}
+
+		_ = yaml.Unmarshal(plaintext, &settings)
+		return settings, passphraseValid
+	}
+
+	return settings, status
+}
+
+func isOpenSSLEncrypted(contents []byte) bool {
+	return bytes.HasPrefix(contents, []byte("Salted__"))
+}
+
+func decryptOpenSSL(contents []byte, passphrase string, digest func() hash.Hash) ([]byte, error) {
+	if len(contents) <= 16 || (len(contents)-16)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("encrypted contents have an invalid length")
+	}
+	salt, ciphertext := contents[8:16], contents[16:]
+
+	// EVP_BytesToKey, with one iteration, for a 32 byte key and 16 byte IV
+	var derived, previous []byte
+	for len(derived) < 48 {
+		h := digest()
+		h.Write(previous)
+		h.Write([]byte(passphrase))
+		h.Write(salt)
+		previous = h.Sum(nil)
+		derived = append(derived, previous...)
+	}
+
+	block, err := aes.NewCipher(derived[:32])
+	if err != nil {
+		return nil, err // un-tested
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, derived[32:48]).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("invalid padding")
+		}
+	}
+
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+func (r installationReport) writeJSON(w io.Writer) error {
+	contents, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err // un-tested
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", contents)
+	return err
+}
+
+func (r installationReport) writeText(w io.Writer) {
+	fmt.Fprintf(w, "installation: %s\n", r.Installation)
+	fmt.Fprintf(w, "installation schema version: %s\n", r.SchemaVersion)
+	fmt.Fprintf(w, "decryption passphrase: %s\n", r.Passphrase)
+
+	if len(r.Products) > 0 {
+		table := tablewriter.NewWriter(w)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetHeader([]string{"Product", "Version"})
+		for _, product := range r.Products {
+			table.Append([]string{product.Name, product.Version})
+		}
+		table.Render()
+	}
+
+	if len(r.Warnings) > 0 {
+		fmt.Fprintf(w, "warnings:\n  - %s\n", strings.Join(r.Warnings, "\n  - "))
+	}
+
+	if !r.valid() {
+		fmt.Fprintf(w, "problems:\n  - %s\n", strings.Join(r.Problems, "\n  - "))
+	}
+}
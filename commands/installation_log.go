@@ -2,28 +2,40 @@ package commands
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
 )
 
 type InstallationLog struct {
-	service installationLogService
-	logger  logger
-	Options struct {
-		Id int `long:"id" required:"true" description:"id of the installation to retrieve logs for"`
+	service      installationLogService
+	logger       logger
+	waitDuration time.Duration
+	logSizeStep  int
+	Options      struct {
+		Id        int    `long:"id"         required:"true" description:"id of the installation to retrieve logs for"`
+		Follow    bool   `long:"follow"     short:"f"       description:"keep printing new lines of the log until the installation finishes"`
+		Grep      string `long:"grep"                       description:"only print lines matching this regular expression"`
+		Product   string `long:"product"                    description:"only print lines of the steps that deploy this product (e.g. cf, p-bosh)"`
+		SinceLine int    `long:"since-line"                 description:"only print lines after this line number"`
 	}
 }
 
 //counterfeiter:generate -o ./fakes/installation_log_service.go --fake-name InstallationLogService . installationLogService
 type installationLogService interface {
+	GetInstallation(id int) (api.InstallationsServiceOutput, error)
 	GetInstallationLogs(id int) (api.InstallationsServiceOutput, error)
 }
 
-func NewInstallationLog(service installationLogService, logger logger) InstallationLog {
+func NewInstallationLog(service installationLogService, logger logger, waitDuration time.Duration) InstallationLog {
 	return InstallationLog{
-		service: service,
-		logger:  logger,
+		service:      service,
+		logger:       logger,
+		waitDuration: waitDuration,
+		logSizeStep:  followLogSizeStep,
 	}
 }
 
@@ -32,17 +44,177 @@ func (i InstallationLog) Execute(args []string) error {
 		return fmt.Errorf("could not parse installation-log flags: %s", err)
 	}
 
+	if i.Options.SinceLine < 0 {
+		return fmt.Errorf("--since-line cannot be negative")
+	}
+
+	filter := &installationLogFilter{
+		product:   i.Options.Product,
+		sinceLine: i.Options.SinceLine,
+	}
+	if i.Options.Grep != "" {
+		grep, err := regexp.Compile(i.Options.Grep)
+		if err != nil {
+			return fmt.Errorf("could not compile --grep %q: %s", i.Options.Grep, err)
+		}
+		filter.grep = grep
+	}
+
+	if i.Options.Follow {
+		return i.follow(filter)
+	}
+
 	output, err := i.service.GetInstallationLogs(i.Options.Id)
 	if err != nil {
 		return err
 	}
-	i.logger.Print(output.Logs)
+
+	if !filter.enabled() {
+		i.logger.Print(output.Logs)
+		return nil
+	}
+
+	lines, err := filter.next(output.Logs, true)
+	if err != nil {
+		return err // un-tested
+	}
+	for _, line := range lines {
+		i.logger.Println(line)
+	}
 	return nil
 }
 
+// Ops Manager has no way to return only part of an installation log, so
+// every poll of --follow downloads the whole log again. The wait between polls
+// grows by one wait duration for every followLogSizeStep of log, up to
+// maxFollowWaits, so that a large log is not downloaded back to back.
+const (
+	followLogSizeStep = 5 * 1024 * 1024
+	maxFollowWaits    = 6
+)
+
+// follow polls the installation until it is no longer running, printing only
+// the lines that were added to the log since the previous poll.
+func (i InstallationLog) follow(filter *installationLogFilter) error {
+	for {
+		current, err := i.service.GetInstallation(i.Options.Id)
+		if err != nil {
+			return fmt.Errorf("could not get the status of installation %d: %s", i.Options.Id, err)
+		}
+		finished := current.Status != api.StatusRunning
+
+		output, err := i.service.GetInstallationLogs(i.Options.Id)
+		if err != nil {
+			return err
+		}
+
+		lines, err := filter.next(output.Logs, finished)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			i.logger.Println(line)
+		}
+
+		if finished {
+			return nil
+		}
+
+		time.Sleep(i.followWait(len(output.Logs)))
+	}
+}
+
+func (i InstallationLog) followWait(logSize int) time.Duration {
+	step := i.logSizeStep
+	if step <= 0 {
+		step = followLogSizeStep
+	}
+
+	waits := 1 + logSize/step
+	if waits > maxFollowWaits {
+		waits = maxFollowWaits
+	}
+	return time.Duration(waits) * i.waitDuration
+}
+
+// installationLogFilter reads the installation log as it grows, keeping track
+// of the line number and of the step each line belongs to, so that lines can
+// be filtered by product without re-reading the log from the start.
+type installationLogFilter struct {
+	grep        *regexp.Regexp
+	product     string
+	sinceLine   int
+	offset      int
+	line        int
+	stepProduct string
+}
+
+func (f *installationLogFilter) enabled() bool {
+	return f.grep != nil || f.product != "" || f.sinceLine > 0
+}
+
+// next returns the lines added to logs since it was last called that pass the
+// filters. Unless complete is set, a trailing line without a newline is left
+// for the next call, as Ops Manager may still be writing it.
+func (f *installationLogFilter) next(logs string, complete bool) ([]string, error) {
+	if f.offset > len(logs) {
+		return nil, fmt.Errorf("installation log is shorter than what has already been read")
+	}
+
+	unread := logs[f.offset:]
+	end := len(unread)
+	if !complete {
+		end = strings.LastIndex(unread, "\n") + 1
+	}
+	if end == 0 {
+		return nil, nil
+	}
+	f.offset += end
+
+	var lines []string
+	for _, line := range strings.SplitAfter(unread[:end], "\n") {
+		if line == "" {
+			continue
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if f.keep(line) {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+func (f *installationLogFilter) keep(line string) bool {
+	f.line++
+
+	product := f.stepProduct
+	if matches := installationLogStep.FindStringSubmatch(line); matches != nil {
+		product = describeInstallationStep(matches[3]).Product
+		if matches[2] == "Running" {
+			f.stepProduct = product
+		} else {
+			f.stepProduct = ""
+		}
+	}
+
+	if f.line <= f.sinceLine {
+		return false
+	}
+	if f.product != "" && product != f.product {
+		return false
+	}
+	if f.grep != nil && !f.grep.MatchString(line) {
+		return false
+	}
+
+	return true
+}
+
 func (i InstallationLog) Usage() jhanda.Usage {
 	return jhanda.Usage{
-		Description:      "This authenticated command retrieves the logs for a given installation.",
+		Description:      "This authenticated command retrieves the logs for a given installation. With --follow, it prints new lines as they are written until the installation finishes, like tail -f.",
 		ShortDescription: "output installation logs",
 		Flags:            i.Options,
 	}
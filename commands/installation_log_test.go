@@ -2,6 +2,8 @@ package commands_test
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,7 +23,7 @@ var _ = Describe("InstallationLog", func() {
 	BeforeEach(func() {
 		logger = &fakes.Logger{}
 		fakeService = &fakes.InstallationLogService{}
-		command = commands.NewInstallationLog(fakeService, logger)
+		command = commands.NewInstallationLog(fakeService, logger, 0)
 	})
 
 	Describe("Execute", func() {
@@ -42,6 +44,125 @@ var _ = Describe("InstallationLog", func() {
 			Expect(outputLogs).To(Equal("some log output"))
 		})
 
+		printed := func() []string {
+			var lines []string
+			for i := 0; i < logger.PrintlnCallCount(); i++ {
+				lines = append(lines, logger.PrintlnArgsForCall(i)[0].(string))
+			}
+			return lines
+		}
+
+		Context("with filters", func() {
+			BeforeEach(func() {
+				fakeService.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: installationLog}, nil)
+			})
+
+			It("only prints the lines matching --grep", func() {
+				err := command.Execute([]string{"--id", "999", "--grep", "^Task \\d+$"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(printed()).To(Equal([]string{"Task 45", "Task 46"}))
+				Expect(logger.PrintCallCount()).To(Equal(0))
+			})
+
+			It("only prints the lines of the steps for --product", func() {
+				err := command.Execute([]string{"--id", "999", "--product", "p-bosh"})
+				Expect(err).ToNot(HaveOccurred())
+
+				lines := strings.Split(installationLog, "\n")
+				Expect(printed()).To(Equal(lines[0:3]))
+			})
+
+			It("only prints the lines after --since-line", func() {
+				err := command.Execute([]string{"--id", "999", "--since-line", "10", "--product", "cf"})
+				Expect(err).ToNot(HaveOccurred())
+
+				lines := strings.Split(installationLog, "\n")
+				Expect(printed()).To(Equal(lines[10:13]))
+			})
+		})
+
+		Context("with --follow", func() {
+			It("prints each new line once until the installation finishes", func() {
+				lines := strings.SplitAfter(installationLog, "\n")
+				polls := []string{
+					lines[0] + lines[1][:10],
+					strings.Join(lines[:4], ""),
+					strings.Join(lines[:4], ""),
+					installationLog + "Finishing",
+				}
+				fakeService.GetInstallationStub = func(int) (api.InstallationsServiceOutput, error) {
+					if fakeService.GetInstallationCallCount() < len(polls) {
+						return api.InstallationsServiceOutput{Status: "running"}, nil
+					}
+					return api.InstallationsServiceOutput{Status: "succeeded"}, nil
+				}
+				fakeService.GetInstallationLogsStub = func(int) (api.InstallationsServiceOutput, error) {
+					return api.InstallationsServiceOutput{Logs: polls[fakeService.GetInstallationLogsCallCount()-1]}, nil
+				}
+
+				err := command.Execute([]string{"--id", "999", "--follow"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeService.GetInstallationCallCount()).To(Equal(4))
+				Expect(fakeService.GetInstallationArgsForCall(0)).To(Equal(999))
+
+				expected := strings.Split(strings.TrimSuffix(installationLog, "\n"), "\n")
+				expected = append(expected, "Finishing")
+				Expect(printed()).To(Equal(expected))
+			})
+
+			It("applies the filters to the new lines", func() {
+				fakeService.GetInstallationReturns(api.InstallationsServiceOutput{Status: "failed"}, nil)
+				fakeService.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: installationLog}, nil)
+
+				err := command.Execute([]string{"--id", "999", "--follow", "--product", "cf", "--grep", "Task"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(printed()).To(Equal([]string{
+					"Task 45",
+					"Task 45 | 17:51:07 | Preparing deployment: Preparing deployment (00:00:02)",
+					"Task 45 done",
+					"Task 46",
+				}))
+			})
+
+			It("waits longer between polls of a large log", func() {
+				command = commands.NewInstallationLog(fakeService, logger, 20*time.Millisecond)
+				command = commands.WithLogSizeStep(command, 18)
+				fakeService.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{Status: "running"}, nil)
+				fakeService.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{Status: "succeeded"}, nil)
+				fakeService.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: strings.Repeat("a line of the log\n", 10)}, nil)
+
+				start := time.Now()
+				err := command.Execute([]string{"--id", "999", "--follow"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeService.GetInstallationLogsCallCount()).To(Equal(2))
+				Expect(time.Since(start)).To(BeNumerically(">=", 6*20*time.Millisecond))
+			})
+
+			When("the installation status cannot be retrieved", func() {
+				It("returns an error", func() {
+					fakeService.GetInstallationReturns(api.InstallationsServiceOutput{}, errors.New("some-error"))
+
+					err := command.Execute([]string{"--id", "999", "--follow"})
+					Expect(err).To(MatchError("could not get the status of installation 999: some-error"))
+				})
+			})
+
+			When("the installation log gets shorter", func() {
+				It("returns an error", func() {
+					fakeService.GetInstallationReturns(api.InstallationsServiceOutput{Status: "running"}, nil)
+					fakeService.GetInstallationLogsReturnsOnCall(0, api.InstallationsServiceOutput{Logs: installationLog}, nil)
+					fakeService.GetInstallationLogsReturnsOnCall(1, api.InstallationsServiceOutput{Logs: "short\n"}, nil)
+
+					err := command.Execute([]string{"--id", "999", "--follow"})
+					Expect(err).To(MatchError("installation log is shorter than what has already been read"))
+				})
+			})
+		})
+
 		Context("Failure cases", func() {
 			When("an unknown flag is provided", func() {
 				It("returns an error", func() {
@@ -55,6 +176,19 @@ var _ = Describe("InstallationLog", func() {
 					Expect(err).To(MatchError("could not parse installation-log flags: missing required flag \"--id\""))
 				})
 			})
+			When("--grep is not a valid regular expression", func() {
+				It("returns an error", func() {
+					err := command.Execute([]string{"--id", "999", "--grep", "("})
+					Expect(err).To(MatchError(ContainSubstring(`could not compile --grep "(":`)))
+					Expect(fakeService.GetInstallationLogsCallCount()).To(Equal(0))
+				})
+			})
+			When("--since-line is negative", func() {
+				It("returns an error", func() {
+					err := command.Execute([]string{"--id", "999", "--since-line", "-1"})
+					Expect(err).To(MatchError("--since-line cannot be negative"))
+				})
+			})
 			When("the api fails to retrieve the installation log", func() {
 				It("returns an error", func() {
 					fakeService.GetInstallationLogsReturns(
@@ -70,9 +204,9 @@ var _ = Describe("InstallationLog", func() {
 
 	Describe("Usage", func() {
 		It("returns usage information for the command", func() {
-			command := commands.NewInstallationLog(nil, nil)
+			command := commands.NewInstallationLog(nil, nil, 0)
 			Expect(command.Usage()).To(Equal(jhanda.Usage{
-				Description:      "This authenticated command retrieves the logs for a given installation.",
+				Description:      "This authenticated command retrieves the logs for a given installation. With --follow, it prints new lines as they are written until the installation finishes, like tail -f.",
 				ShortDescription: "output installation logs",
 				Flags:            command.Options,
 			}))
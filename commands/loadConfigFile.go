@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/interpolate"
 	"gopkg.in/yaml.v2"
 	"reflect"
@@ -14,7 +13,7 @@ import (
 // To load vars, VarsFile and/or VarsEnv must exist in the command struct being passed in.
 // If VarsEnv is used, envFunc must be defined instead of nil
 func loadConfigFile(args []string, command interface{}, envFunc func() []string) error {
-	_, err := jhanda.Parse(command, args)
+	_, err := ParseFlags(command, args)
 	commandValue := reflect.ValueOf(command).Elem()
 	configFile := commandValue.FieldByName("ConfigFile").String()
 	if configFile == "" {
@@ -85,6 +84,6 @@ func loadConfigFile(args []string, command interface{}, envFunc func() []string)
 
 	}
 	fileArgs = append(fileArgs, args...)
-	_, err = jhanda.Parse(command, fileArgs)
+	_, err = ParseFlags(command, fileArgs)
 	return err
 }
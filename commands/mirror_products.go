@@ -0,0 +1,364 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/interpolate"
+)
+
+type MirrorProducts struct {
+	environFunc    func() []string
+	progressWriter io.Writer
+	stderr         *log.Logger
+	stdout         *log.Logger
+	Options        struct {
+		Manifest          string   `long:"manifest"              short:"m"  description:"path to a yml file listing the products to mirror, in the format of download-products" required:"true"`
+		ConfigFile        string   `long:"config"                short:"c"  description:"path to yml file for configuration (keys must match the following command line flags)"`
+		DownloadDirectory string   `long:"download-directory"               description:"directory in which each file is downloaded before it is uploaded, and deleted from after. A download that was interrupted continues from what it left there. Defaults to a temporary directory"`
+		VarsEnv           []string `long:"vars-env" env:"OM_VARS_ENV" experimental:"true" description:"load variables from environment variables matching the provided prefix (e.g.: 'MY' to load MY_var=value)"`
+		VarsFile          []string `long:"vars-file" short:"l"  description:"load variables from a YAML file"`
+		Vars              []string `long:"var"                              description:"Load variable from the command line. Format: VAR=VAL"`
+
+		PivnetDisableSSL bool   `long:"pivnet-disable-ssl"               description:"whether to disable ssl validation when contacting the Pivotal Network"`
+		PivnetToken      string `long:"pivnet-api-token"      short:"t"  description:"API token to use when interacting with Pivnet. Can be retrieved from your profile page in Pivnet." required:"true"`
+
+		ParallelConnections int `long:"parallel-connections" description:"how many connections to download each file from pivnet with, each fetching its own range of bytes" default:"10"`
+
+		Blobstore    string `long:"blobstore" description:"the blobstore to mirror the products to (options: s3,gcs,azure)" required:"true"`
+		ProductPath  string `long:"blobstore-product-path" alias:"s3-product-path,gcs-product-path,azure-product-path" description:"the path in the bucket where the product files are mirrored, the same as the --blobstore-product-path of download-product"`
+		StemcellPath string `long:"blobstore-stemcell-path" alias:"s3-stemcell-path,gcs-stemcell-path,azure-stemcell-path" description:"the path in the bucket where the stemcells are mirrored, the same as the --blobstore-stemcell-path of download-product"`
+
+		BlobstoreFlags
+	}
+}
+
+// mirroredFile is a row of the table that mirror-products prints.
+type mirroredFile struct {
+	slug    string
+	version string
+	name    string
+	result  string
+	failed  bool
+}
+
+func NewMirrorProducts(
+	environFunc func() []string,
+	stdout *log.Logger,
+	stderr *log.Logger,
+	progressWriter io.Writer,
+) *MirrorProducts {
+	return &MirrorProducts{
+		environFunc:    environFunc,
+		stderr:         stderr,
+		stdout:         stdout,
+		progressWriter: progressWriter,
+	}
+}
+
+func (c MirrorProducts) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command copies the product files listed in a manifest, and the stemcells they require, from Pivotal Network to a blobstore. The files are named the way download-product expects when its source is the blobstore. Files that are already in the bucket with the same SHA256 are skipped, so that the command can be run again whenever new versions are released",
+		ShortDescription: "**EXPERIMENTAL** copies the products listed in a manifest from Pivotal Network to a blobstore",
+		Flags:            c.Options,
+	}
+}
+
+func (c *MirrorProducts) Execute(args []string) error {
+	err := loadConfigFile(args, &c.Options, c.environFunc)
+	if err != nil {
+		return fmt.Errorf("could not parse mirror-products flags: %s", err)
+	}
+
+	if c.Options.ParallelConnections < 1 {
+		return fmt.Errorf("--parallel-connections must be at least 1")
+	}
+
+	manifest, err := loadDownloadProductsManifest(c.Options.Manifest, interpolate.Options{
+		VarsFiles:   c.Options.VarsFile,
+		Vars:        c.Options.Vars,
+		EnvironFunc: c.environFunc,
+		VarsEnvs:    c.Options.VarsEnv,
+	})
+	if err != nil {
+		return err
+	}
+
+	store, err := newBlobstore(c.Options.Blobstore, c.Options.blobstoreOptions())
+	if err != nil {
+		return err
+	}
+
+	plugin, ok := plugins["pivnet"]
+	if !ok {
+		return fmt.Errorf("could not find valid source for 'pivnet'")
+	}
+
+	pivnetClient, err := plugin(c.downloadProductOptions(), c.progressWriter, c.stdout, c.stderr)
+	if err != nil {
+		return err
+	}
+
+	downloadDirectory := c.Options.DownloadDirectory
+	if downloadDirectory == "" {
+		downloadDirectory, err = ioutil.TempDir("", "om-mirror-products")
+		if err != nil {
+			return fmt.Errorf("could not create a directory to download to: %s", err)
+		}
+		defer os.RemoveAll(downloadDirectory)
+	}
+
+	options := c.downloadProductOptions()
+	options.OutputDir = downloadDirectory
+
+	var mirrored []mirroredFile
+	for _, product := range manifest.Products {
+		download := &DownloadProduct{
+			environFunc:    c.environFunc,
+			progressWriter: c.progressWriter,
+			stderr:         c.stderr,
+			stdout:         c.stdout,
+			downloadClient: pivnetClient,
+			Options:        product.downloadProductOptions(options),
+		}
+
+		mirrored = append(mirrored, c.mirrorProduct(store, download)...)
+	}
+
+	var output bytes.Buffer
+	table := tablewriter.NewWriter(&output)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"Slug", "Version", "File", "Result"})
+
+	failed := 0
+	for _, file := range mirrored {
+		if file.failed {
+			failed++
+		}
+		table.Append([]string{file.slug, file.version, file.name, file.result})
+	}
+	table.Render()
+
+	c.stdout.Print(output.String())
+
+	if failed > 0 {
+		return fmt.Errorf("failed to mirror %d of %d files", failed, len(mirrored))
+	}
+
+	return nil
+}
+
+// mirrorProduct mirrors the product file of a product in the manifest, and
+// its stemcell when stemcell-iaas is set. It stops at the first failure,
+// which is the last file it returns.
+func (c MirrorProducts) mirrorProduct(store Blobstore, download *DownloadProduct) []mirroredFile {
+	options := download.Options
+	product := mirroredFile{slug: options.PivnetProductSlug}
+
+	fail := func(file mirroredFile, files []mirroredFile, format string, args ...interface{}) []mirroredFile {
+		file.result = fmt.Sprintf(format, args...)
+		file.failed = true
+		return append(files, file)
+	}
+
+	var stemcellConstraints version.Constraints
+	if options.StemcellVersionConstraint != "" {
+		var err error
+		stemcellConstraints, err = version.NewConstraint(options.StemcellVersionConstraint)
+		if err != nil {
+			return fail(product, nil, "could not parse stemcell version constraint '%s': %s", options.StemcellVersionConstraint, err)
+		}
+	}
+
+	productVersion, err := download.determineProductVersion()
+	if err != nil {
+		return fail(product, nil, "%s", err)
+	}
+	product.version = productVersion
+
+	productFile, err := download.downloadClient.GetLatestProductFile(options.PivnetProductSlug, productVersion, options.PivnetFileGlob)
+	if err != nil {
+		return fail(product, nil, "could not find product file: %s", err)
+	}
+
+	product.name = mirroredFileName(c.Options.ProductPath, options.PivnetProductSlug, productVersion, productFile.Name())
+	product.result, err = c.mirrorFile(store, download, productFile, product.name)
+	if err != nil {
+		return fail(product, nil, "%s", err)
+	}
+
+	files := []mirroredFile{product}
+	if options.StemcellIaas == "" {
+		return files
+	}
+
+	if path.Ext(productFile.Name()) != ".pivotal" {
+		c.stderr.Printf("%s is not a .pivotal file. Not determining and mirroring required stemcell.", productFile.Name())
+		return files
+	}
+
+	// the pivnet client finds the stemcell in the dependencies of the
+	// release, so the product file does not have to be downloaded
+	stemcell, err := download.downloadClient.GetLatestStemcellForProduct(productFile, "", stemcellConstraints)
+	if err != nil {
+		return fail(mirroredFile{slug: options.PivnetProductSlug}, files, "could not get information about stemcell: %s", err)
+	}
+
+	stemcellMirror := mirroredFile{slug: stemcell.Slug(), version: stemcell.Version()}
+	stemcellFile, err := download.downloadClient.GetLatestProductFile(stemcell.Slug(), stemcell.Version(), fmt.Sprintf("*%s*", options.StemcellIaas))
+	if err != nil {
+		return fail(stemcellMirror, files, "could not find stemcell file: %s", err)
+	}
+
+	stemcellMirror.name = mirroredFileName(c.Options.StemcellPath, stemcell.Slug(), stemcell.Version(), stemcellFile.Name())
+	stemcellMirror.result, err = c.mirrorFile(store, download, stemcellFile, stemcellMirror.name)
+	if err != nil {
+		return fail(stemcellMirror, files, "%s", err)
+	}
+
+	return append(files, stemcellMirror)
+}
+
+// mirroredFileName is the name of a file in the bucket, prefixed with its
+// slug and version, which is how download-product finds it.
+func mirroredFileName(directory, slug, version, fileName string) string {
+	return path.Join(directory, fmt.Sprintf("[%s,%s]%s", slug, version, path.Base(fileName)))
+}
+
+// mirrorFile uploads a file from Pivotal Network to name in the bucket,
+// along with a .sha256 checksum file, unless it is already there.
+func (c MirrorProducts) mirrorFile(store Blobstore, download *DownloadProduct, fileArtifact FileArtifacter, name string) (string, error) {
+	mirrored, err := c.isMirrored(store, name, fileArtifact.SHA256())
+	if err != nil {
+		return "", fmt.Errorf("could not check %s in %s: %s", name, store.Name(), err)
+	}
+
+	if mirrored {
+		c.stderr.Printf("%s is already in %s, skipping", name, store.Name())
+		return "already mirrored", nil
+	}
+
+	localPath := filepath.Join(download.Options.OutputDir, path.Base(name))
+	err = download.downloadFileArtifact(fileArtifact, localPath)
+	if err != nil {
+		return "", fmt.Errorf("could not download %s: %s", fileArtifact.Name(), err)
+	}
+	defer os.Remove(localPath)
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	c.stderr.Printf("uploading %s to %s", name, store.Name())
+
+	reader := newChecksumReader(file, fileArtifact.SHA256())
+	err = store.Put(name, reader, info.Size())
+	if err != nil {
+		return "", fmt.Errorf("could not upload %s: %s", name, err)
+	}
+
+	checksum := formatChecksum(reader.Sum(), name)
+	err = store.Put(checksumFileName(name), strings.NewReader(checksum), int64(len(checksum)))
+	if err != nil {
+		return "", fmt.Errorf("could not upload checksum of %s: %s", name, err)
+	}
+
+	return "uploaded", nil
+}
+
+// isMirrored is whether the bucket has the file with the expected checksum.
+// The checksum file is trusted when there is one. Otherwise the file is read
+// to compute its checksum, which is then written for the next time.
+func (c MirrorProducts) isMirrored(store Blobstore, name, expected string) (bool, error) {
+	files, err := store.List(name)
+	if err != nil {
+		return false, err
+	}
+
+	var found, hasChecksum bool
+	for _, file := range files {
+		switch file.Name {
+		case name:
+			found = true
+		case checksumFileName(name):
+			hasChecksum = true
+		}
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	if expected == "" {
+		return true, nil
+	}
+
+	if hasChecksum {
+		sum, err := ReadBlobstoreChecksum(store, name)
+		if err != nil {
+			c.stderr.Printf("could not read the checksum of %s, uploading it again: %s", name, err)
+			return false, nil
+		}
+
+		if sum != expected {
+			c.stderr.Printf("the checksum of %s (%s) does not match the one from Pivotal Network (%s), uploading it again", name, sum, expected)
+		}
+		return sum == expected, nil
+	}
+
+	c.stderr.Printf("%s has no checksum file, calculating sha sum", name)
+
+	existing, _, err := store.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer existing.Close()
+
+	reader := newChecksumReader(existing, "")
+	_, err = io.Copy(ioutil.Discard, reader)
+	if err != nil {
+		return false, err
+	}
+
+	if reader.Sum() != expected {
+		c.stderr.Printf("the checksum of %s (%s) does not match the one from Pivotal Network (%s), uploading it again", name, reader.Sum(), expected)
+		return false, nil
+	}
+
+	checksum := formatChecksum(reader.Sum(), name)
+	err = store.Put(checksumFileName(name), strings.NewReader(checksum), int64(len(checksum)))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// downloadProductOptions gives the pivnet client the options that it would
+// have for download-product, without those of any one product.
+func (c MirrorProducts) downloadProductOptions() DownloadProductOptions {
+	return DownloadProductOptions{
+		Source:              "pivnet",
+		PivnetDisableSSL:    c.Options.PivnetDisableSSL,
+		PivnetToken:         c.Options.PivnetToken,
+		ParallelConnections: c.Options.ParallelConnections,
+	}
+}
@@ -0,0 +1,333 @@
+package commands_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("MirrorProducts", func() {
+	var (
+		command               *commands.MirrorProducts
+		fakeProductDownloader *fakes.ProductDownloader
+		blobstore             *fakes.Blobstore
+		bucket                map[string]string
+		pivnetFiles           map[string]string
+		buffer                *gbytes.Buffer
+		tempDir               string
+		manifestFile          string
+		clientOptions         commands.DownloadProductOptions
+		blobstoreOptions      commands.BlobstoreOptions
+	)
+
+	sha := func(contents string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+	}
+
+	writeManifest := func(contents string) {
+		err := ioutil.WriteFile(manifestFile, []byte(contents), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	execute := func(args ...string) error {
+		return command.Execute(append([]string{
+			"--manifest", manifestFile,
+			"--pivnet-api-token", "token",
+			"--blobstore", "s3",
+			"--blobstore-bucket", "some-bucket",
+			"--blobstore-product-path", "products",
+			"--blobstore-stemcell-path", "stemcells",
+			"--download-directory", tempDir,
+		}, args...))
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "om-tests-")
+		Expect(err).ToNot(HaveOccurred())
+		manifestFile = filepath.Join(tempDir, "products.yml")
+
+		pivnetFiles = map[string]string{
+			"product-files/cf-2.7.5-build.1.pivotal":              "cf 2.7.5",
+			"product-files/light-bosh-stemcell-456.30-google.tgz": "stemcell 456.30",
+			"product-files/pivotal-mysql-2.7.1.pivotal":           "mysql 2.7.1",
+		}
+
+		fakeProductDownloader = &fakes.ProductDownloader{}
+		fakeProductDownloader.NameReturns("pivnet")
+		fakeProductDownloader.GetAllProductVersionsStub = func(slug string) ([]string, error) {
+			switch slug {
+			case "cf":
+				return []string{"2.7.4", "2.7.5", "2.8.0"}, nil
+			case "p-mysql":
+				return []string{"2.7.1"}, nil
+			}
+			return nil, fmt.Errorf("no releases of %s", slug)
+		}
+		fakeProductDownloader.GetLatestProductFileStub = func(slug, version, glob string) (commands.FileArtifacter, error) {
+			var names []string
+			for name := range pivnetFiles {
+				if matched, _ := filepath.Match(glob, path.Base(name)); matched && strings.Contains(name, version) {
+					names = append(names, name)
+				}
+			}
+			if len(names) != 1 {
+				return nil, fmt.Errorf("the glob '%s' matches %d files", glob, len(names))
+			}
+
+			fa := &fakes.FileArtifacter{}
+			fa.NameReturns(names[0])
+			fa.SHA256Returns(sha(pivnetFiles[names[0]]))
+			return fa, nil
+		}
+		fakeProductDownloader.DownloadProductToFileStub = func(fa commands.FileArtifacter, file *os.File) error {
+			_, err := file.WriteString(pivnetFiles[fa.Name()])
+			return err
+		}
+		fakeProductDownloader.GetLatestStemcellForProductStub = func(fa commands.FileArtifacter, _ string, _ version.Constraints) (commands.StemcellArtifacter, error) {
+			stemcell := &fakes.StemcellArtifacter{}
+			stemcell.SlugReturns("stemcells-ubuntu-xenial")
+			stemcell.VersionReturns("456.30")
+			return stemcell, nil
+		}
+
+		commands.RegisterProductClient("pivnet", func(c commands.DownloadProductOptions, progressWriter io.Writer, stdout *log.Logger, stderr *log.Logger) (commands.ProductDownloader, error) {
+			clientOptions = c
+			return fakeProductDownloader, nil
+		})
+
+		bucket = map[string]string{}
+		blobstore = &fakes.Blobstore{}
+		blobstore.NameReturns("s3")
+		blobstore.PutStub = func(name string, r io.Reader, size int64) error {
+			contents, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			Expect(int64(len(contents))).To(Equal(size))
+			bucket[name] = string(contents)
+			return nil
+		}
+		blobstore.OpenStub = func(name string) (io.ReadCloser, int64, error) {
+			contents, ok := bucket[name]
+			if !ok {
+				return nil, 0, fmt.Errorf("%s not found", name)
+			}
+			return ioutil.NopCloser(strings.NewReader(contents)), int64(len(contents)), nil
+		}
+		blobstore.ListStub = func(prefix string) ([]commands.BlobstoreFile, error) {
+			var files []commands.BlobstoreFile
+			for name, contents := range bucket {
+				if strings.HasPrefix(name, prefix) {
+					files = append(files, commands.BlobstoreFile{Name: name, Size: int64(len(contents))})
+				}
+			}
+			return files, nil
+		}
+
+		commands.RegisterBlobstore("s3", func(o commands.BlobstoreOptions) (commands.Blobstore, error) {
+			blobstoreOptions = o
+			return blobstore, nil
+		})
+
+		buffer = gbytes.NewBuffer()
+		command = commands.NewMirrorProducts(
+			func() []string { return nil },
+			log.New(buffer, "", 0),
+			log.New(buffer, "", 0),
+			ioutil.Discard,
+		)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tempDir)).To(Succeed())
+	})
+
+	bucketNames := func() []string {
+		var names []string
+		for name := range bucket {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	It("uploads the products and their stemcells with the names download-product expects", func() {
+		writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  pivnet-file-glob: "cf-*.pivotal"
+  product-version-constraint: "~> 2.7.0"
+  stemcell-iaas: google
+`)
+
+		err := execute()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(clientOptions.PivnetToken).To(Equal("token"))
+		Expect(blobstoreOptions.Bucket).To(Equal("some-bucket"))
+
+		Expect(bucketNames()).To(Equal([]string{
+			"products/[cf,2.7.5]cf-2.7.5-build.1.pivotal",
+			"products/[cf,2.7.5]cf-2.7.5-build.1.pivotal.sha256",
+			"stemcells/[stemcells-ubuntu-xenial,456.30]light-bosh-stemcell-456.30-google.tgz",
+			"stemcells/[stemcells-ubuntu-xenial,456.30]light-bosh-stemcell-456.30-google.tgz.sha256",
+		}))
+		Expect(bucket["products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"]).To(Equal("cf 2.7.5"))
+		Expect(bucket["products/[cf,2.7.5]cf-2.7.5-build.1.pivotal.sha256"]).To(Equal(sha("cf 2.7.5") + "  [cf,2.7.5]cf-2.7.5-build.1.pivotal\n"))
+		Expect(bucket["stemcells/[stemcells-ubuntu-xenial,456.30]light-bosh-stemcell-456.30-google.tgz"]).To(Equal("stemcell 456.30"))
+
+		Expect(buffer).To(gbytes.Say(`products/\[cf,2.7.5\]cf-2.7.5-build.1.pivotal\s+\|\s+uploaded`))
+
+		files, err := ioutil.ReadDir(tempDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(1), "only the manifest should be left in the download directory")
+	})
+
+	It("skips files that are in the bucket with the same checksum", func() {
+		bucket["products/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal"] = "mysql 2.7.1"
+		bucket["products/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal.sha256"] = sha("mysql 2.7.1") + "  [p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal\n"
+
+		writeManifest(`---
+products:
+- pivnet-product-slug: p-mysql
+  pivnet-file-glob: "*.pivotal"
+  product-version: 2.7.1
+`)
+
+		err := execute()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(0))
+		Expect(blobstore.PutCallCount()).To(Equal(0))
+		Expect(buffer).To(gbytes.Say(`already mirrored`))
+	})
+
+	It("calculates the checksum of files in the bucket without a checksum file", func() {
+		bucket["products/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal"] = "mysql 2.7.1"
+
+		writeManifest(`---
+products:
+- pivnet-product-slug: p-mysql
+  pivnet-file-glob: "*.pivotal"
+  product-version: 2.7.1
+`)
+
+		err := execute()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(0))
+		Expect(bucket["products/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal.sha256"]).To(Equal(sha("mysql 2.7.1") + "  [p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal\n"))
+	})
+
+	It("uploads files in the bucket again when their checksum is different", func() {
+		bucket["products/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal"] = "corrupted"
+		bucket["products/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal.sha256"] = sha("corrupted") + "  [p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal\n"
+
+		writeManifest(`---
+products:
+- pivnet-product-slug: p-mysql
+  pivnet-file-glob: "*.pivotal"
+  product-version: 2.7.1
+`)
+
+		err := execute()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(1))
+		Expect(bucket["products/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal"]).To(Equal("mysql 2.7.1"))
+		Expect(buffer).To(gbytes.Say(`does not match the one from Pivotal Network`))
+	})
+
+	It("mirrors the other products when one fails, and returns an error", func() {
+		blobstore.PutStub = func(name string, r io.Reader, size int64) error {
+			if strings.Contains(name, "p-mysql") {
+				return errors.New("access denied")
+			}
+			contents, err := ioutil.ReadAll(r)
+			bucket[name] = string(contents)
+			return err
+		}
+
+		writeManifest(`---
+products:
+- pivnet-product-slug: p-mysql
+  pivnet-file-glob: "*.pivotal"
+  product-version: 2.7.1
+- pivnet-product-slug: cf
+  pivnet-file-glob: "cf-*.pivotal"
+  product-version-regex: ^2\.7\.
+`)
+
+		err := execute()
+		Expect(err).To(MatchError("failed to mirror 1 of 2 files"))
+
+		Expect(bucket).To(HaveKey("products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"))
+		Expect(buffer).To(gbytes.Say(`could not upload products/\[p-mysql,2.7.1\]pivotal-mysql-2.7.1.pivotal: access denied`))
+	})
+
+	It("does not upload a file that does not match the checksum from Pivotal Network", func() {
+		fakeProductDownloader.DownloadProductToFileStub = func(fa commands.FileArtifacter, file *os.File) error {
+			_, err := file.WriteString("corrupted")
+			return err
+		}
+
+		writeManifest(`---
+products:
+- pivnet-product-slug: p-mysql
+  pivnet-file-glob: "*.pivotal"
+  product-version: 2.7.1
+`)
+
+		err := execute()
+		Expect(err).To(MatchError("failed to mirror 1 of 1 files"))
+		Expect(bucket).To(BeEmpty())
+	})
+
+	When("the manifest is not valid", func() {
+		It("returns an error before connecting to the blobstore", func() {
+			writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  pivnet-file-glob: "*.pivotal"
+`)
+
+			err := execute()
+			Expect(err).To(MatchError(ContainSubstring(`"product-version", "product-version-regex" or "product-version-constraint" is required for products[0]`)))
+			Expect(blobstore.ListCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the bucket is not set", func() {
+		It("returns an error", func() {
+			writeManifest(`---
+products:
+- pivnet-product-slug: cf
+  pivnet-file-glob: "*.pivotal"
+  product-version: 2.7.5
+`)
+
+			err := command.Execute([]string{
+				"--manifest", manifestFile,
+				"--pivnet-api-token", "token",
+				"--blobstore", "s3",
+			})
+			Expect(err).To(MatchError("--blobstore-bucket is required when using the s3 blobstore"))
+		})
+	})
+})
@@ -1,14 +1,17 @@
 package commands
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"archive/zip"
 
 	"regexp"
 
 	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/extractor"
 	"gopkg.in/yaml.v2"
 )
 
@@ -18,6 +21,7 @@ type ProductMetadata struct {
 		ProductPath    string `long:"product-path" short:"p"   required:"true" description:"path to product file"`
 		ProductName    bool   `long:"product-name"  description:"show product name"`
 		ProductVersion bool   `long:"product-version"  description:"show product version"`
+		Format         string `long:"format" short:"f" description:"Format to print everything the product metadata says about its requirements and what it deploys as (options: json,yaml). Cannot be used with --product-name or --product-version"`
 	}
 
 	deprecatedCommandName bool
@@ -40,6 +44,10 @@ func (t ProductMetadata) Execute(args []string) error {
 		return fmt.Errorf("could not parse product-metadata flags: %s", err)
 	}
 
+	if t.Options.Format != "" {
+		return t.inspect()
+	}
+
 	if !t.Options.ProductName && !t.Options.ProductVersion {
 		return errors.New("you must specify product-name and/or product-version")
 	}
@@ -60,9 +68,45 @@ func (t ProductMetadata) Execute(args []string) error {
 	return nil
 }
 
+// inspect prints what the metadata says about the requirements of the
+// product and what it deploys.
+func (t ProductMetadata) inspect() error {
+	if t.Options.ProductName || t.Options.ProductVersion {
+		return errors.New("--format cannot be used with --product-name or --product-version")
+	}
+
+	if t.Options.Format != "json" && t.Options.Format != "yaml" {
+		return fmt.Errorf("unsupported format %q: must be one of json,yaml", t.Options.Format)
+	}
+
+	metadata, err := extractor.MetadataExtractor{}.ExtractMetadata(t.Options.ProductPath)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata: %s", err)
+	}
+
+	inspection, err := extractor.InspectMetadata(metadata.Raw)
+	if err != nil {
+		return fmt.Errorf("failed to inspect metadata: %s", err)
+	}
+
+	var output []byte
+	if t.Options.Format == "json" {
+		output, err = json.MarshalIndent(inspection, "", "  ")
+	} else {
+		output, err = yaml.Marshal(inspection)
+	}
+	if err != nil {
+		return err
+	}
+
+	t.stdout.Println(strings.TrimSuffix(string(output), "\n"))
+
+	return nil
+}
+
 func (t ProductMetadata) Usage() jhanda.Usage {
 	usage := jhanda.Usage{
-		Description:      "This command prints metadata about the given product",
+		Description:      "This command prints metadata about the given product. With --format, it prints what the product requires, such as its stemcell, Ops Manager version and other products, and what it deploys, such as its BOSH releases, job types and errands, all read from the product file",
 		ShortDescription: "prints product metadata",
 		Flags:            t.Options,
 	}
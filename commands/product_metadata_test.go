@@ -80,6 +80,135 @@ product_version: 1.2.3
 			Expect(content).To(ContainElement("1.2.3"))
 		})
 
+		When("a format is given", func() {
+			BeforeEach(func() {
+				productFile, err = ioutil.TempFile("", "fake-tile")
+				Expect(err).ToNot(HaveOccurred())
+				z := zip.NewWriter(productFile)
+
+				f, err := z.Create("metadata/fake-tile.yml")
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = f.Write([]byte(`
+name: fake-tile
+product_version: 1.2.3
+stemcell_criteria:
+  os: ubuntu-xenial
+  version: "456.30"
+requires_product_versions:
+- name: p-bosh
+  version: ~> 2.7.0
+- name: cf
+  version: ">= 2.6"
+releases:
+- name: some-release
+  version: 1.2.3
+  file: some-release-1.2.3.tgz
+job_types:
+- name: server
+  instance_definition:
+    default: 3
+post_deploy_errands:
+- name: smoke_tests
+`))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(z.Close()).To(Succeed())
+			})
+
+			It("prints the inspection of the tile as json", func() {
+				err = command.Execute([]string{
+					"-p", productFile.Name(),
+					"--format", "json",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				content := stdout.PrintlnArgsForCall(0)
+				Expect(content).To(HaveLen(1))
+				Expect(content[0]).To(MatchJSON(`{
+					"name": "fake-tile",
+					"version": "1.2.3",
+					"ops_manager_version": "~> 2.7.0",
+					"minimum_ops_manager_version": "2.7.0",
+					"stemcell_criteria": {"os": "ubuntu-xenial", "version": "456.30"},
+					"product_dependencies": [{"name": "cf", "version": ">= 2.6"}],
+					"releases": [{"name": "some-release", "version": "1.2.3", "file": "some-release-1.2.3.tgz"}],
+					"job_types": [{"name": "server", "instances": 3, "property_blueprints": 0}],
+					"errands": {"post_deploy": ["smoke_tests"], "pre_delete": []},
+					"property_blueprints": {"product": 0, "configurable": 0, "jobs": 0}
+				}`))
+			})
+
+			It("prints the inspection of the tile as yaml", func() {
+				err = command.Execute([]string{
+					"-p", productFile.Name(),
+					"-f", "yaml",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				content := stdout.PrintlnArgsForCall(0)
+				Expect(content).To(HaveLen(1))
+				Expect(content[0]).To(MatchYAML(`
+name: fake-tile
+version: 1.2.3
+ops_manager_version: ~> 2.7.0
+minimum_ops_manager_version: 2.7.0
+stemcell_criteria:
+  os: ubuntu-xenial
+  version: "456.30"
+product_dependencies:
+- name: cf
+  version: ">= 2.6"
+releases:
+- name: some-release
+  version: 1.2.3
+  file: some-release-1.2.3.tgz
+job_types:
+- name: server
+  instances: 3
+  property_blueprints: 0
+errands:
+  post_deploy: [smoke_tests]
+  pre_delete: []
+property_blueprints:
+  product: 0
+  configurable: 0
+  jobs: 0
+`))
+			})
+
+			It("returns an error for an unsupported format", func() {
+				err = command.Execute([]string{"-p", productFile.Name(), "--format", "text"})
+				Expect(err).To(MatchError(`unsupported format "text": must be one of json,yaml`))
+			})
+
+			It("returns an error when the product name or version is also asked for", func() {
+				err = command.Execute([]string{"-p", productFile.Name(), "--format", "json", "--product-name"})
+				Expect(err).To(MatchError("--format cannot be used with --product-name or --product-version"))
+			})
+
+			It("returns an error when the metadata cannot be read", func() {
+				err = command.Execute([]string{"-p", "non-existent-file", "--format", "json"})
+				Expect(err).To(MatchError(MatchRegexp("^failed to get metadata: ")))
+			})
+
+			It("returns an error when the metadata cannot be inspected", func() {
+				badTile, err := ioutil.TempFile("", "bad-tile")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.Remove(badTile.Name())
+
+				z := zip.NewWriter(badTile)
+				f, err := z.Create("metadata/bad-tile.yml")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = f.Write([]byte("name: bad-tile\nproduct_version: 1.2.3\njob_types: not-a-list\n"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(z.Close()).To(Succeed())
+
+				err = command.Execute([]string{"-p", badTile.Name(), "--format", "json"})
+				Expect(err).To(MatchError(MatchRegexp("^failed to inspect metadata: ")))
+			})
+		})
+
 		Context("failure cases", func() {
 			When("the flags cannot be parsed", func() {
 				It("returns an error", func() {
@@ -139,7 +268,7 @@ product_version: 1.2.3
 		It("returns the usage information for the product-metadata command", func() {
 			command = commands.NewProductMetadata(stdout)
 			Expect(command.Usage()).To(Equal(jhanda.Usage{
-				Description:      "This command prints metadata about the given product",
+				Description:      "This command prints metadata about the given product. With --format, it prints what the product requires, such as its stemcell, Ops Manager version and other products, and what it deploys, such as its BOSH releases, job types and errands, all read from the product file",
 				ShortDescription: "prints product metadata",
 				Flags:            command.Options,
 			}))
@@ -148,7 +277,7 @@ product_version: 1.2.3
 		It("returns the usage information for the tile-metadata command", func() {
 			command = commands.NewDeprecatedProductMetadata(stdout)
 			Expect(command.Usage()).To(Equal(jhanda.Usage{
-				Description:      "*** DEPRECATED *** use 'product-metadata' instead\nThis command prints metadata about the given product",
+				Description:      "*** DEPRECATED *** use 'product-metadata' instead\nThis command prints metadata about the given product. With --format, it prints what the product requires, such as its stemcell, Ops Manager version and other products, and what it deploys, such as its BOSH releases, job types and errands, all read from the product file",
 				ShortDescription: "**DEPRECATED** prints product metadata. Use product-metadata instead",
 				Flags:            command.Options,
 			}))
@@ -79,15 +79,55 @@ func (ec StagedConfig) Execute(args []string) error {
 		}
 	}
 
-	findOutput, err := ec.service.GetStagedProductByName(ec.Options.Product)
+	productConfig, err := getStagedProductConfig(ec.service, info, ec.Options.Product, ec.chooseCredentialHandler)
 	if err != nil {
 		return err
 	}
+
+	output, err := yaml.Marshal(productConfig)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal config: %s", err) // un-tested
+	}
+
+	ec.logger.Println(string(output))
+	return nil
+}
+
+func (ec StagedConfig) chooseCredentialHandler(productGUID string) configparser.CredentialHandler {
+	if ec.Options.IncludePlaceholders {
+		return configparser.NewPlaceholderHandler()
+	}
+
+	if ec.Options.IncludeCredentials {
+		return configparser.NewGetCredentialHandler(productGUID, ec.service)
+	}
+
+	return configparser.NewNilHandler()
+}
+
+type stagedProductConfigReader interface {
+	GetStagedProductByName(product string) (api.StagedProductsFindOutput, error)
+	GetStagedProductJobResourceConfig(productGUID, jobGUID string) (api.JobProperties, error)
+	GetStagedProductNetworksAndAZs(product string) (map[string]interface{}, error)
+	GetStagedProductSyslogConfiguration(product string) (map[string]interface{}, error)
+	GetStagedProductProperties(product string) (map[string]api.ResponseProperty, error)
+	ListStagedProductJobs(productGUID string) (map[string]string, error)
+	ListStagedProductErrands(productID string) (api.ErrandsListOutput, error)
+	GetStagedProductJobMaxInFlight(productGUID string) (map[string]interface{}, error)
+}
+
+// getStagedProductConfig reads the staged state of a product and returns it
+// in the same shape that configure-product accepts.
+func getStagedProductConfig(service stagedProductConfigReader, info api.Info, productName string, chooseCredentialHandler func(productGUID string) configparser.CredentialHandler) (config.ProductConfiguration, error) {
+	findOutput, err := service.GetStagedProductByName(productName)
+	if err != nil {
+		return config.ProductConfiguration{}, err
+	}
 	productGUID := findOutput.Product.GUID
 
-	properties, err := ec.service.GetStagedProductProperties(productGUID)
+	properties, err := service.GetStagedProductProperties(productGUID)
 	if err != nil {
-		return err
+		return config.ProductConfiguration{}, err
 	}
 
 	configurableProperties := map[string]interface{}{}
@@ -108,10 +148,10 @@ func (ec StagedConfig) Execute(args []string) error {
 
 		parser := configparser.NewConfigParser()
 		propertyName := configparser.NewPropertyName(name)
-		output, err = parser.ParseProperties(propertyName, property, ec.chooseCredentialHandler(productGUID))
+		output, err = parser.ParseProperties(propertyName, property, chooseCredentialHandler(productGUID))
 
 		if err != nil {
-			return err
+			return config.ProductConfiguration{}, err
 		}
 		if output != nil && len(output) > 0 {
 			configurableProperties[name] = output
@@ -129,35 +169,35 @@ func (ec StagedConfig) Execute(args []string) error {
 		}
 	}
 
-	networks, err := ec.service.GetStagedProductNetworksAndAZs(productGUID)
+	networks, err := service.GetStagedProductNetworksAndAZs(productGUID)
 	if err != nil {
-		return err
+		return config.ProductConfiguration{}, err
 	}
 
-	jobs, err := ec.service.ListStagedProductJobs(productGUID)
+	jobs, err := service.ListStagedProductJobs(productGUID)
 	if err != nil {
-		return err
+		return config.ProductConfiguration{}, err
 	}
 
-	jobsToMaxInFlight, err := ec.service.GetStagedProductJobMaxInFlight(productGUID)
+	jobsToMaxInFlight, err := service.GetStagedProductJobMaxInFlight(productGUID)
 	if err != nil {
-		return err
+		return config.ProductConfiguration{}, err
 	}
 
 	var syslogProperties map[string]interface{}
 	if ok, _ := info.VersionAtLeast(2, 4); ok {
-		syslogProperties, err = ec.service.GetStagedProductSyslogConfiguration(productGUID)
+		syslogProperties, err = service.GetStagedProductSyslogConfiguration(productGUID)
 		if err != nil {
-			return err
+			return config.ProductConfiguration{}, err
 		}
 	}
 
 	resourceConfig := map[string]config.ResourceConfig{}
 
 	for name, jobGUID := range jobs {
-		jobProperties, err := ec.service.GetStagedProductJobResourceConfig(productGUID, jobGUID)
+		jobProperties, err := service.GetStagedProductJobResourceConfig(productGUID, jobGUID)
 		if err != nil {
-			return err
+			return config.ProductConfiguration{}, err
 		}
 		rc := config.ResourceConfig{
 			JobProperties: jobProperties,
@@ -168,9 +208,9 @@ func (ec StagedConfig) Execute(args []string) error {
 		resourceConfig[name] = rc
 	}
 
-	errandsListOutput, err := ec.service.ListStagedProductErrands(productGUID)
+	errandsListOutput, err := service.ListStagedProductErrands(productGUID)
 	if err != nil {
-		return err
+		return config.ProductConfiguration{}, err
 	}
 
 	errandConfigs := map[string]config.ErrandConfig{}
@@ -183,32 +223,12 @@ func (ec StagedConfig) Execute(args []string) error {
 		errandConfigs[errand.Name] = errandConfig
 	}
 
-	config := config.ProductConfiguration{
-		ProductName:              ec.Options.Product,
+	return config.ProductConfiguration{
+		ProductName:              productName,
 		ProductProperties:        configurableProperties,
 		NetworkProperties:        networks,
 		ResourceConfigProperties: resourceConfig,
 		ErrandConfigs:            errandConfigs,
 		SyslogProperties:         syslogProperties,
-	}
-
-	output, err := yaml.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal config: %s", err) // un-tested
-	}
-
-	ec.logger.Println(string(output))
-	return nil
-}
-
-func (ec StagedConfig) chooseCredentialHandler(productGUID string) configparser.CredentialHandler {
-	if ec.Options.IncludePlaceholders {
-		return configparser.NewPlaceholderHandler()
-	}
-
-	if ec.Options.IncludeCredentials {
-		return configparser.NewGetCredentialHandler(productGUID, ec.service)
-	}
-
-	return configparser.NewNilHandler()
+	}, nil
 }
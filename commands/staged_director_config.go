@@ -60,51 +60,71 @@ func (sdc StagedDirectorConfig) Execute(args []string) error {
 		return fmt.Errorf("could not parse staged-config flags: %s", err)
 	}
 
-	stagedDirector, err := sdc.service.GetStagedProductByName("p-bosh")
+	config, err := sdc.stagedDirectorConfig()
+	if err != nil {
+		return err
+	}
+
+	configYaml, err := yaml.Marshal(config)
 	if err != nil {
 		return err
 	}
 
+	sdc.stdout.Println(string(configYaml))
+	if !sdc.Options.NoRedact {
+		sdc.stderr.Println("NOTE: Because `--no-redact` has not been provided, the `iaas-configurations` and other credentials will be hidden.")
+	}
+	return nil
+}
+
+// stagedDirectorConfig reads the staged director and returns it in the same
+// shape that configure-director accepts, with secrets handled per Options.
+func (sdc StagedDirectorConfig) stagedDirectorConfig() (map[string]interface{}, error) {
+	stagedDirector, err := sdc.service.GetStagedProductByName("p-bosh")
+	if err != nil {
+		return nil, err
+	}
+
 	directorGUID := stagedDirector.Product.GUID
 
 	azs, err := sdc.service.GetStagedDirectorAvailabilityZones()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	properties, err := sdc.service.GetStagedDirectorProperties(!sdc.Options.NoRedact)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	multiIaasConfigs, err := sdc.service.GetStagedDirectorIaasConfigurations(!sdc.Options.NoRedact)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	networks, err := sdc.service.GetStagedDirectorNetworks()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	assignedNetworkAZ, err := sdc.service.GetStagedProductNetworksAndAZs(directorGUID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	jobs, err := sdc.service.ListStagedProductJobs(directorGUID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	vmExtensions, err := sdc.service.ListStagedVMExtensions()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	vmTypes, err := sdc.service.ListVMTypes()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(vmTypes) > 0 && vmTypes[0].BuiltIn {
@@ -145,7 +165,7 @@ func (sdc StagedDirectorConfig) Execute(args []string) error {
 
 	resourceConfigs, err := sdc.getResourceConfigs(jobs, directorGUID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	config["resource-configuration"] = resourceConfigs
 
@@ -156,23 +176,14 @@ func (sdc StagedDirectorConfig) Execute(args []string) error {
 	for key, value := range config {
 		returnedVal, err := sdc.filterSecrets(key, key, value)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if returnedVal != nil {
 			config[key] = returnedVal
 		}
 	}
 
-	configYaml, err := yaml.Marshal(config)
-	if err != nil {
-		return err
-	}
-
-	sdc.stdout.Println(string(configYaml))
-	if !sdc.Options.NoRedact {
-		sdc.stderr.Println("NOTE: Because `--no-redact` has not been provided, the `iaas-configurations` and other credentials will be hidden.")
-	}
-	return nil
+	return config, nil
 }
 
 func (sdc StagedDirectorConfig) removePropertiesIAASConfig(config map[string]interface{}, multiIaasConfigs map[string][]map[string]interface{}, properties map[string]interface{}) {
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pivotal-cf/om/formcontent"
+)
+
+const (
+	uploadResultUploaded = "uploaded"
+	uploadResultSkipped  = "skipped"
+	uploadResultFailed   = "failed"
+)
+
+// findArtifacts expands each of paths, which can be a file, a directory of
+// files with the extension ext, or a glob, into the files to upload.
+func findArtifacts(paths []string, ext string) ([]string, error) {
+	var artifacts []string
+	seen := map[string]bool{}
+
+	add := func(artifact string) {
+		if !seen[artifact] {
+			seen[artifact] = true
+			artifacts = append(artifacts, artifact)
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+
+		// a path that exists is not a glob, as the files from download-product
+		// have a prefix such as "[ubuntu-xenial,97.88]"
+		if err != nil && strings.ContainsAny(path, "*?[") {
+			matches, err := filepath.Glob(path)
+			if err != nil {
+				return nil, fmt.Errorf("could not expand %q: %s", path, err)
+			}
+
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", path)
+			}
+
+			sort.Strings(matches)
+			for _, match := range matches {
+				add(match)
+			}
+			continue
+		}
+
+		if err != nil || !info.IsDir() {
+			add(path)
+			continue
+		}
+
+		infos, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read directory %s: %s", path, err)
+		}
+
+		found := false
+		for _, info := range infos {
+			if !info.IsDir() && filepath.Ext(info.Name()) == ext {
+				add(filepath.Join(path, info.Name()))
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no %s files in directory %s", ext, path)
+		}
+	}
+
+	return artifacts, nil
+}
+
+// newMultipartForm gives each of several uploads a form of its own, as a form
+// cannot be shared by uploads running at once.
+func newMultipartForm() multipart {
+	return formcontent.NewForm()
+}
+
+type uploadSummary struct {
+	artifact string
+	result   string
+	err      error
+}
+
+// uploadArtifacts uploads each artifact, with at most concurrency at once,
+// and prints a summary of what was uploaded, skipped and failed. A failed
+// upload does not stop the others.
+func uploadArtifacts(artifacts []string, concurrency int, kind string, logger logger, upload func(artifact string) (bool, error)) error {
+	summaries := make([]uploadSummary, len(artifacts))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				summaries[index].artifact = artifacts[index]
+				logger.Printf("uploading %s", artifacts[index])
+
+				skipped, err := upload(artifacts[index])
+				switch {
+				case err != nil:
+					summaries[index].result = uploadResultFailed
+					summaries[index].err = err
+				case skipped:
+					summaries[index].result = uploadResultSkipped
+				default:
+					summaries[index].result = uploadResultUploaded
+				}
+			}
+		}()
+	}
+
+	for index := range artifacts {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	var output bytes.Buffer
+	table := tablewriter.NewWriter(&output)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{strings.Title(kind), "Result", "Error"})
+
+	failed := 0
+	for _, summary := range summaries {
+		var message string
+		if summary.err != nil {
+			failed++
+			message = summary.err.Error()
+		}
+		table.Append([]string{summary.artifact, summary.result, message})
+	}
+	table.Render()
+
+	logger.Print(output.String())
+
+	if failed > 0 {
+		return fmt.Errorf("failed to upload %d of %d %ss", failed, len(artifacts), kind)
+	}
+
+	return nil
+}
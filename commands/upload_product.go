@@ -13,15 +13,18 @@ import (
 const maxProductUploadRetries = 2
 
 type UploadProduct struct {
-	multipart multipart
-	logger    logger
-	service   uploadProductService
-	Options   struct {
-		ConfigFile      string `long:"config"           short:"c"   description:"path to yml file for configuration (keys must match the following command line flags)"`
-		Product         string `long:"product"          short:"p"   description:"path to product" required:"true"`
-		PollingInterval int    `long:"polling-interval" short:"pi"  description:"interval (in seconds) at which to print status" default:"1"`
-		Shasum          string `long:"shasum"                       description:"shasum of the provided product file to be used for validation"`
-		Version         string `long:"product-version"              description:"version of the provided product file to be used for validation"`
+	multipart    multipart
+	newMultipart func() multipart
+	logger       logger
+	service      uploadProductService
+	Options      struct {
+		ConfigFile         string   `long:"config"           short:"c"   description:"path to yml file for configuration (keys must match the following command line flags)"`
+		Product            []string `long:"product"          short:"p"   description:"path to product, a directory of .pivotal files, or a glob. Can be given more than once" required:"true"`
+		Concurrency        int      `long:"concurrency"                  description:"when uploading more than one product, how many to upload at once" default:"1"`
+		PollingInterval    int      `long:"polling-interval" short:"pi"  description:"interval (in seconds) at which to print status" default:"1"`
+		Shasum             string   `long:"shasum"                       description:"shasum of the provided product file to be used for validation"`
+		Version            string   `long:"product-version"              description:"version of the provided product file to be used for validation"`
+		CheckCompatibility bool     `long:"check-compatibility"          description:"before uploading, check that the Ops Manager version, the deployed or staged products and the uploaded stemcells satisfy what the product requires, as check-product-compatibility does, only warning about stemcells that are not uploaded yet"`
 	}
 	metadataExtractor metadataExtractor
 }
@@ -30,6 +33,8 @@ type UploadProduct struct {
 type uploadProductService interface {
 	UploadAvailableProduct(api.UploadAvailableProductInput) (api.UploadAvailableProductOutput, error)
 	CheckProductAvailability(string, string) (bool, error)
+	Info() (api.Info, error)
+	GetDiagnosticReport() (api.DiagnosticReport, error)
 }
 
 //counterfeiter:generate -o ./fakes/metadata_extractor.go --fake-name MetadataExtractor . metadataExtractor
@@ -40,6 +45,7 @@ type metadataExtractor interface {
 func NewUploadProduct(multipart multipart, metadataExtractor metadataExtractor, service uploadProductService, logger logger) UploadProduct {
 	return UploadProduct{
 		multipart:         multipart,
+		newMultipart:      newMultipartForm,
 		metadataExtractor: metadataExtractor,
 		logger:            logger,
 		service:           service,
@@ -60,29 +66,68 @@ func (up UploadProduct) Execute(args []string) error {
 		return fmt.Errorf("could not parse upload-product flags: %s", err)
 	}
 
+	// --product can be given more than once, so those on the command line
+	// replace those in the config file rather than being added to them
+	if up.Options.ConfigFile != "" {
+		var commandLine UploadProduct
+		err = parseCommandLineFlags(&commandLine.Options, args)
+		if err != nil {
+			return fmt.Errorf("could not parse upload-product flags: %s", err)
+		}
+		if len(commandLine.Options.Product) > 0 {
+			up.Options.Product = commandLine.Options.Product
+		}
+	}
+
+	if up.Options.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	products, err := findArtifacts(up.Options.Product, ".pivotal")
+	if err != nil {
+		return err
+	}
+
+	if len(products) == 1 {
+		_, err = up.uploadProduct(products[0], up.multipart, false)
+		return err
+	}
+
+	if up.Options.Shasum != "" || up.Options.Version != "" {
+		return fmt.Errorf("--shasum and --product-version cannot be used when uploading more than one product")
+	}
+
+	return uploadArtifacts(products, up.Options.Concurrency, "product", up.logger, func(product string) (bool, error) {
+		return up.uploadProduct(product, up.newMultipart(), up.Options.Concurrency > 1)
+	})
+}
+
+// uploadProduct uploads a product with its own form, so that several can be
+// uploaded at once. It returns true when the product was already uploaded.
+func (up UploadProduct) uploadProduct(product string, form multipart, hideProgress bool) (bool, error) {
 	if up.Options.Shasum != "" {
 		shaValidator := validator.NewSHA256Calculator()
-		shasum, err := shaValidator.Checksum(up.Options.Product)
+		shasum, err := shaValidator.Checksum(product)
 
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		if shasum != up.Options.Shasum {
-			return fmt.Errorf("expected shasum %s does not match file shasum %s", up.Options.Shasum, shasum)
+			return false, fmt.Errorf("expected shasum %s does not match file shasum %s", up.Options.Shasum, shasum)
 		}
 
 		up.logger.Printf("expected shasum matches product shasum.")
 	}
 
-	metadata, err := up.metadataExtractor.ExtractMetadata(up.Options.Product)
+	metadata, err := up.metadataExtractor.ExtractMetadata(product)
 	if err != nil {
-		return fmt.Errorf("failed to extract product metadata: %s", err)
+		return false, fmt.Errorf("failed to extract product metadata: %s", err)
 	}
 
 	if up.Options.Version != "" {
 		if up.Options.Version != metadata.Version {
-			return fmt.Errorf("expected version %s does not match product version %s", up.Options.Version, metadata.Version)
+			return false, fmt.Errorf("expected version %s does not match product version %s", up.Options.Version, metadata.Version)
 		}
 		up.logger.Printf("expected version matches product version.")
 	}
@@ -90,23 +135,30 @@ func (up UploadProduct) Execute(args []string) error {
 	var prodAvailable bool
 	prodAvailable, err = up.service.CheckProductAvailability(metadata.Name, metadata.Version)
 	if err != nil {
-		return fmt.Errorf("failed to check product availability: %s", err)
+		return false, fmt.Errorf("failed to check product availability: %s", err)
 	}
 
 	if prodAvailable {
 		up.logger.Printf("product %s %s is already uploaded, nothing to be done", metadata.Name, metadata.Version)
-		return nil
+		return true, nil
+	}
+
+	if up.Options.CheckCompatibility {
+		err = checkProductCompatibility(up.service, up.logger, metadata, false)
+		if err != nil {
+			return false, err
+		}
 	}
 
 	for i := 0; i <= maxProductUploadRetries; i++ {
 		up.logger.Printf("processing product")
 
-		err = up.multipart.AddFile("product[file]", up.Options.Product)
+		err = form.AddFile("product[file]", product)
 		if err != nil {
-			return fmt.Errorf("failed to load product: %s", err)
+			return false, fmt.Errorf("failed to load product: %s", err)
 		}
 
-		submission := up.multipart.Finalize()
+		submission := form.Finalize()
 
 		up.logger.Printf("beginning product upload to Ops Manager")
 
@@ -115,28 +167,29 @@ func (up UploadProduct) Execute(args []string) error {
 			ContentType:     submission.ContentType,
 			ContentLength:   submission.ContentLength,
 			PollingInterval: up.Options.PollingInterval,
+			HideProgress:    hideProgress,
 		})
 		if network.CanRetry(err) && i < maxProductUploadRetries {
 			up.logger.Printf("retrying product upload after error: %s\n", err)
-			up.multipart.Reset()
+			form.Reset()
 
 			prodAvailable, err = up.service.CheckProductAvailability(metadata.Name, metadata.Version)
 			if err != nil {
-				return fmt.Errorf("failed to check product availability: %s", err)
+				return false, fmt.Errorf("failed to check product availability: %s", err)
 			}
 			if prodAvailable {
 				up.logger.Printf("product %s %s has been successfully uploaded", metadata.Name, metadata.Version)
-				return nil
+				return false, nil
 			}
 		} else {
 			break
 		}
 	}
 	if err != nil {
-		return fmt.Errorf("failed to upload product: %s", err)
+		return false, fmt.Errorf("failed to upload product: %s", err)
 	}
 
 	up.logger.Printf("finished upload")
 
-	return nil
+	return false, nil
 }
@@ -7,8 +7,10 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
@@ -296,6 +298,234 @@ var _ = Describe("UploadProduct", func() {
 		})
 	})
 
+	When("more than one product is given", func() {
+		var productsDir string
+
+		BeforeEach(func() {
+			var err error
+			productsDir, err = ioutil.TempDir("", "products")
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, name := range []string{"a.pivotal", "b.pivotal", "c.pivotal", "README.md"} {
+				Expect(ioutil.WriteFile(filepath.Join(productsDir, name), []byte("some-product"), 0600)).To(Succeed())
+			}
+
+			metadataExtractor.ExtractMetadataStub = func(product string) (extractor.Metadata, error) {
+				return extractor.Metadata{Name: strings.TrimSuffix(filepath.Base(product), ".pivotal"), Version: "1.0.0"}, nil
+			}
+			fakeService.CheckProductAvailabilityStub = func(name, version string) (bool, error) {
+				return name == "b", nil
+			}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(productsDir)).To(Succeed())
+		})
+
+		readProduct := func(input api.UploadAvailableProductInput) string {
+			contents, err := ioutil.ReadAll(input.Product)
+			Expect(err).ToNot(HaveOccurred())
+			return string(contents)
+		}
+
+		It("uploads each product in a directory, and prints a summary", func() {
+			fakeService.UploadAvailableProductStub = func(input api.UploadAvailableProductInput) (api.UploadAvailableProductOutput, error) {
+				if strings.Contains(readProduct(input), `filename="c.pivotal"`) {
+					return api.UploadAvailableProductOutput{}, errors.New("some upload error")
+				}
+				return api.UploadAvailableProductOutput{}, nil
+			}
+
+			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+
+			err := command.Execute([]string{"--product", productsDir})
+			Expect(err).To(MatchError("failed to upload 1 of 3 products"))
+
+			Expect(multipart.AddFileCallCount()).To(Equal(0))
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(2))
+			Expect(fakeService.UploadAvailableProductArgsForCall(0).HideProgress).To(BeFalse())
+
+			Expect(logger.PrintCallCount()).To(Equal(1))
+			summary := fmt.Sprint(logger.PrintArgsForCall(0))
+			Expect(summary).To(MatchRegexp(`\|\s+\S+/a.pivotal\s+\|\s+uploaded\s+\|\s+\|`))
+			Expect(summary).To(MatchRegexp(`\|\s+\S+/b.pivotal\s+\|\s+skipped\s+\|\s+\|`))
+			Expect(summary).To(MatchRegexp(`\|\s+\S+/c.pivotal\s+\|\s+failed\s+\|\s+failed to upload product: some upload error\s+\|`))
+		})
+
+		It("uploads the products matching a glob, and those given more than once", func() {
+			fakeService.UploadAvailableProductStub = func(input api.UploadAvailableProductInput) (api.UploadAvailableProductOutput, error) {
+				readProduct(input)
+				return api.UploadAvailableProductOutput{}, nil
+			}
+
+			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+
+			err := command.Execute([]string{
+				"--product", filepath.Join(productsDir, "[ab].pivotal"),
+				"--product", filepath.Join(productsDir, "c.pivotal"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(metadataExtractor.ExtractMetadataCallCount()).To(Equal(3))
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(2))
+		})
+
+		It("uploads several at once with --concurrency, without showing progress", func() {
+			started := make(chan bool, 2)
+			bothStarted := make(chan bool)
+			fakeService.UploadAvailableProductStub = func(input api.UploadAvailableProductInput) (api.UploadAvailableProductOutput, error) {
+				readProduct(input)
+				started <- true
+				if len(started) == 2 {
+					close(bothStarted)
+				}
+
+				select {
+				case <-bothStarted:
+				case <-time.After(5 * time.Second):
+					return api.UploadAvailableProductOutput{}, errors.New("the uploads did not run at once")
+				}
+				return api.UploadAvailableProductOutput{}, nil
+			}
+
+			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+
+			err := command.Execute([]string{"--product", productsDir, "--concurrency", "2"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(2))
+			Expect(fakeService.UploadAvailableProductArgsForCall(0).HideProgress).To(BeTrue())
+			Expect(fakeService.UploadAvailableProductArgsForCall(1).HideProgress).To(BeTrue())
+		})
+
+		When("--shasum is given", func() {
+			It("returns an error", func() {
+				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+
+				err := command.Execute([]string{"--product", productsDir, "--shasum", "some-shasum"})
+				Expect(err).To(MatchError("--shasum and --product-version cannot be used when uploading more than one product"))
+			})
+		})
+
+		When("the directory has no products", func() {
+			It("returns an error", func() {
+				emptyDir, err := ioutil.TempDir("", "")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(emptyDir)
+
+				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+
+				err = command.Execute([]string{"--product", emptyDir})
+				Expect(err).To(MatchError("no .pivotal files in directory " + emptyDir))
+			})
+		})
+
+		When("a glob matches nothing", func() {
+			It("returns an error", func() {
+				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+
+				err := command.Execute([]string{"--product", filepath.Join(productsDir, "*.tgz")})
+				Expect(err).To(MatchError(fmt.Sprintf("no files match %q", filepath.Join(productsDir, "*.tgz"))))
+			})
+		})
+
+		When("--concurrency is less than 1", func() {
+			It("returns an error", func() {
+				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+
+				err := command.Execute([]string{"--product", productsDir, "--concurrency", "0"})
+				Expect(err).To(MatchError("--concurrency must be at least 1"))
+			})
+		})
+	})
+
+	When("--check-compatibility is provided", func() {
+		BeforeEach(func() {
+			metadataExtractor.ExtractMetadataReturns(extractor.Metadata{
+				Name:    "some-product",
+				Version: "1.2.3",
+				Raw: []byte(`---
+name: some-product
+product_version: 1.2.3
+requires_product_versions:
+- name: p-bosh
+  version: ~> 2.7.0
+`),
+			}, nil)
+		})
+
+		It("uploads the product when Ops Manager satisfies what it requires", func() {
+			fakeService.InfoReturns(api.Info{Version: "2.7.3-build.12"}, nil)
+
+			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			err := command.Execute([]string{
+				"--product", "/path/to/some-product.tgz",
+				"--check-compatibility",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.GetDiagnosticReportCallCount()).To(Equal(1))
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(1))
+		})
+
+		It("returns an error without uploading when Ops Manager does not satisfy what it requires", func() {
+			fakeService.InfoReturns(api.Info{Version: "2.6.10-build.4"}, nil)
+
+			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			err := command.Execute([]string{
+				"--product", "/path/to/some-product.tgz",
+				"--check-compatibility",
+			})
+			Expect(err).To(MatchError("some-product 1.2.3 is not compatible with the targeted Ops Manager: 1 of 1 requirements are not satisfied"))
+
+			Expect(multipart.AddFileCallCount()).To(Equal(0))
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(0))
+		})
+
+		It("warns about, but still uploads, a product whose stemcell has not been uploaded yet", func() {
+			metadataExtractor.ExtractMetadataReturns(extractor.Metadata{
+				Name:    "some-product",
+				Version: "1.2.3",
+				Raw: []byte(`---
+name: some-product
+product_version: 1.2.3
+stemcell_criteria:
+  os: ubuntu-xenial
+  version: "456.30"
+`),
+			}, nil)
+
+			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			err := command.Execute([]string{
+				"--product", "/path/to/some-product.tgz",
+				"--check-compatibility",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(1))
+
+			var warnings []string
+			for i := 0; i < logger.PrintfCallCount(); i++ {
+				format, args := logger.PrintfArgsForCall(i)
+				warnings = append(warnings, fmt.Sprintf(format, args...))
+			}
+			Expect(warnings).To(ContainElement("warning: no stemcell ubuntu-xenial satisfying what some-product 1.2.3 requires has been uploaded yet"))
+		})
+
+		It("does not check a product that is already uploaded", func() {
+			fakeService.CheckProductAvailabilityReturns(true, nil)
+
+			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			err := command.Execute([]string{
+				"--product", "/path/to/some-product.tgz",
+				"--check-compatibility",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.InfoCallCount()).To(Equal(0))
+		})
+	})
+
 	When("config file is provided", func() {
 		var configFile *os.File
 
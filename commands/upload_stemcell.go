@@ -8,6 +8,7 @@ import (
 	"github.com/pivotal-cf/om/formcontent"
 	"github.com/pivotal-cf/om/network"
 	"github.com/pivotal-cf/om/validator"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -16,15 +17,17 @@ import (
 const maxStemcellUploadRetries = 2
 
 type UploadStemcell struct {
-	multipart multipart
-	logger    logger
-	service   uploadStemcellService
-	Options   struct {
-		ConfigFile string `long:"config"   short:"c"                 description:"path to yml file for configuration (keys must match the following command line flags)"`
-		Stemcell   string `long:"stemcell" short:"s" required:"true" description:"path to stemcell"`
-		Force      bool   `long:"force"    short:"f"                 description:"upload stemcell even if it already exists on the target Ops Manager"`
-		Floating   string `long:"floating" default:"true"            description:"assigns the stemcell to all compatible products "`
-		Shasum     string `long:"shasum"                             description:"shasum of the provided product file to be used for validation"`
+	multipart    multipart
+	newMultipart func() multipart
+	logger       logger
+	service      uploadStemcellService
+	Options      struct {
+		ConfigFile  string   `long:"config"   short:"c"                 description:"path to yml file for configuration (keys must match the following command line flags)"`
+		Stemcell    []string `long:"stemcell" short:"s" required:"true" description:"path to stemcell, a directory of .tgz files, or a glob. Can be given more than once"`
+		Concurrency int      `long:"concurrency" default:"1"            description:"when uploading more than one stemcell, how many to upload at once"`
+		Force       bool     `long:"force"    short:"f"                 description:"upload stemcell even if it already exists on the target Ops Manager"`
+		Floating    string   `long:"floating" default:"true"            description:"assigns the stemcell to all compatible products "`
+		Shasum      string   `long:"shasum"                             description:"shasum of the provided product file to be used for validation"`
 	}
 }
 
@@ -33,6 +36,7 @@ type multipart interface {
 	Finalize() formcontent.ContentSubmission
 	Reset()
 	AddFile(key, path string) error
+	AddReader(key, fileName string, r io.Reader, length int64) error
 	AddField(key, value string) error
 }
 
@@ -45,9 +49,10 @@ type uploadStemcellService interface {
 
 func NewUploadStemcell(multipart multipart, service uploadStemcellService, logger logger) UploadStemcell {
 	return UploadStemcell{
-		multipart: multipart,
-		logger:    logger,
-		service:   service,
+		multipart:    multipart,
+		newMultipart: newMultipartForm,
+		logger:       logger,
+		service:      service,
 	}
 }
 
@@ -65,20 +70,58 @@ func (us UploadStemcell) Execute(args []string) error {
 		return fmt.Errorf("could not parse upload-stemcell flags: %s", err)
 	}
 
-	err = us.validate()
+	// --stemcell can be given more than once, so those on the command line
+	// replace those in the config file rather than being added to them
+	if us.Options.ConfigFile != "" {
+		var commandLine UploadStemcell
+		err = parseCommandLineFlags(&commandLine.Options, args)
+		if err != nil {
+			return fmt.Errorf("could not parse upload-stemcell flags: %s", err)
+		}
+		if len(commandLine.Options.Stemcell) > 0 {
+			us.Options.Stemcell = commandLine.Options.Stemcell
+		}
+	}
+
+	if us.Options.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	stemcells, err := findArtifacts(us.Options.Stemcell, ".tgz")
 	if err != nil {
 		return err
 	}
 
-	stemcellFilename := us.Options.Stemcell
+	if len(stemcells) == 1 {
+		_, err = us.upload(stemcells[0], us.multipart, false)
+		return err
+	}
+
+	if us.Options.Shasum != "" {
+		return fmt.Errorf("--shasum cannot be used when uploading more than one stemcell")
+	}
+
+	return uploadArtifacts(stemcells, us.Options.Concurrency, "stemcell", us.logger, func(stemcell string) (bool, error) {
+		return us.upload(stemcell, us.newMultipart(), us.Options.Concurrency > 1)
+	})
+}
+
+// upload uploads a stemcell with its own form, so that several can be
+// uploaded at once. It returns true when the stemcell was already uploaded.
+func (us UploadStemcell) upload(stemcellFilename string, form multipart, hideProgress bool) (bool, error) {
+	err := us.validate(stemcellFilename)
+	if err != nil {
+		return false, err
+	}
+
 	if !us.Options.Force {
-		exists, err := us.checkStemcellUploaded()
+		exists, err := us.checkStemcellUploaded(stemcellFilename)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		if exists {
-			return nil
+			return true, nil
 		}
 	}
 
@@ -89,36 +132,36 @@ func (us UploadStemcell) Execute(args []string) error {
 		symlinkedStemcell := filepath.Join(filepath.Dir(stemcellFilename), matches[1])
 		err = os.Symlink(stemcellFilename, symlinkedStemcell)
 		if err != nil {
-			return err
+			return false, err
 		}
 		stemcellFilename = symlinkedStemcell
 
 		defer os.Remove(symlinkedStemcell)
 	}
 
-	err = us.uploadStemcell(stemcellFilename)
+	err = us.uploadStemcell(stemcellFilename, form, hideProgress)
 	if err != nil {
-		return fmt.Errorf("failed to upload stemcell: %s", err)
+		return false, fmt.Errorf("failed to upload stemcell: %s", err)
 	}
 
 	us.logger.Printf("finished upload")
 
-	return nil
+	return false, nil
 }
 
-func (us UploadStemcell) uploadStemcell(stemcellFilename string) (err error) {
+func (us UploadStemcell) uploadStemcell(stemcellFilename string, form multipart, hideProgress bool) (err error) {
 	for i := 0; i <= maxStemcellUploadRetries; i++ {
-		err = us.multipart.AddFile("stemcell[file]", stemcellFilename)
+		err = form.AddFile("stemcell[file]", stemcellFilename)
 		if err != nil {
 			return err
 		}
 
-		err = us.multipart.AddField("stemcell[floating]", us.Options.Floating)
+		err = form.AddField("stemcell[floating]", us.Options.Floating)
 		if err != nil {
 			return err
 		}
 
-		submission := us.multipart.Finalize()
+		submission := form.Finalize()
 		if err != nil {
 			return fmt.Errorf("failed to create multipart form: %s", err)
 		}
@@ -129,10 +172,11 @@ func (us UploadStemcell) uploadStemcell(stemcellFilename string) (err error) {
 			Stemcell:      submission.Content,
 			ContentType:   submission.ContentType,
 			ContentLength: submission.ContentLength,
+			HideProgress:  hideProgress,
 		})
 		if network.CanRetry(err) && i < maxStemcellUploadRetries {
 			us.logger.Printf("retrying stemcell upload after error: %s\n", err)
-			us.multipart.Reset()
+			form.Reset()
 		} else {
 			break
 		}
@@ -141,14 +185,14 @@ func (us UploadStemcell) uploadStemcell(stemcellFilename string) (err error) {
 	return err
 }
 
-func (us UploadStemcell) validate() error {
+func (us UploadStemcell) validate(stemcellFilename string) error {
 	if us.Options.Floating != "true" && us.Options.Floating != "false" {
 		return errors.New("--floating must be \"true\" or \"false\". Default: true")
 	}
 
 	if us.Options.Shasum != "" {
 		shaValidator := validator.NewSHA256Calculator()
-		shasum, err := shaValidator.Checksum(us.Options.Stemcell)
+		shasum, err := shaValidator.Checksum(stemcellFilename)
 
 		if err != nil {
 			return err
@@ -164,10 +208,9 @@ func (us UploadStemcell) validate() error {
 	return nil
 }
 
-func (us UploadStemcell) checkStemcellUploaded() (exists bool, err error) {
+func (us UploadStemcell) checkStemcellUploaded(stemcellFilename string) (exists bool, err error) {
 	us.logger.Printf("processing stemcell")
 
-	stemcellFilename := us.Options.Stemcell
 	exists = true
 
 	report, err := us.service.GetDiagnosticReport()
@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -412,6 +413,69 @@ var _ = Describe("UploadStemcell", func() {
 		})
 	})
 
+	When("more than one stemcell is given", func() {
+		var stemcellsDir string
+
+		BeforeEach(func() {
+			var err error
+			stemcellsDir, err = ioutil.TempDir("", "stemcells")
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, name := range []string{"light-bosh-stemcell-1.tgz", "light-bosh-stemcell-2.tgz", "light-bosh-stemcell-3.tgz", "notes.txt"} {
+				Expect(ioutil.WriteFile(filepath.Join(stemcellsDir, name), []byte("some-stemcell"), 0600)).To(Succeed())
+			}
+
+			fakeService.InfoReturns(api.Info{Version: "2.2-build.1"}, nil)
+			fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{Stemcells: []string{"light-bosh-stemcell-2.tgz"}}, nil)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(stemcellsDir)).To(Succeed())
+		})
+
+		It("uploads each stemcell that is not already uploaded, and prints a summary", func() {
+			fakeService.UploadStemcellStub = func(input api.StemcellUploadInput) (api.StemcellUploadOutput, error) {
+				defer GinkgoRecover()
+
+				contents, err := ioutil.ReadAll(input.Stemcell)
+				Expect(err).ToNot(HaveOccurred())
+
+				if strings.Contains(string(contents), `filename="light-bosh-stemcell-3.tgz"`) {
+					return api.StemcellUploadOutput{}, errors.New("some upload error")
+				}
+				Expect(string(contents)).To(ContainSubstring(`name="stemcell[floating]"` + "\r\n\r\nfalse"))
+				return api.StemcellUploadOutput{}, nil
+			}
+
+			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+
+			err := command.Execute([]string{
+				"--stemcell", filepath.Join(stemcellsDir, "*.tgz"),
+				"--floating", "false",
+				"--concurrency", "2",
+			})
+			Expect(err).To(MatchError("failed to upload 1 of 3 stemcells"))
+
+			Expect(multipart.AddFileCallCount()).To(Equal(0))
+			Expect(fakeService.UploadStemcellCallCount()).To(Equal(2))
+			Expect(fakeService.UploadStemcellArgsForCall(0).HideProgress).To(BeTrue())
+
+			summary := fmt.Sprint(logger.PrintArgsForCall(0))
+			Expect(summary).To(MatchRegexp(`\|\s+\S+/light-bosh-stemcell-1.tgz\s+\|\s+uploaded\s+\|\s+\|`))
+			Expect(summary).To(MatchRegexp(`\|\s+\S+/light-bosh-stemcell-2.tgz\s+\|\s+skipped\s+\|\s+\|`))
+			Expect(summary).To(MatchRegexp(`\|\s+\S+/light-bosh-stemcell-3.tgz\s+\|\s+failed\s+\|\s+failed to upload stemcell: some upload error\s+\|`))
+		})
+
+		When("--shasum is given", func() {
+			It("returns an error", func() {
+				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+
+				err := command.Execute([]string{"--stemcell", stemcellsDir, "--shasum", "some-shasum"})
+				Expect(err).To(MatchError("--shasum cannot be used when uploading more than one stemcell"))
+			})
+		})
+	})
+
 	When("config file is provided", func() {
 		var (
 			configFile *os.File
@@ -466,6 +530,22 @@ shasum: 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44
 			format, v := logger.PrintfArgsForCall(0)
 			Expect(fmt.Sprintf(format, v...)).To(ContainSubstring("expected shasum matches stemcell shasum."))
 		})
+
+		It("reads the stemcell from the config file when it is not on the command line", func() {
+			_, err := configFile.WriteString("stemcell: " + file.Name() + "\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeService.InfoReturns(api.Info{Version: "2.2-build.1"}, nil)
+			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+			err = command.Execute([]string{
+				"--config", configFile.Name(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			key, path := multipart.AddFileArgsForCall(0)
+			Expect(key).To(Equal("stemcell[file]"))
+			Expect(path).To(Equal(file.Name()))
+		})
 	})
 
 	Context("failure cases", func() {
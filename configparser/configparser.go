@@ -175,3 +175,28 @@ func NewGetCredentialHandler(productGUID string, apiService getCredential) Crede
 		return output, nil
 	}
 }
+
+// NewPlaceholderVarsHandler replaces credentials with placeholders, the same
+// as NewPlaceholderHandler, and records each deployed credential in vars
+// under its placeholder name.
+func NewPlaceholderVarsHandler(productGUID string, apiService getCredential, vars map[string]interface{}) CredentialHandler {
+	placeholderHandler := NewPlaceholderHandler()
+
+	return func(name PropertyName, property api.ResponseProperty) (map[string]interface{}, error) {
+		output, err := placeholderHandler(name, property)
+		if err != nil || output == nil {
+			return output, err
+		}
+
+		apiOutput, err := apiService.GetDeployedProductCredential(api.GetDeployedProductCredentialInput{
+			DeployedGUID:        productGUID,
+			CredentialReference: name.credentialName(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		vars[name.placeholderName()] = apiOutput.Credential.Value
+		return output, nil
+	}
+}
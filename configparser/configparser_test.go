@@ -345,4 +345,44 @@ var _ = Describe("Config Parser", func() {
 			})
 		})
 	})
+
+	Context("given placeholder vars handler", func() {
+		var fakeCredService *fakes.CredentialsService
+
+		BeforeEach(func() {
+			fakeCredService = &fakes.CredentialsService{}
+		})
+
+		It("replaces credentials with placeholders and records their values", func() {
+			fakeCredService.GetDeployedProductCredentialStub = func(input api.GetDeployedProductCredentialInput) (api.GetDeployedProductCredentialOutput, error) {
+				return api.GetDeployedProductCredentialOutput{
+					Credential: api.Credential{
+						Value: map[string]string{"value-of": input.CredentialReference},
+					},
+				}, nil
+			}
+
+			vars := map[string]interface{}{}
+			output, err := getOutput(configparser.NewPlaceholderVarsHandler(productGUID, fakeCredService, vars))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(output).To(ContainSubstring(`secret: ((properties_some-secret-property.secret))`))
+			Expect(vars).To(Equal(map[string]interface{}{
+				"properties_some-secret-property":      map[string]string{"value-of": ".properties.some-secret-property"},
+				"properties_simple-credentials":        map[string]string{"value-of": ".properties.simple-credentials"},
+				"properties_rsa-cert-credentials":      map[string]string{"value-of": ".properties.rsa-cert-credentials"},
+				"properties_rsa-pkey-credentials":      map[string]string{"value-of": ".properties.rsa-pkey-credentials"},
+				"properties_salted-credentials":        map[string]string{"value-of": ".properties.salted-credentials"},
+				"properties_collection_0_certificate":  map[string]string{"value-of": ".properties.collection[0].certificate"},
+				"properties_collection_1_certificate2": map[string]string{"value-of": ".properties.collection[1].certificate2"},
+			}))
+		})
+
+		It("returns an error when looking up a credential fails", func() {
+			fakeCredService.GetDeployedProductCredentialReturns(api.GetDeployedProductCredentialOutput{}, errors.New("some-error"))
+
+			_, err := getOutput(configparser.NewPlaceholderVarsHandler(productGUID, fakeCredService, map[string]interface{}{}))
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
 })
@@ -15,11 +15,12 @@ import (
 
 	pivnetapi "github.com/pivotal-cf/go-pivnet"
 	"github.com/pivotal-cf/go-pivnet/logshim"
+	"github.com/pivotal-cf/om/cache"
 	"github.com/pkg/errors"
 	"howett.net/ranger"
 )
 
-func NewPivnetProvider(host, token, slug, version, glob string, skipSSL bool) Provider {
+func NewPivnetProvider(host, token, slug, version, glob string, skipSSL bool, cacheDirectory string) Provider {
 
 	logWriter := os.Stderr
 	logger := log.New(logWriter, "", log.LstdFlags)
@@ -44,6 +45,7 @@ func NewPivnetProvider(host, token, slug, version, glob string, skipSSL bool) Pr
 		slug:             slug,
 		version:          version,
 		glob:             glob,
+		cacheDirectory:   cacheDirectory,
 	}
 }
 
@@ -55,6 +57,7 @@ type PivnetProvider struct {
 	slug             string
 	version          string
 	glob             string
+	cacheDirectory   string
 }
 
 func (p *PivnetProvider) MetadataBytes() ([]byte, error) {
@@ -108,12 +111,23 @@ func (p *PivnetProvider) downloadFiles(
 		return nil, fmt.Errorf("the glob '%s' matches multiple files. Write your glob to match exactly one of the following:\n  %s", p.glob, strings.Join(list, "\n  "))
 	}
 
+	pf := filtered[0]
+	if p.cacheDirectory != "" && pf.SHA256 != "" {
+		entry, ok, err := cache.New(p.cacheDirectory).Get(pf.SHA256)
+		if err != nil {
+			return nil, errors.Wrap(err, "error with cache")
+		}
+
+		if ok {
+			return NewFileProvider(entry.Path).MetadataBytes()
+		}
+	}
+
 	err = p.client.EULA.Accept(p.slug, releaseID)
 	if err != nil {
 		return nil, err
 	}
 
-	pf := filtered[0]
 	downloadLink, err := pf.DownloadLink()
 	if err != nil {
 		return nil, err
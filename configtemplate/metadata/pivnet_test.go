@@ -1,13 +1,19 @@
 package metadata_test
 
 import (
+	"archive/zip"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/om/cache"
 	"github.com/pivotal-cf/om/configtemplate/metadata"
+	"github.com/pivotal-cf/om/validator"
 )
 
 var _ = Describe("Pivnet Client", func() {
@@ -43,7 +49,7 @@ var _ = Describe("Pivnet Client", func() {
 				),
 			)
 
-			provider := metadata.NewPivnetProvider(server.URL(), "some-token", "example-product", "1.1.1", "*.pivotal", false)
+			provider := metadata.NewPivnetProvider(server.URL(), "some-token", "example-product", "1.1.1", "*.pivotal", false, "")
 			_, err := provider.MetadataBytes()
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError("the glob '*.pivotal' matches multiple files. Write your glob to match exactly one of the following:\n  something.pivotal\n  something-else.pivotal"))
@@ -86,11 +92,66 @@ var _ = Describe("Pivnet Client", func() {
 				),
 			)
 
-			provider := metadata.NewPivnetProvider(server.URL(), "some-token", "example-product", "1.1.1", "*.pivotal", false)
+			provider := metadata.NewPivnetProvider(server.URL(), "some-token", "example-product", "1.1.1", "*.pivotal", false, "")
 
 			_, err := provider.MetadataBytes()
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError("no version matched for slug example-product, version 1.1.1 and glob *.pivotal.\nVersions found:\n  2.2.2\n  3.3.3"))
 		})
 	})
+	When("the product file is in the cache", func() {
+		It("reads the metadata from the cached file without downloading it", func() {
+			cacheDirectory, err := ioutil.TempDir("", "om-cache-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(cacheDirectory)
+
+			tile := filepath.Join(cacheDirectory, "example-product-1.1.1.pivotal")
+			file, err := os.Create(tile)
+			Expect(err).ToNot(HaveOccurred())
+			zipper := zip.NewWriter(file)
+			writer, err := zipper.Create("metadata/example-product.yml")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = writer.Write([]byte("name: example-product"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(zipper.Close()).To(Succeed())
+			Expect(file.Close()).To(Succeed())
+
+			sum, err := validator.NewSHA256Calculator().Checksum(tile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cache.New(cacheDirectory).Add(sum, tile)).To(Succeed())
+
+			server := ghttp.NewServer()
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v2/products/example-product/releases"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ReleasesResponse{
+						Releases: []pivnet.Release{
+							{
+								ID:      1,
+								Version: "1.1.1",
+							},
+						},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v2/products/example-product/releases/1/product_files"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{
+						ProductFiles: []pivnet.ProductFile{
+							{
+								ID:           1234,
+								AWSObjectKey: "example-product-1.1.1.pivotal",
+								SHA256:       sum,
+							},
+						},
+					}),
+				),
+			)
+
+			provider := metadata.NewPivnetProvider(server.URL(), "some-token", "example-product", "1.1.1", "*.pivotal", false, cacheDirectory)
+			contents, err := provider.MetadataBytes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("name: example-product"))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
 })
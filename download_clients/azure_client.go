@@ -6,15 +6,17 @@ import (
 	"github.com/pivotal-cf/om/commands"
 	"gopkg.in/go-playground/validator.v9"
 	"io"
+	"io/ioutil"
 	"log"
 )
 
 type AzureConfiguration struct {
-	StorageAccount string `validate:"required"`
-	Key            string `validate:"required"`
-	Container      string `validate:"required"`
-	ProductPath    string
-	StemcellPath   string
+	StorageAccount      string `validate:"required"`
+	Key                 string `validate:"required"`
+	Container           string `validate:"required"`
+	ProductPath         string
+	StemcellPath        string
+	ParallelConnections int
 }
 
 func NewAzureClient(stower Stower, config AzureConfiguration, progressWriter io.Writer) (stowClient, error) {
@@ -29,7 +31,7 @@ func NewAzureClient(stower Stower, config AzureConfiguration, progressWriter io.
 		azure.ConfigKey:     config.Key,
 	}
 
-	return NewStowClient(
+	client := NewStowClient(
 		stower,
 		config.Container,
 		stowConfig,
@@ -37,7 +39,10 @@ func NewAzureClient(stower Stower, config AzureConfiguration, progressWriter io.
 		config.ProductPath,
 		config.StemcellPath,
 		"azure",
-	), nil
+	)
+	client.parallelConnections = config.ParallelConnections
+
+	return client, nil
 }
 
 func init() {
@@ -48,15 +53,26 @@ func init() {
 		_ *log.Logger,
 	) (commands.ProductDownloader, error) {
 		config := AzureConfiguration{
-			Container:      c.Bucket,
-			StorageAccount: c.AzureStorageAccount,
-			Key:            c.AzureKey,
-			ProductPath:    c.ProductPath,
-			StemcellPath:   c.StemcellPath,
+			Container:           c.Bucket,
+			StorageAccount:      c.AzureStorageAccount,
+			Key:                 c.AzureKey,
+			ProductPath:         c.ProductPath,
+			StemcellPath:        c.StemcellPath,
+			ParallelConnections: c.ParallelConnections,
 		}
 
 		return NewAzureClient(wrapStow{}, config, progressWriter)
 	}
 
 	commands.RegisterProductClient("azure", initializer)
+
+	commands.RegisterBlobstore("azure", func(c commands.BlobstoreOptions) (commands.Blobstore, error) {
+		config := AzureConfiguration{
+			Container:      c.Bucket,
+			StorageAccount: c.AzureStorageAccount,
+			Key:            c.AzureKey,
+		}
+
+		return NewAzureClient(wrapStow{}, config, ioutil.Discard)
+	})
 }
@@ -11,19 +11,21 @@ import (
 )
 
 type PivnetDownloader struct {
-	DownloadProductFileStub        func(*download.FileInfo, string, int, int, io.Writer) error
-	downloadProductFileMutex       sync.RWMutex
-	downloadProductFileArgsForCall []struct {
+	DownloadProductFileFromStub        func(*download.FileInfo, string, int, int, int64, int, io.Writer) error
+	downloadProductFileFromMutex       sync.RWMutex
+	downloadProductFileFromArgsForCall []struct {
 		arg1 *download.FileInfo
 		arg2 string
 		arg3 int
 		arg4 int
-		arg5 io.Writer
+		arg5 int64
+		arg6 int
+		arg7 io.Writer
 	}
-	downloadProductFileReturns struct {
+	downloadProductFileFromReturns struct {
 		result1 error
 	}
-	downloadProductFileReturnsOnCall map[int]struct {
+	downloadProductFileFromReturnsOnCall map[int]struct {
 		result1 error
 	}
 	ProductFilesForReleaseStub        func(string, int) ([]pivnet.ProductFile, error)
@@ -85,66 +87,69 @@ type PivnetDownloader struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *PivnetDownloader) DownloadProductFile(arg1 *download.FileInfo, arg2 string, arg3 int, arg4 int, arg5 io.Writer) error {
-	fake.downloadProductFileMutex.Lock()
-	ret, specificReturn := fake.downloadProductFileReturnsOnCall[len(fake.downloadProductFileArgsForCall)]
-	fake.downloadProductFileArgsForCall = append(fake.downloadProductFileArgsForCall, struct {
+func (fake *PivnetDownloader) DownloadProductFileFrom(arg1 *download.FileInfo, arg2 string, arg3 int, arg4 int, arg5 int64, arg6 int, arg7 io.Writer) error {
+	fake.downloadProductFileFromMutex.Lock()
+	ret, specificReturn := fake.downloadProductFileFromReturnsOnCall[len(fake.downloadProductFileFromArgsForCall)]
+	fake.downloadProductFileFromArgsForCall = append(fake.downloadProductFileFromArgsForCall, struct {
 		arg1 *download.FileInfo
 		arg2 string
 		arg3 int
 		arg4 int
-		arg5 io.Writer
-	}{arg1, arg2, arg3, arg4, arg5})
-	fake.recordInvocation("DownloadProductFile", []interface{}{arg1, arg2, arg3, arg4, arg5})
-	fake.downloadProductFileMutex.Unlock()
-	if fake.DownloadProductFileStub != nil {
-		return fake.DownloadProductFileStub(arg1, arg2, arg3, arg4, arg5)
+		arg5 int64
+		arg6 int
+		arg7 io.Writer
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	stub := fake.DownloadProductFileFromStub
+	fakeReturns := fake.downloadProductFileFromReturns
+	fake.recordInvocation("DownloadProductFileFrom", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	fake.downloadProductFileFromMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.downloadProductFileReturns
 	return fakeReturns.result1
 }
 
-func (fake *PivnetDownloader) DownloadProductFileCallCount() int {
-	fake.downloadProductFileMutex.RLock()
-	defer fake.downloadProductFileMutex.RUnlock()
-	return len(fake.downloadProductFileArgsForCall)
+func (fake *PivnetDownloader) DownloadProductFileFromCallCount() int {
+	fake.downloadProductFileFromMutex.RLock()
+	defer fake.downloadProductFileFromMutex.RUnlock()
+	return len(fake.downloadProductFileFromArgsForCall)
 }
 
-func (fake *PivnetDownloader) DownloadProductFileCalls(stub func(*download.FileInfo, string, int, int, io.Writer) error) {
-	fake.downloadProductFileMutex.Lock()
-	defer fake.downloadProductFileMutex.Unlock()
-	fake.DownloadProductFileStub = stub
+func (fake *PivnetDownloader) DownloadProductFileFromCalls(stub func(*download.FileInfo, string, int, int, int64, int, io.Writer) error) {
+	fake.downloadProductFileFromMutex.Lock()
+	defer fake.downloadProductFileFromMutex.Unlock()
+	fake.DownloadProductFileFromStub = stub
 }
 
-func (fake *PivnetDownloader) DownloadProductFileArgsForCall(i int) (*download.FileInfo, string, int, int, io.Writer) {
-	fake.downloadProductFileMutex.RLock()
-	defer fake.downloadProductFileMutex.RUnlock()
-	argsForCall := fake.downloadProductFileArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+func (fake *PivnetDownloader) DownloadProductFileFromArgsForCall(i int) (*download.FileInfo, string, int, int, int64, int, io.Writer) {
+	fake.downloadProductFileFromMutex.RLock()
+	defer fake.downloadProductFileFromMutex.RUnlock()
+	argsForCall := fake.downloadProductFileFromArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
 }
 
-func (fake *PivnetDownloader) DownloadProductFileReturns(result1 error) {
-	fake.downloadProductFileMutex.Lock()
-	defer fake.downloadProductFileMutex.Unlock()
-	fake.DownloadProductFileStub = nil
-	fake.downloadProductFileReturns = struct {
+func (fake *PivnetDownloader) DownloadProductFileFromReturns(result1 error) {
+	fake.downloadProductFileFromMutex.Lock()
+	defer fake.downloadProductFileFromMutex.Unlock()
+	fake.DownloadProductFileFromStub = nil
+	fake.downloadProductFileFromReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *PivnetDownloader) DownloadProductFileReturnsOnCall(i int, result1 error) {
-	fake.downloadProductFileMutex.Lock()
-	defer fake.downloadProductFileMutex.Unlock()
-	fake.DownloadProductFileStub = nil
-	if fake.downloadProductFileReturnsOnCall == nil {
-		fake.downloadProductFileReturnsOnCall = make(map[int]struct {
+func (fake *PivnetDownloader) DownloadProductFileFromReturnsOnCall(i int, result1 error) {
+	fake.downloadProductFileFromMutex.Lock()
+	defer fake.downloadProductFileFromMutex.Unlock()
+	fake.DownloadProductFileFromStub = nil
+	if fake.downloadProductFileFromReturnsOnCall == nil {
+		fake.downloadProductFileFromReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.downloadProductFileReturnsOnCall[i] = struct {
+	fake.downloadProductFileFromReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
@@ -156,15 +161,16 @@ func (fake *PivnetDownloader) ProductFilesForRelease(arg1 string, arg2 int) ([]p
 		arg1 string
 		arg2 int
 	}{arg1, arg2})
+	stub := fake.ProductFilesForReleaseStub
+	fakeReturns := fake.productFilesForReleaseReturns
 	fake.recordInvocation("ProductFilesForRelease", []interface{}{arg1, arg2})
 	fake.productFilesForReleaseMutex.Unlock()
-	if fake.ProductFilesForReleaseStub != nil {
-		return fake.ProductFilesForReleaseStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.productFilesForReleaseReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -220,15 +226,16 @@ func (fake *PivnetDownloader) ReleaseDependencies(arg1 string, arg2 int) ([]pivn
 		arg1 string
 		arg2 int
 	}{arg1, arg2})
+	stub := fake.ReleaseDependenciesStub
+	fakeReturns := fake.releaseDependenciesReturns
 	fake.recordInvocation("ReleaseDependencies", []interface{}{arg1, arg2})
 	fake.releaseDependenciesMutex.Unlock()
-	if fake.ReleaseDependenciesStub != nil {
-		return fake.ReleaseDependenciesStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.releaseDependenciesReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -284,15 +291,16 @@ func (fake *PivnetDownloader) ReleaseForVersion(arg1 string, arg2 string) (pivne
 		arg1 string
 		arg2 string
 	}{arg1, arg2})
+	stub := fake.ReleaseForVersionStub
+	fakeReturns := fake.releaseForVersionReturns
 	fake.recordInvocation("ReleaseForVersion", []interface{}{arg1, arg2})
 	fake.releaseForVersionMutex.Unlock()
-	if fake.ReleaseForVersionStub != nil {
-		return fake.ReleaseForVersionStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.releaseForVersionReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -347,15 +355,16 @@ func (fake *PivnetDownloader) ReleasesForProductSlug(arg1 string) ([]pivnet.Rele
 	fake.releasesForProductSlugArgsForCall = append(fake.releasesForProductSlugArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.ReleasesForProductSlugStub
+	fakeReturns := fake.releasesForProductSlugReturns
 	fake.recordInvocation("ReleasesForProductSlug", []interface{}{arg1})
 	fake.releasesForProductSlugMutex.Unlock()
-	if fake.ReleasesForProductSlugStub != nil {
-		return fake.ReleasesForProductSlugStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.releasesForProductSlugReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -407,8 +416,8 @@ func (fake *PivnetDownloader) ReleasesForProductSlugReturnsOnCall(i int, result1
 func (fake *PivnetDownloader) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.downloadProductFileMutex.RLock()
-	defer fake.downloadProductFileMutex.RUnlock()
+	fake.downloadProductFileFromMutex.RLock()
+	defer fake.downloadProductFileFromMutex.RUnlock()
 	fake.productFilesForReleaseMutex.RLock()
 	defer fake.productFilesForReleaseMutex.RUnlock()
 	fake.releaseDependenciesMutex.RLock()
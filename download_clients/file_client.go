@@ -0,0 +1,95 @@
+package download_clients
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+
+	"github.com/graymeta/stow"
+	"github.com/graymeta/stow/local"
+	"github.com/pivotal-cf/om/commands"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type FileConfiguration struct {
+	Directory    string `validate:"required"`
+	ProductPath  string
+	StemcellPath string
+}
+
+// NewFileClient reads products from a directory, such as an NFS mount, laid
+// out as it would be in a bucket.
+func NewFileClient(config FileConfiguration, progressWriter io.Writer) (stowClient, error) {
+	validate := validator.New()
+	err := validate.Struct(config)
+	if err != nil {
+		return stowClient{}, err
+	}
+
+	// the directory is the container as well as the location, and a relative
+	// container would be taken as relative to the location
+	directory, err := filepath.Abs(config.Directory)
+	if err != nil {
+		return stowClient{}, err
+	}
+
+	stowConfig := stow.ConfigMap{
+		local.ConfigKeyPath: directory,
+	}
+
+	return NewStowClient(
+		fileStower{},
+		directory,
+		stowConfig,
+		progressWriter,
+		config.ProductPath,
+		config.StemcellPath,
+		"file",
+	), nil
+}
+
+// fileStower reads the directory with stow's local kind, whose items are
+// identified by their absolute path. It identifies them by their path in
+// the directory instead, as the product and stemcell paths are matched
+// against it as they would be in a bucket.
+type fileStower struct{}
+
+func (fileStower) Dial(_ string, config StowConfiger) (stow.Location, error) {
+	return stow.Dial(local.Kind, config)
+}
+
+func (fileStower) Walk(container stow.Container, prefix string, pageSize int, fn stow.WalkFunc) error {
+	return stow.Walk(container, prefix, pageSize, func(item stow.Item, err error) error {
+		if err != nil {
+			return fn(item, err)
+		}
+		return fn(fileItem{item}, nil)
+	})
+}
+
+type fileItem struct {
+	stow.Item
+}
+
+func (i fileItem) ID() string {
+	return i.Name()
+}
+
+func init() {
+	initializer := func(
+		c commands.DownloadProductOptions,
+		progressWriter io.Writer,
+		_ *log.Logger,
+		_ *log.Logger,
+	) (commands.ProductDownloader, error) {
+		config := FileConfiguration{
+			Directory:    c.FileDirectory,
+			ProductPath:  c.ProductPath,
+			StemcellPath: c.StemcellPath,
+		}
+
+		return NewFileClient(config, progressWriter)
+	}
+
+	commands.RegisterProductClient("file", initializer)
+}
@@ -0,0 +1,164 @@
+package download_clients_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/download_clients"
+)
+
+var _ = Describe("FileClient", func() {
+	var directory string
+
+	writeFile := func(name, contents string) {
+		path := filepath.Join(directory, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "")
+		Expect(err).ToNot(HaveOccurred())
+
+		writeFile("products/[cf,2.7.4]cf-2.7.4-build.2.pivotal", "cf 2.7.4")
+		writeFile("products/[cf,2.7.5]cf-2.7.5-build.1.pivotal", "cf 2.7.5")
+		writeFile("products/[cf,2.7.5]srt-2.7.5-build.1.pivotal", "srt 2.7.5")
+		writeFile("stemcells/[stemcells-ubuntu-xenial,456.30]light-bosh-stemcell-456.30-google.tgz", "stemcell")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(directory)).To(Succeed())
+	})
+
+	newConfig := func() download_clients.FileConfiguration {
+		return download_clients.FileConfiguration{
+			Directory:    directory,
+			ProductPath:  "products",
+			StemcellPath: "stemcells",
+		}
+	}
+
+	It("finds the versions of a product in the product path", func() {
+		client, err := download_clients.NewFileClient(newConfig(), GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		versions, err := client.GetAllProductVersions("cf")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(versions).To(ConsistOf("2.7.4", "2.7.5"))
+	})
+
+	It("downloads the file matching the slug, version and glob", func() {
+		client, err := download_clients.NewFileClient(newConfig(), GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Name()).To(Equal("file"))
+
+		file, err := client.GetLatestProductFile("cf", "2.7.5", "cf-*.pivotal")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Name()).To(Equal("products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"))
+
+		destination, err := ioutil.TempFile("", "")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(destination.Name())
+
+		err = client.DownloadProductToFile(file, destination)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(destination.Close()).To(Succeed())
+
+		contents, err := ioutil.ReadFile(destination.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal("cf 2.7.5"))
+	})
+
+	It("starts again from a file that it did not start downloading", func() {
+		client, err := download_clients.NewFileClient(newConfig(), GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		file, err := client.GetLatestProductFile("cf", "2.7.5", "cf-*.pivotal")
+		Expect(err).ToNot(HaveOccurred())
+
+		destination, err := ioutil.TempFile("", "")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(destination.Name())
+
+		_, err = destination.WriteString("cf 2.6.1")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = client.DownloadProductToFile(file, destination)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(destination.Close()).To(Succeed())
+
+		contents, err := ioutil.ReadFile(destination.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal("cf 2.7.5"))
+	})
+
+	It("verifies the file with the checksum file next to it", func() {
+		sum := "0d5f4c4ef4bf5b8bba0e5d9d1c5f0c1b4b0c3e1bd2a7c8d1f2e3a4b5c6d7e8f9"
+		writeFile("products/[cf,2.7.5]cf-2.7.5-build.1.pivotal.sha256", sum+"  [cf,2.7.5]cf-2.7.5-build.1.pivotal\n")
+
+		client, err := download_clients.NewFileClient(newConfig(), GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		file, err := client.GetLatestProductFile("cf", "2.7.5", "cf-*")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Name()).To(Equal("products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"))
+		Expect(file.SHA256()).To(Equal(sum))
+	})
+
+	It("errors when the checksum file next to the file cannot be read", func() {
+		writeFile("products/[cf,2.7.5]cf-2.7.5-build.1.pivotal.sha256", "not a checksum\n")
+
+		client, err := download_clients.NewFileClient(newConfig(), GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetLatestProductFile("cf", "2.7.5", "cf-*")
+		Expect(err).To(MatchError(ContainSubstring("could not read the checksum of products/[cf,2.7.5]cf-2.7.5-build.1.pivotal")))
+	})
+
+	It("finds stemcells in the stemcell path", func() {
+		client, err := download_clients.NewFileClient(newConfig(), GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		file, err := client.GetLatestProductFile("stemcells-ubuntu-xenial", "456.30", "*google*")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Name()).To(Equal("stemcells/[stemcells-ubuntu-xenial,456.30]light-bosh-stemcell-456.30-google.tgz"))
+	})
+
+	It("accepts a relative directory", func() {
+		workingDirectory, err := os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Chdir(workingDirectory)
+
+		Expect(os.Chdir(filepath.Dir(directory))).To(Succeed())
+
+		config := newConfig()
+		config.Directory = filepath.Base(directory)
+		client, err := download_clients.NewFileClient(config, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		versions, err := client.GetAllProductVersions("cf")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(versions).To(ConsistOf("2.7.4", "2.7.5"))
+	})
+
+	When("the directory does not exist", func() {
+		It("returns an error", func() {
+			config := newConfig()
+			config.Directory = filepath.Join(directory, "missing")
+			client, err := download_clients.NewFileClient(config, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetAllProductVersions("cf")
+			Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+		})
+	})
+
+	It("requires a directory", func() {
+		_, err := download_clients.NewFileClient(download_clients.FileConfiguration{}, GinkgoWriter)
+		Expect(err).To(MatchError(ContainSubstring("Field validation for 'Directory' failed on the 'required' tag")))
+	})
+})
@@ -1,21 +1,26 @@
 package download_clients
 
 import (
+	"fmt"
 	"github.com/graymeta/stow"
 	"github.com/graymeta/stow/google"
 	"github.com/pivotal-cf/om/commands"
+	storagev1 "google.golang.org/api/storage/v1"
 	storage "google.golang.org/api/storage/v1beta2"
 	"gopkg.in/go-playground/validator.v9"
 	"io"
+	"io/ioutil"
 	"log"
 )
 
 type GCSConfiguration struct {
-	Bucket             string `validate:"required"`
-	ServiceAccountJSON string `validate:"required"`
-	ProjectID          string `validate:"required"`
-	ProductPath        string
-	StemcellPath       string
+	Bucket              string `validate:"required"`
+	ServiceAccountJSON  string `validate:"required"`
+	ProjectID           string `validate:"required"`
+	ProductPath         string
+	StemcellPath        string
+	ParallelConnections int
+	ReadWrite           bool
 }
 
 func NewGCSClient(stower Stower, config GCSConfiguration, progressWriter io.Writer) (stowClient, error) {
@@ -25,13 +30,18 @@ func NewGCSClient(stower Stower, config GCSConfiguration, progressWriter io.Writ
 		return stowClient{}, err
 	}
 
+	scope := storage.DevstorageReadOnlyScope
+	if config.ReadWrite {
+		scope = storage.DevstorageReadWriteScope
+	}
+
 	stowConfig := stow.ConfigMap{
 		google.ConfigJSON:      config.ServiceAccountJSON,
 		google.ConfigProjectId: config.ProjectID,
-		google.ConfigScopes:    storage.DevstorageReadOnlyScope,
+		google.ConfigScopes:    scope,
 	}
 
-	return NewStowClient(
+	client := NewStowClient(
 		stower,
 		config.Bucket,
 		stowConfig,
@@ -39,7 +49,75 @@ func NewGCSClient(stower Stower, config GCSConfiguration, progressWriter io.Writ
 		config.ProductPath,
 		config.StemcellPath,
 		"google",
-	), nil
+	)
+	client.parallelConnections = config.ParallelConnections
+
+	return client, nil
+}
+
+// gcsStower adds reading a range of bytes to the items of stow's google
+// location, which only read the whole object, so that downloads from gcs can
+// be resumed and made with several connections.
+type gcsStower struct {
+	Stower
+}
+
+func (g gcsStower) Dial(kind string, config StowConfiger) (stow.Location, error) {
+	location, err := g.Stower.Dial(kind, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if googleLocation, ok := location.(*google.Location); ok {
+		return gcsLocation{googleLocation}, nil
+	}
+
+	return location, nil
+}
+
+type gcsLocation struct {
+	*google.Location
+}
+
+func (l gcsLocation) Container(id string) (stow.Container, error) {
+	container, err := l.Location.Container(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcsContainer{Container: container, service: l.Service()}, nil
+}
+
+type gcsContainer struct {
+	stow.Container
+	service *storagev1.Service
+}
+
+func (c gcsContainer) Item(id string) (stow.Item, error) {
+	item, err := c.Container.Item(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcsItem{Item: item, bucket: c.ID(), service: c.service}, nil
+}
+
+type gcsItem struct {
+	stow.Item
+	bucket  string
+	service *storagev1.Service
+}
+
+func (i gcsItem) OpenRange(start, end uint64) (io.ReadCloser, error) {
+	call := i.service.Objects.Get(i.bucket, i.ID())
+	call.Header().Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	response, err := call.Download()
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
 }
 
 func init() {
@@ -50,15 +128,27 @@ func init() {
 		_ *log.Logger,
 	) (commands.ProductDownloader, error) {
 		config := GCSConfiguration{
-			Bucket:             c.Bucket,
-			ProjectID:          c.GCSProjectID,
-			ServiceAccountJSON: c.GCSServiceAccountJSON,
-			ProductPath:        c.ProductPath,
-			StemcellPath:       c.StemcellPath,
+			Bucket:              c.Bucket,
+			ProjectID:           c.GCSProjectID,
+			ServiceAccountJSON:  c.GCSServiceAccountJSON,
+			ProductPath:         c.ProductPath,
+			StemcellPath:        c.StemcellPath,
+			ParallelConnections: c.ParallelConnections,
 		}
 
-		return NewGCSClient(wrapStow{}, config, progressWriter)
+		return NewGCSClient(gcsStower{wrapStow{}}, config, progressWriter)
 	}
 
 	commands.RegisterProductClient("gcs", initializer)
+
+	commands.RegisterBlobstore("gcs", func(c commands.BlobstoreOptions) (commands.Blobstore, error) {
+		config := GCSConfiguration{
+			Bucket:             c.Bucket,
+			ProjectID:          c.GCSProjectID,
+			ServiceAccountJSON: c.GCSServiceAccountJSON,
+			ReadWrite:          true,
+		}
+
+		return NewGCSClient(gcsStower{wrapStow{}}, config, ioutil.Discard)
+	})
 }
@@ -0,0 +1,424 @@
+package download_clients
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/graymeta/stow"
+	"github.com/pivotal-cf/om/commands"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	httpConfigIndexFile    = "index_file"
+	httpConfigProductPath  = "product_path"
+	httpConfigStemcellPath = "stemcell_path"
+)
+
+type HTTPConfiguration struct {
+	URL          string `validate:"required"`
+	IndexFile    string
+	DisableSSL   bool
+	ProductPath  string
+	StemcellPath string
+}
+
+// NewHTTPClient reads products from a plain HTTP artifact server, such as an
+// Artifactory generic repository, laid out as it would be in a bucket.
+func NewHTTPClient(config HTTPConfiguration, progressWriter io.Writer) (stowClient, error) {
+	validate := validator.New()
+	err := validate.Struct(config)
+	if err != nil {
+		return stowClient{}, err
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(config.URL, "/") + "/")
+	if err != nil {
+		return stowClient{}, fmt.Errorf("could not parse http url %q: %s", config.URL, err)
+	}
+
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return stowClient{}, fmt.Errorf("http url %q must start with http:// or https://", config.URL)
+	}
+
+	stowConfig := stow.ConfigMap{
+		httpConfigIndexFile:    config.IndexFile,
+		httpConfigProductPath:  config.ProductPath,
+		httpConfigStemcellPath: config.StemcellPath,
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.DisableSSL,
+			},
+		},
+	}
+
+	return NewStowClient(
+		httpStower{client: client},
+		baseURL.String(),
+		stowConfig,
+		progressWriter,
+		config.ProductPath,
+		config.StemcellPath,
+		"http",
+	), nil
+}
+
+// httpStower makes an artifact server look like a bucket, whose container is
+// the base url and whose items are the files under it.
+type httpStower struct {
+	client *http.Client
+}
+
+func (s httpStower) Dial(_ string, config StowConfiger) (stow.Location, error) {
+	return httpLocation{client: s.client, config: config}, nil
+}
+
+func (s httpStower) Walk(container stow.Container, prefix string, pageSize int, fn stow.WalkFunc) error {
+	return stow.Walk(container, prefix, pageSize, fn)
+}
+
+type httpLocation struct {
+	client *http.Client
+	config StowConfiger
+}
+
+func (l httpLocation) Container(id string) (stow.Container, error) {
+	baseURL, err := url.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	indexFile, _ := l.config.Config(httpConfigIndexFile)
+	productPath, _ := l.config.Config(httpConfigProductPath)
+	stemcellPath, _ := l.config.Config(httpConfigStemcellPath)
+
+	return httpContainer{
+		client:       l.client,
+		baseURL:      baseURL,
+		indexFile:    indexFile,
+		productPath:  productPath,
+		stemcellPath: stemcellPath,
+	}, nil
+}
+
+func (l httpLocation) Close() error { return nil }
+
+func (l httpLocation) CreateContainer(string) (stow.Container, error) {
+	return nil, stow.NotSupported("creating a container")
+}
+
+func (l httpLocation) Containers(string, string, int) ([]stow.Container, string, error) {
+	return nil, "", stow.NotSupported("listing containers")
+}
+
+func (l httpLocation) RemoveContainer(string) error {
+	return stow.NotSupported("removing a container")
+}
+
+func (l httpLocation) ItemByURL(*url.URL) (stow.Item, error) {
+	return nil, stow.NotSupported("finding an item by url")
+}
+
+type httpContainer struct {
+	client       *http.Client
+	baseURL      *url.URL
+	indexFile    string
+	productPath  string
+	stemcellPath string
+}
+
+func (c httpContainer) ID() string {
+	return c.baseURL.String()
+}
+
+func (c httpContainer) Name() string {
+	return c.baseURL.String()
+}
+
+func (c httpContainer) Item(id string) (stow.Item, error) {
+	item := httpItem{client: c.client, name: id, url: c.itemURL(id)}
+
+	response, err := c.client.Head(item.url.String())
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, stow.ErrNotFound
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not find %s: unexpected response %s", item.url, response.Status)
+	}
+
+	item.size = response.ContentLength
+	if item.size < 0 {
+		item.size = 0
+	}
+	item.etag = response.Header.Get("ETag")
+	item.lastModified, _ = http.ParseTime(response.Header.Get("Last-Modified"))
+
+	return item, nil
+}
+
+// Items lists every file at once, so there is never a next page.
+func (c httpContainer) Items(prefix, _ string, _ int) ([]stow.Item, string, error) {
+	var (
+		names []string
+		err   error
+	)
+
+	if c.indexFile != "" {
+		names, err = c.readIndexFile()
+	} else {
+		names, err = c.readDirectoryListings()
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var items []stow.Item
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			items = append(items, httpItem{client: c.client, name: name, url: c.itemURL(name), size: -1})
+		}
+	}
+
+	return items, "", nil
+}
+
+func (c httpContainer) RemoveItem(string) error {
+	return stow.NotSupported("removing an item")
+}
+
+func (c httpContainer) Put(string, io.Reader, int64, map[string]interface{}) (stow.Item, error) {
+	return nil, stow.NotSupported("putting an item")
+}
+
+// readIndexFile reads a file that lists the artifacts, one path relative to
+// the base url on each line.
+func (c httpContainer) readIndexFile() ([]string, error) {
+	body, err := c.get(c.itemURL(c.indexFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read index file: %s", err)
+	}
+	defer body.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, strings.TrimPrefix(line, "/"))
+	}
+
+	return names, scanner.Err()
+}
+
+var hrefRegex = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+
+// readDirectoryListings reads the HTML directory listings of the product and
+// stemcell paths, as served by Artifactory, nginx's autoindex or Apache.
+func (c httpContainer) readDirectoryListings() ([]string, error) {
+	var names []string
+	listed := map[string]bool{}
+	seen := map[string]bool{}
+
+	for _, directory := range []string{c.productPath, c.stemcellPath} {
+		directory = strings.Trim(directory, "/")
+		if listed[directory] {
+			continue
+		}
+		listed[directory] = true
+
+		directoryURL := c.baseURL
+		if directory != "" {
+			directoryURL = c.itemURL(directory + "/")
+		}
+
+		body, err := c.get(directoryURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not read directory listing: %s", err)
+		}
+
+		var listing strings.Builder
+		_, err = io.Copy(&listing, body)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read directory listing of %s: %s", directoryURL, err)
+		}
+
+		for _, match := range hrefRegex.FindAllStringSubmatch(listing.String(), -1) {
+			href, err := url.Parse(match[1])
+			if err != nil || href.RawQuery != "" || strings.HasSuffix(href.Path, "/") {
+				continue
+			}
+
+			fileURL := directoryURL.ResolveReference(href)
+			if fileURL.Host != c.baseURL.Host || !strings.HasPrefix(fileURL.Path, c.baseURL.Path) {
+				continue
+			}
+
+			name := strings.TrimPrefix(fileURL.Path, c.baseURL.Path)
+			if path.Dir(name) != path.Clean(directory) {
+				continue
+			}
+
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+func (c httpContainer) get(location *url.URL) (io.ReadCloser, error) {
+	response, err := c.client.Get(location.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("could not get %s: unexpected response %s", location, response.Status)
+	}
+
+	return response.Body, nil
+}
+
+// itemURL escapes each part of the name, as files are named with brackets
+// and commas, such as "[cf,2.7.4]cf-2.7.4.pivotal".
+func (c httpContainer) itemURL(name string) *url.URL {
+	parts := strings.Split(name, "/")
+	for index, part := range parts {
+		parts[index] = url.PathEscape(part)
+	}
+
+	reference, _ := url.Parse(strings.Join(parts, "/"))
+	return c.baseURL.ResolveReference(reference)
+}
+
+type httpItem struct {
+	client       *http.Client
+	name         string
+	url          *url.URL
+	size         int64
+	etag         string
+	lastModified time.Time
+}
+
+func (i httpItem) ID() string {
+	return i.name
+}
+
+func (i httpItem) Name() string {
+	return i.name
+}
+
+func (i httpItem) URL() *url.URL {
+	return i.url
+}
+
+func (i httpItem) Size() (int64, error) {
+	if i.size < 0 {
+		return 0, fmt.Errorf("the size of %s is not known until it is found", i.name)
+	}
+	return i.size, nil
+}
+
+func (i httpItem) Open() (io.ReadCloser, error) {
+	response, err := i.client.Get(i.url.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("could not download %s: unexpected response %s", i.url, response.Status)
+	}
+
+	return response.Body, nil
+}
+
+// OpenRange lets a download that was interrupted continue from where it
+// stopped. A server that ignores the range sends the whole file, and the
+// part that was already downloaded is skipped.
+func (i httpItem) OpenRange(start, end uint64) (io.ReadCloser, error) {
+	request, err := http.NewRequest("GET", i.url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	response, err := i.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		return response.Body, nil
+	case http.StatusOK:
+		_, err = io.CopyN(ioutil.Discard, response.Body, int64(start))
+		if err != nil {
+			response.Body.Close()
+			return nil, fmt.Errorf("could not download %s: %s", i.url, err)
+		}
+		return response.Body, nil
+	default:
+		response.Body.Close()
+		return nil, fmt.Errorf("could not download %s: unexpected response %s", i.url, response.Status)
+	}
+}
+
+func (i httpItem) ETag() (string, error) {
+	return i.etag, nil
+}
+
+func (i httpItem) LastMod() (time.Time, error) {
+	return i.lastModified, nil
+}
+
+func (i httpItem) Metadata() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func init() {
+	initializer := func(
+		c commands.DownloadProductOptions,
+		progressWriter io.Writer,
+		_ *log.Logger,
+		_ *log.Logger,
+	) (commands.ProductDownloader, error) {
+		config := HTTPConfiguration{
+			URL:          c.HTTPURL,
+			IndexFile:    c.HTTPIndexFile,
+			DisableSSL:   c.HTTPDisableSSL,
+			ProductPath:  c.ProductPath,
+			StemcellPath: c.StemcellPath,
+		}
+
+		return NewHTTPClient(config, progressWriter)
+	}
+
+	commands.RegisterProductClient("http", initializer)
+}
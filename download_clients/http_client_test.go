@@ -0,0 +1,299 @@
+package download_clients_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/om/download_clients"
+)
+
+var _ = Describe("HTTPClient", func() {
+	var (
+		server *ghttp.Server
+		config download_clients.HTTPConfiguration
+	)
+
+	const productListing = `<html><body><h1>Index of /artifactory/tiles/products/</h1><pre>
+<a href="../">../</a>
+<a href="%5Bcf%2C2.7.4%5Dcf-2.7.4-build.2.pivotal">[cf,2.7.4]cf-2.7.4-build.2.pivotal</a>   17-Oct-2019 10:12  9.50 GB
+<a href="%5Bcf%2C2.7.5%5Dcf-2.7.5-build.1.pivotal">[cf,2.7.5]cf-2.7.5-build.1.pivotal</a>   18-Oct-2019 10:12  9.51 GB
+<a href='%5Bp-mysql%2C2.7.1%5Dpivotal-mysql-2.7.1.pivotal'>[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal</a>
+<a href="archive/">archive/</a>
+<a href="?C=M;O=A">Last modified</a>
+</pre></body></html>`
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		config = download_clients.HTTPConfiguration{
+			URL:          server.URL() + "/artifactory/tiles",
+			ProductPath:  "products",
+			StemcellPath: "products",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("GetAllProductVersions", func() {
+		It("reads the directory listing of the product path", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/artifactory/tiles/products/"),
+					ghttp.RespondWith(http.StatusOK, productListing),
+				),
+			)
+
+			client, err := download_clients.NewHTTPClient(config, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Name()).To(Equal("http"))
+
+			versions, err := client.GetAllProductVersions("cf")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(Equal([]string{"2.7.4", "2.7.5"}))
+		})
+
+		It("reads the index file when there is one", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/artifactory/tiles/index.txt"),
+					ghttp.RespondWith(http.StatusOK, "# tiles\n/products/[cf,2.7.4]cf-2.7.4-build.2.pivotal\n\nproducts/[p-mysql,2.7.1]pivotal-mysql-2.7.1.pivotal\n"),
+				),
+			)
+
+			config.IndexFile = "index.txt"
+			client, err := download_clients.NewHTTPClient(config, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			versions, err := client.GetAllProductVersions("p-mysql")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(Equal([]string{"2.7.1"}))
+		})
+
+		It("returns an error when the listing cannot be read", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusForbidden, ""))
+
+			client, err := download_clients.NewHTTPClient(config, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetAllProductVersions("cf")
+			Expect(err).To(MatchError(ContainSubstring("could not read directory listing")))
+			Expect(err).To(MatchError(ContainSubstring("403")))
+		})
+	})
+
+	Describe("DownloadProductToFile", func() {
+		It("downloads the file matching the glob", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/artifactory/tiles/products/"),
+					ghttp.RespondWith(http.StatusOK, productListing),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("HEAD", "/artifactory/tiles/products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"),
+					ghttp.RespondWith(http.StatusOK, "", http.Header{"Content-Length": []string{"8"}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/artifactory/tiles/products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"),
+					ghttp.RespondWith(http.StatusOK, "cf 2.7.5"),
+				),
+			)
+
+			client, err := download_clients.NewHTTPClient(config, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			file, err := client.GetLatestProductFile("cf", "2.7.5", "cf-*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(file.Name()).To(Equal("products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"))
+
+			destination, err := ioutil.TempFile("", "")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(destination.Name())
+
+			err = client.DownloadProductToFile(file, destination)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(destination.Close()).To(Succeed())
+
+			contents, err := ioutil.ReadFile(destination.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("cf 2.7.5"))
+		})
+	})
+
+	Describe("resuming a download", func() {
+		var (
+			destination *os.File
+			checksummed bool
+		)
+
+		BeforeEach(func() {
+			var err error
+			destination, err = ioutil.TempFile("", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			config.IndexFile = "index.txt"
+			checksummed = true
+		})
+
+		AfterEach(func() {
+			Expect(os.Remove(destination.Name())).To(Succeed())
+			_ = os.Remove(destination.Name() + ".source")
+		})
+
+		download := func(handlers ...http.HandlerFunc) (string, error) {
+			if checksummed {
+				checksum := "0d5f4c4ef4bf5b8bba0e5d9d1c5f0c1b4b0c3e1bd2a7c8d1f2e3a4b5c6d7e8f9  [cf,2.7.5]cf-2.7.5-build.1.pivotal\n"
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, "products/[cf,2.7.5]cf-2.7.5-build.1.pivotal\nproducts/[cf,2.7.5]cf-2.7.5-build.1.pivotal.sha256\n"),
+					ghttp.RespondWith(http.StatusOK, ""),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/artifactory/tiles/products/[cf,2.7.5]cf-2.7.5-build.1.pivotal.sha256"),
+						ghttp.RespondWith(http.StatusOK, checksum),
+					),
+				)
+			} else {
+				server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "products/[cf,2.7.5]cf-2.7.5-build.1.pivotal\n"))
+			}
+			server.AppendHandlers(handlers...)
+
+			client, err := download_clients.NewHTTPClient(config, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			file, err := client.GetLatestProductFile("cf", "2.7.5", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = client.DownloadProductToFile(file, destination)
+
+			contents, readErr := ioutil.ReadFile(destination.Name())
+			Expect(readErr).ToNot(HaveOccurred())
+			return string(contents), err
+		}
+
+		head := func(etag string) http.HandlerFunc {
+			return ghttp.RespondWith(http.StatusOK, "", http.Header{
+				"Content-Length": []string{"8"},
+				"ETag":           []string{etag},
+			})
+		}
+
+		// headWithoutLength is a server that streams its responses, without
+		// their length
+		headWithoutLength := func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.(http.Flusher).Flush()
+		}
+
+		interruptAfter := func(head http.HandlerFunc) {
+			contents, err := download(
+				head,
+				func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("Content-Length", "8")
+					_, _ = w.Write([]byte("cf 2."))
+				},
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(contents).To(Equal("cf 2."))
+		}
+
+		interrupt := func(etag string) {
+			interruptAfter(head(etag))
+		}
+
+		It("asks for the rest of the file", func() {
+			interrupt(`"v1"`)
+
+			contents, err := download(
+				head(`"v1"`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/artifactory/tiles/products/[cf,2.7.5]cf-2.7.5-build.1.pivotal"),
+					ghttp.VerifyHeader(http.Header{"Range": []string{"bytes=5-7"}}),
+					ghttp.RespondWith(http.StatusPartialContent, "7.5"),
+				),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("cf 2.7.5"))
+		})
+
+		It("skips what was downloaded when the server sends the whole file", func() {
+			interrupt(`"v1"`)
+
+			contents, err := download(head(`"v1"`), ghttp.RespondWith(http.StatusOK, "cf 2.7.5"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("cf 2.7.5"))
+		})
+
+		It("starts again when the file was changed after the download was interrupted", func() {
+			interrupt(`"v1"`)
+
+			contents, err := download(
+				head(`"v2"`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeader(http.Header{"Range": nil}),
+					ghttp.RespondWith(http.StatusOK, "cf 2.7.6"),
+				),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("cf 2.7.6"))
+		})
+
+		It("starts again when the server does not identify the file", func() {
+			interrupt("")
+
+			contents, err := download(
+				head(""),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeader(http.Header{"Range": nil}),
+					ghttp.RespondWith(http.StatusOK, "cf 2.7.6"),
+				),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("cf 2.7.6"))
+		})
+
+		It("starts again when the server does not send the length of the file", func() {
+			interruptAfter(headWithoutLength)
+
+			contents, err := download(
+				headWithoutLength,
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeader(http.Header{"Range": nil}),
+					ghttp.RespondWith(http.StatusOK, "cf 2.7.5"),
+				),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("cf 2.7.5"))
+		})
+
+		It("continues a file without a checksum file next to it", func() {
+			checksummed = false
+			interrupt(`"v1"`)
+
+			contents, err := download(
+				head(`"v1"`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeader(http.Header{"Range": []string{"bytes=5-7"}}),
+					ghttp.RespondWith(http.StatusPartialContent, "7.5"),
+				),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("cf 2.7.5"))
+		})
+	})
+
+	Describe("configuration", func() {
+		It("requires a url", func() {
+			_, err := download_clients.NewHTTPClient(download_clients.HTTPConfiguration{}, GinkgoWriter)
+			Expect(err).To(MatchError(ContainSubstring("Field validation for 'URL' failed on the 'required' tag")))
+		})
+
+		It("requires an http or https url", func() {
+			config.URL = "ftp://example.com/tiles"
+			_, err := download_clients.NewHTTPClient(config, GinkgoWriter)
+			Expect(err).To(MatchError(`http url "ftp://example.com/tiles" must start with http:// or https://`))
+		})
+	})
+})
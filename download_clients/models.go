@@ -6,6 +6,7 @@ type PivnetFileArtifact struct {
 	slug          string
 	releaseID     int
 	productFileID int
+	size          int64
 }
 
 func (f PivnetFileArtifact) Name() string {
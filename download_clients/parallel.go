@@ -0,0 +1,134 @@
+package download_clients
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pivotal-cf/om/progress"
+)
+
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+func (r byteRange) length() int64 {
+	return r.end - r.start + 1
+}
+
+// splitRange divides the bytes from offset to the end of a file that is
+// size bytes long into at most connections ranges of about the same length.
+func splitRange(offset, size int64, connections int) []byteRange {
+	remaining := size - offset
+	if remaining <= 0 {
+		return nil
+	}
+
+	count := int64(connections)
+	if count < 1 {
+		count = 1
+	}
+	if count > remaining {
+		count = remaining
+	}
+
+	length := remaining / count
+	var ranges []byteRange
+	for i := int64(0); i < count; i++ {
+		r := byteRange{
+			start: offset + i*length,
+			end:   offset + (i+1)*length - 1,
+		}
+		if i == count-1 {
+			r.end = size - 1
+		}
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+// downloadRanges downloads the rest of a file with one connection for each
+// range, writing every range to its place in file. When a range fails, file
+// is cut back to what was downloaded without gaps, so that a later download
+// can continue from there. Until then, the download is recorded as not
+// being one that can be continued, in case it is killed.
+func downloadRanges(file *os.File, offset, size int64, connections int, openRange func(start, end int64) (io.ReadCloser, error), progressBar *progress.Bar) error {
+	rangesWritten, err := writingRanges(file.Name())
+	if err != nil {
+		return err
+	}
+
+	ranges := splitRange(offset, size, connections)
+	written := make([]int64, len(ranges))
+	errs := make([]error, len(ranges))
+
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		wg.Add(1)
+		go func(i int, r byteRange) {
+			defer wg.Done()
+			written[i], errs[i] = downloadRange(file, r, openRange, progressBar)
+		}(i, r)
+	}
+	wg.Wait()
+
+	var messages []string
+	for i, err := range errs {
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("bytes %d-%d: %s", ranges[i].start, ranges[i].end, err))
+		}
+	}
+
+	if len(messages) > 0 {
+		downloaded := offset
+		for i, r := range ranges {
+			downloaded = r.start + written[i]
+			if written[i] < r.length() {
+				break
+			}
+		}
+
+		if file.Truncate(downloaded) == nil {
+			rangesWritten()
+		}
+		return fmt.Errorf("could not download %d of %d ranges:\n  %s", len(messages), len(ranges), strings.Join(messages, "\n  "))
+	}
+
+	_, err = file.Seek(size, io.SeekStart)
+	return err
+}
+
+func downloadRange(file *os.File, r byteRange, openRange func(start, end int64) (io.ReadCloser, error), progressBar *progress.Bar) (int64, error) {
+	reader, err := openRange(r.start, r.end)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	written, err := io.Copy(
+		&rangeWriter{file: file, offset: r.start},
+		progressBar.NewProxyReader(io.LimitReader(reader, r.length())),
+	)
+	if err == nil && written < r.length() {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return written, err
+}
+
+// rangeWriter writes to a file from an offset, without moving the offset
+// of the file, so that several ranges can be written to it at once.
+type rangeWriter struct {
+	file   *os.File
+	offset int64
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
@@ -9,13 +9,13 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/go-version"
 	"github.com/pivotal-cf/go-pivnet"
 	"github.com/pivotal-cf/go-pivnet/download"
 	pivnetlog "github.com/pivotal-cf/go-pivnet/logger"
 	"github.com/pivotal-cf/go-pivnet/logshim"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/pivnet-cli/filter"
-	"github.com/pivotal-cf/pivnet-cli/gp"
 )
 
 //counterfeiter:generate -o ./fakes/pivnet_downloader_service.go --fake-name PivnetDownloader . PivnetDownloader
@@ -23,7 +23,7 @@ type PivnetDownloader interface {
 	ReleasesForProductSlug(productSlug string) ([]pivnet.Release, error)
 	ReleaseForVersion(productSlug string, releaseVersion string) (pivnet.Release, error)
 	ProductFilesForRelease(productSlug string, releaseID int) ([]pivnet.ProductFile, error)
-	DownloadProductFile(location *download.FileInfo, productSlug string, releaseID int, productFileID int, progressWriter io.Writer) error
+	DownloadProductFileFrom(location *download.FileInfo, productSlug string, releaseID int, productFileID int, offset int64, connections int, progressWriter io.Writer) error
 	ReleaseDependencies(productSlug string, releaseID int) ([]pivnet.ReleaseDependency, error)
 }
 
@@ -62,10 +62,15 @@ func NewPivnetClient(
 	}
 }
 
+// pivnetParallelConnections is how many ranges a Pivnet file is downloaded
+// in when --parallel-connections is not given, as go-pivnet's downloader did.
+const pivnetParallelConnections = 10
+
 type pivnetClient struct {
-	downloader     PivnetDownloader
-	filter         PivnetFilter
-	progressWriter io.Writer
+	downloader          PivnetDownloader
+	filter              PivnetFilter
+	progressWriter      io.Writer
+	parallelConnections int
 }
 
 func (p *pivnetClient) GetAllProductVersions(slug string) ([]string, error) {
@@ -113,6 +118,7 @@ func (p *pivnetClient) GetLatestProductFile(slug, version, glob string) (command
 		releaseID:     release.ID,
 		slug:          slug,
 		productFileID: productFiles[0].ID,
+		size:          int64(productFiles[0].Size),
 	}, nil
 }
 
@@ -122,21 +128,43 @@ func (p *pivnetClient) DownloadProductToFile(fa commands.FileArtifacter, file *o
 	if err != nil {
 		return fmt.Errorf("could not create fileInfo for download product file %s: %s", fileArtifact.slug, err.Error())
 	}
-	err = p.downloader.DownloadProductFile(fileInfo, fileArtifact.slug, fileArtifact.releaseID, fileArtifact.productFileID, p.progressWriter)
+
+	var source string
+	if fileArtifact.sha256 != "" {
+		source = fmt.Sprintf("%s release=%d product-file=%d sha256=%s", fileArtifact.slug, fileArtifact.releaseID, fileArtifact.productFileID, fileArtifact.sha256)
+	}
+	offset, err := resumeOffset(file, fileArtifact.size, source)
+	if err != nil {
+		return fmt.Errorf("could not resume the download of product file %s: %s", fileArtifact.slug, err)
+	}
+
+	// a download that is continued is fetched in one range, so that the
+	// file never has gaps when it is interrupted again
+	connections := p.parallelConnections
+	if connections < 1 {
+		connections = pivnetParallelConnections
+	}
+	if offset > 0 {
+		connections = 1
+	}
+
+	err = p.downloader.DownloadProductFileFrom(fileInfo, fileArtifact.slug, fileArtifact.releaseID, fileArtifact.productFileID, offset, connections, p.progressWriter)
 	if err != nil {
 		return fmt.Errorf("could not download product file %s: %s", fileArtifact.slug, err)
 	}
+
+	downloadFinished(file)
 	return nil
 }
 
-func (p *pivnetClient) GetLatestStemcellForProduct(fa commands.FileArtifacter, _ string) (commands.StemcellArtifacter, error) {
+func (p *pivnetClient) GetLatestStemcellForProduct(fa commands.FileArtifacter, _ string, constraints version.Constraints) (commands.StemcellArtifacter, error) {
 	fileArtifact := fa.(*PivnetFileArtifact)
 	dependencies, err := p.downloader.ReleaseDependencies(fileArtifact.slug, fileArtifact.releaseID)
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch stemcell dependency for %s: %s", fileArtifact.slug, err)
 	}
 
-	stemcellSlug, stemcellVersion, err := p.getLatestStemcell(dependencies)
+	stemcellSlug, stemcellVersion, err := p.getLatestStemcell(dependencies, constraints)
 	if err != nil {
 		return nil, fmt.Errorf("could not sort stemcell dependency: %s", err)
 	}
@@ -161,7 +189,7 @@ func (p *pivnetClient) checkForSingleProductFile(glob string, productFiles []piv
 	return nil
 }
 
-func (p *pivnetClient) getLatestStemcell(dependencies []pivnet.ReleaseDependency) (string, string, error) {
+func (p *pivnetClient) getLatestStemcell(dependencies []pivnet.ReleaseDependency, constraints version.Constraints) (string, string, error) {
 	var (
 		stemcellSlug string
 		versions     []string
@@ -174,6 +202,13 @@ func (p *pivnetClient) getLatestStemcell(dependencies []pivnet.ReleaseDependency
 		}
 	}
 
+	if constraints != nil {
+		versions = stemcellVersionsMatching(versions, constraints)
+		if len(versions) == 0 {
+			return "", "", fmt.Errorf("no stemcell dependencies match the stemcell version constraint '%s'", constraints)
+		}
+	}
+
 	stemcellVersion, err := getLatestStemcellVersion(versions)
 	if err != nil {
 		return "", "", err
@@ -213,6 +248,23 @@ func getLatestStemcellVersion(versions []string) (string, error) {
 	return stemcellVersion, nil
 }
 
+// stemcellVersionsMatching keeps the versions that satisfy constraints.
+func stemcellVersionsMatching(versions []string, constraints version.Constraints) []string {
+	var matching []string
+	for _, versionString := range versions {
+		v, err := version.NewVersion(versionString)
+		if err != nil {
+			continue
+		}
+
+		if constraints.Check(v) {
+			matching = append(matching, versionString)
+		}
+	}
+
+	return matching
+}
+
 func stemcellVersionPartsFromString(version string) (int, int, error) {
 	splitVersions := strings.Split(version, ".")
 	if len(splitVersions) == 1 {
@@ -236,7 +288,7 @@ func stemcellVersionPartsFromString(version string) (int, int, error) {
 }
 
 func DefaultPivnetFactory(ts pivnet.AccessTokenService, config pivnet.ClientConfig, logger pivnetlog.Logger) PivnetDownloader {
-	return gp.NewClient(ts, config, logger)
+	return newPivnetDownloader(ts, config, logger)
 }
 
 func init() {
@@ -253,14 +305,17 @@ func init() {
 		)
 		pivnetFilter := filter.NewFilter(logger)
 
-		return NewPivnetClient(
+		client := NewPivnetClient(
 			logger,
 			progressWriter,
 			DefaultPivnetFactory,
 			c.PivnetToken,
 			pivnetFilter,
 			c.PivnetDisableSSL,
-		), nil
+		)
+		client.parallelConnections = c.ParallelConnections
+
+		return client, nil
 	}
 
 	commands.RegisterProductClient("pivnet", initializer)
@@ -3,11 +3,15 @@ package download_clients_test
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 
+	"github.com/hashicorp/go-version"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/download"
 	log "github.com/pivotal-cf/go-pivnet/logger"
 	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
 	"github.com/pivotal-cf/om/commands"
@@ -160,17 +164,20 @@ var _ = Describe("PivnetClient", func() {
 		})
 
 		It("downloads a product file to given destination", func() {
-			fakePivnetDownloader.DownloadProductFileReturns(nil)
+			fakePivnetDownloader.DownloadProductFileFromReturns(nil)
 			tmpFile, err := ioutil.TempFile("", "")
 			Expect(err).ToNot(HaveOccurred())
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
 			err = client.DownloadProductToFile(createPivnetFileArtifact(), tmpFile)
 			Expect(err).ToNot(HaveOccurred())
+
+			_, _, _, _, _, connections, _ := fakePivnetDownloader.DownloadProductFileFromArgsForCall(0)
+			Expect(connections).To(Equal(10))
 		})
 
 		It("returns an error if the product file could not be downloaded", func() {
-			fakePivnetDownloader.DownloadProductFileReturns(errors.New("download error"))
+			fakePivnetDownloader.DownloadProductFileFromReturns(errors.New("download error"))
 			tmpFile, err := ioutil.TempFile("", "")
 			Expect(err).ToNot(HaveOccurred())
 
@@ -179,6 +186,131 @@ var _ = Describe("PivnetClient", func() {
 			Expect(err).To(MatchError(ContainSubstring("could not download product file")))
 		})
 
+		It("continues a download that was interrupted from what it has already written", func() {
+			tmpFile, err := ioutil.TempFile("", "")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(tmpFile.Name())
+
+			fakePivnetDownloader.DownloadProductFileFromStub = func(fileInfo *download.FileInfo, _ string, _, _ int, _ int64, _ int, _ io.Writer) error {
+				_, err := tmpFile.WriteString("start")
+				Expect(err).ToNot(HaveOccurred())
+				return errors.New("connection reset")
+			}
+
+			fakePivnetDownloader.ReleaseForVersionReturns(createRelease("1.0.0"), nil)
+			fakePivnetFilter.ProductFileKeysByGlobsReturns([]pivnet.ProductFile{createProductFile("someslug")}, nil)
+
+			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
+			artifact, err := client.GetLatestProductFile("someslug", "1.0.0", "*.zip")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = client.DownloadProductToFile(artifact, tmpFile)
+			Expect(err).To(MatchError(ContainSubstring("connection reset")))
+
+			fakePivnetDownloader.DownloadProductFileFromStub = nil
+			err = client.DownloadProductToFile(artifact, tmpFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			fileInfo, _, _, _, offset, connections, _ := fakePivnetDownloader.DownloadProductFileFromArgsForCall(1)
+			Expect(fileInfo.Name).To(Equal(tmpFile.Name()))
+			Expect(offset).To(Equal(int64(5)))
+			Expect(connections).To(Equal(1))
+			Expect(tmpFile.Name() + ".source").ToNot(BeAnExistingFile())
+		})
+
+		It("starts again when there is no checksum to verify the file with", func() {
+			tmpFile, err := ioutil.TempFile("", "")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(tmpFile.Name())
+
+			fakePivnetDownloader.DownloadProductFileFromStub = func(fileInfo *download.FileInfo, _ string, _, _ int, _ int64, _ int, _ io.Writer) error {
+				_, err := tmpFile.WriteString("start")
+				Expect(err).ToNot(HaveOccurred())
+				return errors.New("connection reset")
+			}
+
+			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
+			err = client.DownloadProductToFile(createPivnetFileArtifact(), tmpFile)
+			Expect(err).To(MatchError(ContainSubstring("connection reset")))
+
+			fakePivnetDownloader.DownloadProductFileFromStub = nil
+			err = client.DownloadProductToFile(createPivnetFileArtifact(), tmpFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, _, _, offset, _, _ := fakePivnetDownloader.DownloadProductFileFromArgsForCall(1)
+			Expect(offset).To(Equal(int64(0)))
+		})
+
+		It("starts again when the file was written for another release", func() {
+			tmpFile, err := ioutil.TempFile("", "")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(tmpFile.Name())
+			defer os.Remove(tmpFile.Name() + ".source")
+
+			fakePivnetDownloader.DownloadProductFileFromStub = func(fileInfo *download.FileInfo, _ string, _, _ int, _ int64, _ int, _ io.Writer) error {
+				_, err := tmpFile.WriteString("start")
+				Expect(err).ToNot(HaveOccurred())
+				return errors.New("connection reset")
+			}
+
+			fakePivnetDownloader.ReleaseForVersionReturns(createRelease("1.0.0"), nil)
+			fakePivnetFilter.ProductFileKeysByGlobsReturns([]pivnet.ProductFile{createProductFile("someslug")}, nil)
+
+			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
+			artifact, err := client.GetLatestProductFile("someslug", "1.0.0", "*.zip")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = client.DownloadProductToFile(artifact, tmpFile)
+			Expect(err).To(HaveOccurred())
+
+			fakePivnetDownloader.DownloadProductFileFromStub = nil
+			release := createRelease("1.0.1")
+			release.ID = 124
+			fakePivnetDownloader.ReleaseForVersionReturns(release, nil)
+
+			artifact, err = client.GetLatestProductFile("someslug", "1.0.1", "*.zip")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = client.DownloadProductToFile(artifact, tmpFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, releaseID, _, offset, _, _ := fakePivnetDownloader.DownloadProductFileFromArgsForCall(1)
+			Expect(releaseID).To(Equal(124))
+			Expect(offset).To(Equal(int64(0)))
+
+			contents, err := ioutil.ReadFile(tmpFile.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(BeEmpty())
+		})
+
+		It("starts again when the file is larger than the product file", func() {
+			productFile := createProductFile("someslug")
+			productFile.Size = 3
+			fakePivnetDownloader.ReleaseForVersionReturns(createRelease("1.0.0"), nil)
+			fakePivnetFilter.ProductFileKeysByGlobsReturns([]pivnet.ProductFile{productFile}, nil)
+
+			tmpFile, err := ioutil.TempFile("", "")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(tmpFile.Name())
+
+			_, err = tmpFile.WriteString("something else")
+			Expect(err).ToNot(HaveOccurred())
+
+			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
+			artifact, err := client.GetLatestProductFile("someslug", "1.0.0", "*.zip")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = client.DownloadProductToFile(artifact, tmpFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, _, _, offset, _, _ := fakePivnetDownloader.DownloadProductFileFromArgsForCall(0)
+			Expect(offset).To(Equal(int64(0)))
+
+			contents, err := ioutil.ReadFile(tmpFile.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(BeEmpty())
+		})
+
 	})
 
 	Context("GetLatestStemcellForProduct", func() {
@@ -203,7 +335,7 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(stemcell).ToNot(BeNil())
 			Expect(stemcell.Version()).To(Equal("1.0"))
@@ -215,7 +347,7 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(stemcell).ToNot(BeNil())
 			Expect(stemcell.Version()).To(Equal("1"))
@@ -229,7 +361,7 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(stemcell).ToNot(BeNil())
 			Expect(stemcell.Version()).To(Equal("5.10"))
@@ -243,12 +375,41 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(stemcell).ToNot(BeNil())
 			Expect(stemcell.Version()).To(Equal("1.3"))
 		})
 
+		It("downloads the latest stemcell that satisfies the constraints", func() {
+			fakePivnetDownloader.ReleaseDependenciesReturns([]pivnet.ReleaseDependency{
+				createReleaseDependency(789, "456.30", "someslug.stemcells"),
+				createReleaseDependency(789, "456.101", "someslug.stemcells"),
+				createReleaseDependency(789, "621.12", "someslug.stemcells"),
+			}, nil)
+
+			constraints, err := version.NewConstraint("~> 456.0")
+			Expect(err).ToNot(HaveOccurred())
+
+			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
+			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", constraints)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stemcell.Version()).To(Equal("456.101"))
+		})
+
+		It("returns an error when no stemcell satisfies the constraints", func() {
+			fakePivnetDownloader.ReleaseDependenciesReturns([]pivnet.ReleaseDependency{
+				createReleaseDependency(789, "621.12", "someslug.stemcells"),
+			}, nil)
+
+			constraints, err := version.NewConstraint("< 621")
+			Expect(err).ToNot(HaveOccurred())
+
+			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
+			_, err = client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", constraints)
+			Expect(err).To(MatchError(ContainSubstring("no stemcell dependencies match the stemcell version constraint '< 621'")))
+		})
+
 		It("downloads the stemcell with the highest minor", func() {
 			fakePivnetDownloader.ReleaseDependenciesReturns([]pivnet.ReleaseDependency{
 				createReleaseDependency(789, "97.190", "someslug.stemcells"),
@@ -257,7 +418,7 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			stemcell, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(stemcell).ToNot(BeNil())
 			Expect(stemcell.Version()).To(Equal("97.190"))
@@ -267,7 +428,7 @@ var _ = Describe("PivnetClient", func() {
 			fakePivnetDownloader.ReleaseDependenciesReturns([]pivnet.ReleaseDependency{}, errors.New("stemcell not found"))
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).To(MatchError(ContainSubstring("could not fetch stemcell dependency for")))
 		})
 
@@ -277,7 +438,7 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).To(MatchError(ContainSubstring("could not sort stemcell dependency")))
 			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf(errorTemplateForStemcell, "1.0.0"))))
 		})
@@ -288,7 +449,7 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf(errorTemplateForStemcell, "abc1.0"))))
 		})
 
@@ -298,7 +459,7 @@ var _ = Describe("PivnetClient", func() {
 			}, nil)
 
 			client := download_clients.NewPivnetClient(logger, nil, fakePivnetFactory, "", fakePivnetFilter, true)
-			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "")
+			_, err := client.GetLatestStemcellForProduct(createPivnetFileArtifact(), "", nil)
 			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf(errorTemplateForStemcell, "1.0def"))))
 		})
 	})
@@ -0,0 +1,115 @@
+package download_clients
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/download"
+	pivnetlog "github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/pivnet-cli/gp"
+)
+
+// pivnetDownloader downloads product files with go-pivnet's downloader, but
+// only the ranges after an offset, so that an interrupted download can
+// continue from where it stopped. go-pivnet always downloads 10 ranges at
+// once, which leaves gaps in the file when it is interrupted.
+type pivnetDownloader struct {
+	*gp.Client
+	client     pivnet.Client
+	downloader download.Client
+}
+
+func newPivnetDownloader(ts pivnet.AccessTokenService, config pivnet.ClientConfig, logger pivnetlog.Logger) pivnetDownloader {
+	return pivnetDownloader{
+		Client: gp.NewClient(ts, config, logger),
+		client: pivnet.NewClient(ts, config, logger),
+		downloader: download.Client{
+			HTTPClient: &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						InsecureSkipVerify: config.SkipSSLValidation,
+					},
+					Proxy: http.ProxyFromEnvironment,
+				},
+			},
+			Logger:  logger,
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+func (d pivnetDownloader) DownloadProductFileFrom(location *download.FileInfo, productSlug string, releaseID int, productFileID int, offset int64, connections int, progressWriter io.Writer) error {
+	productFile, err := d.client.ProductFiles.GetForRelease(productSlug, releaseID, productFileID)
+	if err != nil {
+		return fmt.Errorf("GetForRelease: %s", err)
+	}
+
+	downloadLink, err := productFile.DownloadLink()
+	if err != nil {
+		return fmt.Errorf("DownloadLink: %s", err)
+	}
+
+	rangesWritten := func() {}
+	if connections > 1 {
+		rangesWritten, err = writingRanges(location.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	downloader := d.downloader
+	downloader.Ranger = resumeRanger{offset: offset, connections: connections}
+	downloader.Bar = offsetBar{Bar: download.NewBar(), offset: offset}
+
+	err = downloader.Get(location, pivnet.NewProductFileLinkFetcher(downloadLink, d.client), progressWriter)
+	if err != nil {
+		if connections > 1 {
+			// which of the ranges were finished is not known,
+			// so only what was there before is kept
+			if os.Truncate(location.Name, offset) == nil {
+				rangesWritten()
+			}
+		}
+		return fmt.Errorf("Downloader.Get: %s", err)
+	}
+
+	return nil
+}
+
+// resumeRanger divides everything after the offset into a range for each
+// connection.
+type resumeRanger struct {
+	offset      int64
+	connections int
+}
+
+func (r resumeRanger) BuildRange(contentLength int64) ([]download.Range, error) {
+	if r.offset > contentLength {
+		return nil, fmt.Errorf("already downloaded %d bytes of a file that is %d bytes long", r.offset, contentLength)
+	}
+
+	var ranges []download.Range
+	for _, br := range splitRange(r.offset, contentLength, r.connections) {
+		ranges = append(ranges, download.NewRange(br.start, br.end, http.Header{
+			"Range": []string{fmt.Sprintf("bytes=%d-%d", br.start, br.end)},
+		}))
+	}
+
+	return ranges, nil
+}
+
+// offsetBar counts what was downloaded before the offset as done.
+type offsetBar struct {
+	download.Bar
+	offset int64
+}
+
+func (b offsetBar) Kickoff() {
+	b.Bar.Kickoff()
+	b.Add64(b.offset)
+}
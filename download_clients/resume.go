@@ -0,0 +1,90 @@
+package download_clients
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// resumeOffset is how much of the file a download that was interrupted has
+// already written, so that the download can continue from there.
+//
+// source identifies what is being downloaded, such as the release and SHA256
+// of a Pivnet file or the etag of a blob, and is recorded next to the file.
+// The file is emptied to start again when it was not written for the same
+// source, or when it is larger than size. A download without a source is
+// never continued, as a file of the same name from somewhere else could not
+// be told apart from it. size is 0 when it is not known.
+func resumeOffset(file *os.File, size int64, source string) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	sourcePath := file.Name() + ".source"
+	recorded, _ := ioutil.ReadFile(sourcePath)
+
+	offset := info.Size()
+	if offset > 0 && (source == "" || string(recorded) != source || (size > 0 && offset > size)) {
+		offset = 0
+		err = restartDownload(file)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if source == "" {
+		_ = os.Remove(sourcePath)
+		return 0, nil
+	}
+
+	err = ioutil.WriteFile(sourcePath, []byte(source), 0600)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	return offset, err
+}
+
+// writingRanges records next to the file that several ranges are being
+// written to it at once, which leaves gaps in it until all of them are
+// finished. A download that is killed before then is not continued, as
+// the gaps could not be told apart from what was downloaded. The returned
+// func records the source again, for once the file has been cut back to
+// what was downloaded without gaps.
+func writingRanges(path string) (func(), error) {
+	sourcePath := path + ".source"
+	recorded, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		// without a source, the download is not continued anyway
+		return func() {}, nil
+	}
+
+	err = ioutil.WriteFile(sourcePath, append(recorded, " writing-ranges"...), 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = ioutil.WriteFile(sourcePath, recorded, 0600)
+	}, nil
+}
+
+// downloadFinished removes the source recorded by resumeOffset, once the
+// file has been downloaded and there is nothing left to continue.
+func downloadFinished(file *os.File) {
+	_ = os.Remove(file.Name() + ".source")
+}
+
+// restartDownload empties a file that an interrupted download has written
+// to, for when the download cannot continue from where it stopped.
+func restartDownload(file *os.File) error {
+	err := file.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	return err
+}
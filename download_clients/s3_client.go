@@ -7,21 +7,23 @@ import (
 	"github.com/pivotal-cf/om/commands"
 	"gopkg.in/go-playground/validator.v9"
 	"io"
+	"io/ioutil"
 	"log"
 	"strconv"
 )
 
 type S3Configuration struct {
-	Bucket          string `validate:"required"`
-	AccessKeyID     string
-	SecretAccessKey string
-	RegionName      string `validate:"required"`
-	Endpoint        string
-	DisableSSL      bool
-	EnableV2Signing bool
-	ProductPath     string
-	StemcellPath    string
-	AuthType        string
+	Bucket              string `validate:"required"`
+	AccessKeyID         string
+	SecretAccessKey     string
+	RegionName          string `validate:"required"`
+	Endpoint            string
+	DisableSSL          bool
+	EnableV2Signing     bool
+	ProductPath         string
+	StemcellPath        string
+	ParallelConnections int
+	AuthType            string
 }
 
 func NewS3Client(stower Stower, config S3Configuration, progressWriter io.Writer) (stowClient, error) {
@@ -52,7 +54,7 @@ func NewS3Client(stower Stower, config S3Configuration, progressWriter io.Writer
 		s3.ConfigAuthType:    config.AuthType,
 	}
 
-	return NewStowClient(
+	client := NewStowClient(
 		stower,
 		config.Bucket,
 		stowConfig,
@@ -60,7 +62,10 @@ func NewS3Client(stower Stower, config S3Configuration, progressWriter io.Writer
 		config.ProductPath,
 		config.StemcellPath,
 		"s3",
-	), nil
+	)
+	client.parallelConnections = config.ParallelConnections
+
+	return client, nil
 }
 
 func validateAccessKeyAuthType(config S3Configuration) error {
@@ -82,6 +87,26 @@ func init() {
 		_ *log.Logger,
 		_ *log.Logger,
 	) (commands.ProductDownloader, error) {
+		config := S3Configuration{
+			Bucket:              c.Bucket,
+			AccessKeyID:         c.S3AccessKeyID,
+			AuthType:            c.S3AuthType,
+			SecretAccessKey:     c.S3SecretAccessKey,
+			RegionName:          c.S3RegionName,
+			Endpoint:            c.S3Endpoint,
+			DisableSSL:          c.S3DisableSSL,
+			EnableV2Signing:     c.S3EnableV2Signing,
+			ProductPath:         c.ProductPath,
+			StemcellPath:        c.StemcellPath,
+			ParallelConnections: c.ParallelConnections,
+		}
+
+		return NewS3Client(wrapStow{}, config, progressWriter)
+	}
+
+	commands.RegisterProductClient("s3", initializer)
+
+	commands.RegisterBlobstore("s3", func(c commands.BlobstoreOptions) (commands.Blobstore, error) {
 		config := S3Configuration{
 			Bucket:          c.Bucket,
 			AccessKeyID:     c.S3AccessKeyID,
@@ -91,12 +116,8 @@ func init() {
 			Endpoint:        c.S3Endpoint,
 			DisableSSL:      c.S3DisableSSL,
 			EnableV2Signing: c.S3EnableV2Signing,
-			ProductPath:     c.ProductPath,
-			StemcellPath:    c.StemcellPath,
 		}
 
-		return NewS3Client(wrapStow{}, config, progressWriter)
-	}
-
-	commands.RegisterProductClient("s3", initializer)
+		return NewS3Client(wrapStow{}, config, ioutil.Discard)
+	})
 }
@@ -14,6 +14,7 @@ import (
 	"io"
 	"io/ioutil"
 	"text/template"
+	"time"
 )
 
 var _ = Describe("S3Client", func() {
@@ -277,6 +278,14 @@ func (m mockItem) Size() (int64, error) {
 	return 0, nil
 }
 
+func (m mockItem) ETag() (string, error) {
+	return "", nil
+}
+
+func (m mockItem) LastMod() (time.Time, error) {
+	return time.Time{}, nil
+}
+
 func createPivotalFile(productFileName, stemcellName, stemcellVersion string) string {
 	tempfile, err := ioutil.TempFile("", productFileName)
 	Expect(err).ToNot(HaveOccurred())
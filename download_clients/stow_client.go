@@ -5,14 +5,17 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/graymeta/stow"
+	"github.com/hashicorp/go-version"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/progress"
 	"gopkg.in/yaml.v2"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -38,13 +41,14 @@ func (d wrapStow) Walk(container stow.Container, prefix string, pageSize int, fn
 }
 
 type stowClient struct {
-	stower         Stower
-	bucket         string
-	Config         stow.Config
-	progressWriter io.Writer
-	productPath    string
-	stemcellPath   string
-	kind           string
+	stower              Stower
+	bucket              string
+	Config              stow.Config
+	progressWriter      io.Writer
+	productPath         string
+	stemcellPath        string
+	kind                string
+	parallelConnections int
 }
 
 func NewStowClient(stower Stower, bucket string, config stow.ConfigMap, progressWriter io.Writer, productPath string, stemcellPath string, kind string) stowClient {
@@ -179,6 +183,11 @@ func (s stowClient) GetLatestProductFile(slug, version, glob string) (commands.F
 	}
 
 	for _, f := range prefixedFilepaths {
+		// mirror-products writes a .sha256 checksum file next to each file
+		if strings.HasSuffix(f, ".sha256") {
+			continue
+		}
+
 		removePrefixRegex := regexp.MustCompile(`^\[.*\]`)
 		baseFilename := removePrefixRegex.ReplaceAllString(filepath.Base(f), "")
 
@@ -200,32 +209,143 @@ func (s stowClient) GetLatestProductFile(slug, version, glob string) (commands.F
 		return nil, fmt.Errorf("the glob '%s' matches no file\navailable files: %s", glob, availableFiles)
 	}
 
-	return &stowFileArtifact{name: globMatchedFilepaths[0]}, nil
+	name := globMatchedFilepaths[0]
+	artifact := &stowFileArtifact{name: name}
+
+	// the checksum that mirror-products writes next to the file is what the
+	// download is verified with
+	for _, f := range files {
+		if f == name+".sha256" {
+			artifact.sha256, err = commands.ReadBlobstoreChecksum(s, name)
+			if err != nil {
+				return nil, fmt.Errorf("could not read the checksum of %s: %s", name, err)
+			}
+			break
+		}
+	}
+
+	return artifact, nil
 }
 
 func (s stowClient) DownloadProductToFile(fa commands.FileArtifacter, destinationFile *os.File) error {
-	blobReader, size, err := s.initializeBlobReader(fa.Name())
+	item, err := s.getItem(fa.Name())
+	if err != nil {
+		return err
+	}
+
+	size, err := item.Size()
+	if err != nil {
+		return err
+	}
+
+	offset, err := resumeOffset(destinationFile, size, stowSource(fa, item, size))
+	if err != nil {
+		return err
+	}
+
+	// an item whose size is not known, such as one from an http server that
+	// sends no Content-Length, cannot be divided into ranges
+	if ranger, ok := item.(stow.ItemRanger); ok && s.parallelConnections > 1 && size > 0 {
+		progressBar := s.startProgressBar(size, offset)
+		defer progressBar.Finish()
+
+		err = downloadRanges(destinationFile, offset, size, s.parallelConnections, func(start, end int64) (io.ReadCloser, error) {
+			return ranger.OpenRange(uint64(start), uint64(end))
+		}, progressBar)
+		if err != nil {
+			return err
+		}
+
+		downloadFinished(destinationFile)
+		return nil
+	}
+
+	blobReader, offset, err := s.openItemFrom(item, size, offset, destinationFile)
 	if err != nil {
 		return err
 	}
+	defer blobReader.Close()
 
-	progressBar, wrappedBlobReader := s.startProgressBar(size, blobReader)
+	progressBar := s.startProgressBar(size, offset)
 	defer progressBar.Finish()
 
-	if err = s.streamBufferToFile(destinationFile, wrappedBlobReader); err != nil {
+	if err = s.streamBufferToFile(destinationFile, progressBar.NewProxyReader(blobReader)); err != nil {
 		return err
 	}
 
+	downloadFinished(destinationFile)
 	return nil
 }
 
-func (s *stowClient) initializeBlobReader(filename string) (blobToRead io.ReadCloser, fileSize int64, err error) {
-	container, err := s.getContainer()
+// stowSource identifies an item by its size, etag and when it was last
+// modified, so that a download is only continued from a file written for
+// the same item. Items that have neither an etag nor a modification time
+// are not identified, and neither are items whose size is not known. An
+// item without a .sha256 file next to it is still continued; only the
+// checksum is then not verified once it is downloaded.
+func stowSource(fa commands.FileArtifacter, item stow.Item, size int64) string {
+	if size <= 0 {
+		return ""
+	}
+
+	etag, _ := item.ETag()
+
+	var modified string
+	if lastModified, err := item.LastMod(); err == nil && !lastModified.IsZero() {
+		modified = lastModified.UTC().Format(time.RFC3339Nano)
+	}
+
+	if etag == "" && modified == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s size=%d etag=%s last-modified=%s", fa.Name(), size, etag, modified)
+}
+
+// openItemFrom opens the item after what an interrupted download has already
+// written. Items that cannot be read from an offset are downloaded again
+// from the start.
+func (s stowClient) openItemFrom(item stow.Item, size, offset int64, destinationFile *os.File) (io.ReadCloser, int64, error) {
+	if offset == 0 {
+		reader, err := item.Open()
+		return reader, 0, err
+	}
+
+	if offset == size {
+		return ioutil.NopCloser(strings.NewReader("")), offset, nil
+	}
+
+	if ranger, ok := item.(stow.ItemRanger); ok {
+		reader, err := ranger.OpenRange(uint64(offset), uint64(size-1))
+		return reader, offset, err
+	}
+
+	reader, err := item.Open()
 	if err != nil {
 		return nil, 0, err
 	}
 
-	item, err := container.Item(filename)
+	if seeker, ok := reader.(io.Seeker); ok {
+		_, err = seeker.Seek(offset, io.SeekStart)
+		if err == nil {
+			return reader, offset, nil
+		}
+	}
+
+	return reader, 0, restartDownload(destinationFile)
+}
+
+func (s *stowClient) getItem(filename string) (stow.Item, error) {
+	container, err := s.getContainer()
+	if err != nil {
+		return nil, err
+	}
+
+	return container.Item(filename)
+}
+
+func (s *stowClient) initializeBlobReader(filename string) (blobToRead io.ReadCloser, fileSize int64, err error) {
+	item, err := s.getItem(filename)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -238,14 +358,14 @@ func (s *stowClient) initializeBlobReader(filename string) (blobToRead io.ReadCl
 	return blobToRead, fileSize, err
 }
 
-func (s stowClient) startProgressBar(size int64, item io.Reader) (progressBar *progress.Bar, reader io.Reader) {
-	progressBar = progress.NewBar()
+func (s stowClient) startProgressBar(size, offset int64) *progress.Bar {
+	progressBar := progress.NewBar()
 	progressBar.SetTotal64(size)
+	progressBar.Set64(offset)
 	progressBar.SetOutput(s.progressWriter)
-	reader = progressBar.NewProxyReader(item)
 	_, _ = s.progressWriter.Write([]byte("Downloading product from s..."))
 	progressBar.Start()
-	return progressBar, reader
+	return progressBar
 }
 
 func (s stowClient) streamBufferToFile(destinationFile *os.File, wrappedBlobReader io.Reader) error {
@@ -253,18 +373,83 @@ func (s stowClient) streamBufferToFile(destinationFile *os.File, wrappedBlobRead
 	return err
 }
 
-func (s stowClient) GetLatestStemcellForProduct(_ commands.FileArtifacter, downloadedProductFileName string) (commands.StemcellArtifacter, error) {
-	definedStemcell, err := stemcellFromProduct(downloadedProductFileName)
+func (s stowClient) Put(name string, r io.Reader, size int64) error {
+	container, err := s.getContainer()
+	if err != nil {
+		return err
+	}
+
+	_, err = container.Put(name, r, size, nil)
+	return err
+}
+
+func (s stowClient) Open(name string) (io.ReadCloser, int64, error) {
+	return s.initializeBlobReader(name)
+}
+
+func (s stowClient) List(prefix string) ([]commands.BlobstoreFile, error) {
+	container, err := s.getContainer()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []commands.BlobstoreFile
+	err = s.stower.Walk(container, prefix, 100, func(item stow.Item, err error) error {
+		if err != nil {
+			return err
+		}
+
+		size, err := item.Size()
+		if err != nil {
+			return err
+		}
+
+		lastModified, err := item.LastMod()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, commands.BlobstoreFile{
+			Name:         item.Name(),
+			Size:         size,
+			LastModified: lastModified,
+		})
+		return nil
+	})
+
+	return files, err
+}
+
+func (s stowClient) Delete(name string) error {
+	container, err := s.getContainer()
+	if err != nil {
+		return err
+	}
+
+	item, err := container.Item(name)
+	if err != nil {
+		return err
+	}
+
+	return container.RemoveItem(item.ID())
+}
+
+// GetLatestStemcellForProduct chooses the stemcell from the stemcell path
+// that satisfies the stemcell_criteria of the product: the same os, the same
+// major version and, unless enable_patch_security_updates is false, the latest
+// patch of it.
+func (s stowClient) GetLatestStemcellForProduct(_ commands.FileArtifacter, downloadedProductFileName string, constraints version.Constraints) (commands.StemcellArtifacter, error) {
+	criteria, err := stemcellCriteriaFromProduct(downloadedProductFileName)
 	if err != nil {
 		return nil, err
 	}
 
-	definedMajor, definedPatch, err := stemcellVersionPartsFromString(definedStemcell.Version())
+	definedMajor, definedPatch, err := stemcellVersionPartsFromString(criteria.Version)
 	if err != nil {
 		return nil, err
 	}
 
-	allStemcellVersions, err := s.getAllProductVersionsFromPath(definedStemcell.Slug(), s.stemcellPath)
+	slug, allStemcellVersions, err := s.stemcellsForOS(criteria.Os)
 	if err != nil {
 		return nil, fmt.Errorf("could not find stemcells on %s: %s", s.kind, err)
 	}
@@ -273,13 +458,30 @@ func (s stowClient) GetLatestStemcellForProduct(_ commands.FileArtifacter, downl
 	for _, version := range allStemcellVersions {
 		major, patch, _ := stemcellVersionPartsFromString(version)
 
+		if criteria.PatchSecurityUpdates == "false" {
+			if major == definedMajor && patch == definedPatch {
+				filteredVersions = append(filteredVersions, version)
+			}
+			continue
+		}
+
 		if major == definedMajor && patch >= definedPatch {
 			filteredVersions = append(filteredVersions, version)
 		}
 	}
 
 	if len(filteredVersions) == 0 {
-		return nil, fmt.Errorf("no versions could be found equal to or greater than %s", definedStemcell.Version())
+		if criteria.PatchSecurityUpdates == "false" {
+			return nil, fmt.Errorf("no versions could be found equal to %s, which the product requires exactly as it disables patch security updates", criteria.Version)
+		}
+		return nil, fmt.Errorf("no versions could be found equal to or greater than %s", criteria.Version)
+	}
+
+	if constraints != nil {
+		filteredVersions = stemcellVersionsMatching(filteredVersions, constraints)
+		if len(filteredVersions) == 0 {
+			return nil, fmt.Errorf("no versions equal to or greater than %s match the stemcell version constraint '%s'", criteria.Version, constraints)
+		}
 	}
 
 	latestVersion, err := getLatestStemcellVersion(filteredVersions)
@@ -289,10 +491,89 @@ func (s stowClient) GetLatestStemcellForProduct(_ commands.FileArtifacter, downl
 
 	return &stemcell{
 		version: latestVersion,
-		slug:    definedStemcell.Slug(),
+		slug:    slug,
 	}, nil
 }
 
+var stemcellNameToPivnetProductName = map[string]string{
+	"ubuntu-xenial": "stemcells-ubuntu-xenial",
+	"ubuntu-trusty": "stemcells",
+	"windows2016":   "stemcells-windows-server",
+	"windows1803":   "stemcells-windows-server",
+	"windows2019":   "stemcells-windows-server",
+}
+
+// stemcellsForOS finds the slug and versions of the stemcells for an os in
+// the stemcell path. A stemcell is for the os when it has the pivnet slug of
+// the os, or when the os is in its file name, as in
+// [stemcells-ubuntu-xenial,456.30]light-bosh-stemcell-456.30-google-kvm-ubuntu-xenial-go_agent.tgz.
+// When some file names have the os in them, only those are used, as
+// stemcells for several versions of windows have the same slug.
+func (s stowClient) stemcellsForOS(stemcellOS string) (string, []string, error) {
+	files, err := s.listFiles()
+	if err != nil {
+		return "", nil, err
+	}
+
+	stemcellFileRegex := regexp.MustCompile(
+		fmt.Sprintf(`^/?%s/?\[([^,\]]+),(.*?)\](.*)$`,
+			regexp.QuoteMeta(strings.Trim(s.stemcellPath, "/")),
+		),
+	)
+
+	type stemcellFile struct {
+		slug, version string
+		namesOS       bool
+	}
+
+	knownSlug := stemcellNameToPivnetProductName[stemcellOS]
+	var candidates []stemcellFile
+	namingOS := false
+	for _, fileName := range files {
+		match := stemcellFileRegex.FindStringSubmatch(fileName)
+		if match == nil {
+			continue
+		}
+
+		file := stemcellFile{
+			slug:    match[1],
+			version: match[2],
+			namesOS: strings.Contains(match[3], "-"+stemcellOS+"-"),
+		}
+		if file.slug == knownSlug || file.namesOS {
+			candidates = append(candidates, file)
+			namingOS = namingOS || file.namesOS
+		}
+	}
+
+	if len(candidates) == 0 {
+		if knownSlug != "" {
+			return "", nil, fmt.Errorf("no files matching pivnet-product-slug %s found", knownSlug)
+		}
+		return "", nil, fmt.Errorf("no files for stemcell os %s found", stemcellOS)
+	}
+
+	slug := knownSlug
+	var versions []string
+	versionFound := make(map[string]bool)
+	for _, file := range candidates {
+		if namingOS && !file.namesOS {
+			continue
+		}
+
+		if slug == "" {
+			slug = file.slug
+		}
+
+		if file.slug == slug && !versionFound[file.version] {
+			versions = append(versions, file.version)
+			versionFound[file.version] = true
+		}
+	}
+
+	return slug, versions, nil
+}
+
 type stemcellMetadata struct {
 	Metadata internalStemcellMetadata `yaml:"stemcell_criteria"`
 }
@@ -303,11 +584,11 @@ type internalStemcellMetadata struct {
 	PatchSecurityUpdates string `yaml:"enable_patch_security_updates"`
 }
 
-func stemcellFromProduct(filename string) (*stemcell, error) {
+func stemcellCriteriaFromProduct(filename string) (internalStemcellMetadata, error) {
 	// Open a zip archive for reading.
 	tileZipReader, err := zip.OpenReader(filename)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse tile. Ensure that downloaded file is a valid pivotal tile: %s", err)
+		return internalStemcellMetadata{}, fmt.Errorf("could not parse tile. Ensure that downloaded file is a valid pivotal tile: %s", err)
 	}
 
 	defer tileZipReader.Close()
@@ -320,34 +601,23 @@ func stemcellFromProduct(filename string) (*stemcell, error) {
 		if metadataRegex.MatchString(file.Name) {
 			metadataReadCloser, err := file.Open()
 			if err != nil {
-				return nil, err
+				return internalStemcellMetadata{}, err
 			}
 
 			metadataBuffer := new(bytes.Buffer)
 			_, err = metadataBuffer.ReadFrom(metadataReadCloser)
 			if err != nil {
-				return nil, err
+				return internalStemcellMetadata{}, err
 			}
 
 			metadata := stemcellMetadata{}
 			err = yaml.Unmarshal(metadataBuffer.Bytes(), &metadata)
 			if err != nil {
-				return nil, err
-			}
-
-			stemcellNameToPivnetProductName := map[string]string{
-				"ubuntu-xenial": "stemcells-ubuntu-xenial",
-				"ubuntu-trusty": "stemcells",
-				"windows2016":   "stemcells-windows-server",
-				"windows1803":   "stemcells-windows-server",
-				"windows2019":   "stemcells-windows-server",
+				return internalStemcellMetadata{}, err
 			}
 
-			return &stemcell{
-				slug:    stemcellNameToPivnetProductName[metadata.Metadata.Os],
-				version: metadata.Metadata.Version,
-			}, nil
+			return metadata.Metadata, nil
 		}
 	}
-	return nil, fmt.Errorf("could not find the appropriate stemcell associated with the tile: %s", filename)
+	return internalStemcellMetadata{}, fmt.Errorf("could not find the appropriate stemcell associated with the tile: %s", filename)
 }
@@ -0,0 +1,215 @@
+package extractor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// opsManagerProductName is how the metadata of a product names Ops Manager
+// among the products it requires.
+const opsManagerProductName = "p-bosh"
+
+// ProductInspection is what the metadata of a product says about what it
+// requires and what it deploys.
+type ProductInspection struct {
+	Name                        string              `json:"name" yaml:"name"`
+	Version                     string              `json:"version" yaml:"version"`
+	MinimumVersionForUpgrade    string              `json:"minimum_version_for_upgrade,omitempty" yaml:"minimum_version_for_upgrade,omitempty"`
+	OpsManagerVersion           string              `json:"ops_manager_version,omitempty" yaml:"ops_manager_version,omitempty"`
+	MinimumOpsManagerVersion    string              `json:"minimum_ops_manager_version,omitempty" yaml:"minimum_ops_manager_version,omitempty"`
+	StemcellCriteria            StemcellCriteria    `json:"stemcell_criteria" yaml:"stemcell_criteria"`
+	AdditionalStemcellsCriteria []StemcellCriteria  `json:"additional_stemcells_criteria,omitempty" yaml:"additional_stemcells_criteria,omitempty"`
+	ProductDependencies         []ProductDependency `json:"product_dependencies" yaml:"product_dependencies"`
+	Releases                    []Release           `json:"releases" yaml:"releases"`
+	JobTypes                    []JobType           `json:"job_types" yaml:"job_types"`
+	Errands                     Errands             `json:"errands" yaml:"errands"`
+	PropertyBlueprints          PropertyBlueprints  `json:"property_blueprints" yaml:"property_blueprints"`
+}
+
+type StemcellCriteria struct {
+	OS                         string `json:"os" yaml:"os"`
+	Version                    string `json:"version" yaml:"version"`
+	RequiresCPI                bool   `json:"requires_cpi,omitempty" yaml:"requires_cpi,omitempty"`
+	EnablePatchSecurityUpdates *bool  `json:"enable_patch_security_updates,omitempty" yaml:"enable_patch_security_updates,omitempty"`
+}
+
+// ProductDependency is another product, other than Ops Manager, that must
+// be deployed with a version satisfying the constraint.
+type ProductDependency struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type Release struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+	File    string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+type JobType struct {
+	Name                  string `json:"name" yaml:"name"`
+	Label                 string `json:"label,omitempty" yaml:"label,omitempty"`
+	Errand                bool   `json:"errand,omitempty" yaml:"errand,omitempty"`
+	Instances             *int   `json:"instances,omitempty" yaml:"instances,omitempty"`
+	InstancesConfigurable bool   `json:"instances_configurable,omitempty" yaml:"instances_configurable,omitempty"`
+	PropertyBlueprints    int    `json:"property_blueprints" yaml:"property_blueprints"`
+}
+
+type Errands struct {
+	PostDeploy []string `json:"post_deploy" yaml:"post_deploy"`
+	PreDelete  []string `json:"pre_delete" yaml:"pre_delete"`
+}
+
+// PropertyBlueprints counts the properties of the product, and of all its
+// job types together.
+type PropertyBlueprints struct {
+	Product      int `json:"product" yaml:"product"`
+	Configurable int `json:"configurable" yaml:"configurable"`
+	Jobs         int `json:"jobs" yaml:"jobs"`
+}
+
+type inspectedMetadata struct {
+	Name                        string             `yaml:"name"`
+	Version                     string             `yaml:"product_version"`
+	MinimumVersionForUpgrade    string             `yaml:"minimum_version_for_upgrade"`
+	StemcellCriteria            StemcellCriteria   `yaml:"stemcell_criteria"`
+	AdditionalStemcellsCriteria []StemcellCriteria `yaml:"additional_stemcells_criteria"`
+	RequiresProductVersions     []struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"requires_product_versions"`
+	Releases []Release `yaml:"releases"`
+	JobTypes []struct {
+		Name               string `yaml:"name"`
+		Label              string `yaml:"label"`
+		Errand             bool   `yaml:"errand"`
+		InstanceDefinition struct {
+			Configurable bool        `yaml:"configurable"`
+			Default      interface{} `yaml:"default"`
+		} `yaml:"instance_definition"`
+		PropertyBlueprints []interface{} `yaml:"property_blueprints"`
+	} `yaml:"job_types"`
+	PostDeployErrands []struct {
+		Name string `yaml:"name"`
+	} `yaml:"post_deploy_errands"`
+	PreDeleteErrands []struct {
+		Name string `yaml:"name"`
+	} `yaml:"pre_delete_errands"`
+	PropertyBlueprints []struct {
+		Configurable bool `yaml:"configurable"`
+	} `yaml:"property_blueprints"`
+}
+
+// InspectMetadata reads the contents of the metadata file of a product.
+func InspectMetadata(raw []byte) (ProductInspection, error) {
+	var metadata inspectedMetadata
+	err := yaml.Unmarshal(raw, &metadata)
+	if err != nil {
+		return ProductInspection{}, fmt.Errorf("could not inspect product metadata: %s", err)
+	}
+
+	inspection := ProductInspection{
+		Name:                        metadata.Name,
+		Version:                     metadata.Version,
+		MinimumVersionForUpgrade:    metadata.MinimumVersionForUpgrade,
+		StemcellCriteria:            metadata.StemcellCriteria,
+		AdditionalStemcellsCriteria: metadata.AdditionalStemcellsCriteria,
+		ProductDependencies:         []ProductDependency{},
+		Releases:                    metadata.Releases,
+		JobTypes:                    []JobType{},
+		Errands: Errands{
+			PostDeploy: []string{},
+			PreDelete:  []string{},
+		},
+	}
+
+	if inspection.Releases == nil {
+		inspection.Releases = []Release{}
+	}
+
+	for _, required := range metadata.RequiresProductVersions {
+		if required.Name == opsManagerProductName {
+			inspection.OpsManagerVersion = required.Version
+			inspection.MinimumOpsManagerVersion = minimumVersion(required.Version)
+			continue
+		}
+
+		inspection.ProductDependencies = append(inspection.ProductDependencies, ProductDependency{
+			Name:    required.Name,
+			Version: required.Version,
+		})
+	}
+
+	for _, job := range metadata.JobTypes {
+		jobType := JobType{
+			Name:                  job.Name,
+			Label:                 job.Label,
+			Errand:                job.Errand,
+			InstancesConfigurable: job.InstanceDefinition.Configurable,
+			PropertyBlueprints:    len(job.PropertyBlueprints),
+		}
+
+		if instances, ok := job.InstanceDefinition.Default.(int); ok {
+			jobType.Instances = &instances
+		}
+
+		inspection.JobTypes = append(inspection.JobTypes, jobType)
+		inspection.PropertyBlueprints.Jobs += len(job.PropertyBlueprints)
+	}
+
+	for _, errand := range metadata.PostDeployErrands {
+		inspection.Errands.PostDeploy = append(inspection.Errands.PostDeploy, errand.Name)
+	}
+
+	for _, errand := range metadata.PreDeleteErrands {
+		inspection.Errands.PreDelete = append(inspection.Errands.PreDelete, errand.Name)
+	}
+
+	inspection.PropertyBlueprints.Product = len(metadata.PropertyBlueprints)
+	for _, property := range metadata.PropertyBlueprints {
+		if property.Configurable {
+			inspection.PropertyBlueprints.Configurable++
+		}
+	}
+
+	return inspection, nil
+}
+
+// minimumVersion is the lowest version that can satisfy a constraint, such
+// as 2.7.0 for '~> 2.7.0' or '>= 2.6, < 3'. It is empty when the constraint
+// has no lower bound that a version can be equal to.
+func minimumVersion(constraint string) string {
+	var minimum *version.Version
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+
+		var bound string
+		for _, operator := range []string{">=", "~>", "="} {
+			if strings.HasPrefix(part, operator) {
+				bound = strings.TrimSpace(strings.TrimPrefix(part, operator))
+				break
+			}
+		}
+		if bound == "" && !strings.ContainsAny(part, "<>!") {
+			bound = part
+		}
+
+		v, err := version.NewVersion(bound)
+		if err != nil {
+			continue
+		}
+
+		if minimum == nil || v.GreaterThan(minimum) {
+			minimum = v
+		}
+	}
+
+	if minimum == nil {
+		return ""
+	}
+
+	return minimum.Original()
+}
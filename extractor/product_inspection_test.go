@@ -0,0 +1,124 @@
+package extractor_test
+
+import (
+	"github.com/pivotal-cf/om/extractor"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const inspectedYAML = `---
+name: some-product
+product_version: 2.7.3
+minimum_version_for_upgrade: 2.6.0
+stemcell_criteria:
+  os: ubuntu-xenial
+  version: 456.30
+  enable_patch_security_updates: false
+additional_stemcells_criteria:
+- os: windows2019
+  version: "2019.7"
+requires_product_versions:
+- name: p-bosh
+  version: ~> 2.7.0
+- name: cf
+  version: ">= 2.6"
+releases:
+- name: some-release
+  version: 1.2.3
+  file: some-release-1.2.3.tgz
+property_blueprints:
+- name: some-property
+  configurable: true
+- name: some-generated-secret
+job_types:
+- name: server
+  label: Server
+  instance_definition:
+    configurable: true
+    default: 3
+  property_blueprints:
+  - name: a
+  - name: b
+- name: smoke_tests
+  label: Smoke Tests
+  errand: true
+  instance_definition:
+    default: 1
+post_deploy_errands:
+- name: smoke_tests
+pre_delete_errands:
+- name: cleanup
+`
+
+var _ = Describe("InspectMetadata", func() {
+	It("reports what the product requires and deploys", func() {
+		inspection, err := extractor.InspectMetadata([]byte(inspectedYAML))
+		Expect(err).ToNot(HaveOccurred())
+
+		three, one, disabled := 3, 1, false
+		Expect(inspection).To(Equal(extractor.ProductInspection{
+			Name:                     "some-product",
+			Version:                  "2.7.3",
+			MinimumVersionForUpgrade: "2.6.0",
+			OpsManagerVersion:        "~> 2.7.0",
+			MinimumOpsManagerVersion: "2.7.0",
+			StemcellCriteria: extractor.StemcellCriteria{
+				OS:                         "ubuntu-xenial",
+				Version:                    "456.30",
+				EnablePatchSecurityUpdates: &disabled,
+			},
+			AdditionalStemcellsCriteria: []extractor.StemcellCriteria{
+				{OS: "windows2019", Version: "2019.7"},
+			},
+			ProductDependencies: []extractor.ProductDependency{
+				{Name: "cf", Version: ">= 2.6"},
+			},
+			Releases: []extractor.Release{
+				{Name: "some-release", Version: "1.2.3", File: "some-release-1.2.3.tgz"},
+			},
+			JobTypes: []extractor.JobType{
+				{Name: "server", Label: "Server", Instances: &three, InstancesConfigurable: true, PropertyBlueprints: 2},
+				{Name: "smoke_tests", Label: "Smoke Tests", Errand: true, Instances: &one},
+			},
+			Errands: extractor.Errands{
+				PostDeploy: []string{"smoke_tests"},
+				PreDelete:  []string{"cleanup"},
+			},
+			PropertyBlueprints: extractor.PropertyBlueprints{
+				Product:      2,
+				Configurable: 1,
+				Jobs:         2,
+			},
+		}))
+	})
+
+	It("reports empty lists for a product with only a name and version", func() {
+		inspection, err := extractor.InspectMetadata([]byte(validYAML))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(inspection.Name).To(Equal("some-product"))
+		Expect(inspection.ProductDependencies).To(BeEmpty())
+		Expect(inspection.ProductDependencies).ToNot(BeNil())
+		Expect(inspection.Releases).ToNot(BeNil())
+		Expect(inspection.JobTypes).ToNot(BeNil())
+		Expect(inspection.Errands.PostDeploy).ToNot(BeNil())
+	})
+
+	DescribeTable("the minimum Ops Manager version", func(constraint, minimum string) {
+		inspection, err := extractor.InspectMetadata([]byte("requires_product_versions:\n- name: p-bosh\n  version: '" + constraint + "'\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inspection.MinimumOpsManagerVersion).To(Equal(minimum))
+	},
+		Entry("pessimistic", "~> 2.7.0", "2.7.0"),
+		Entry("several bounds", ">= 2.5, >= 2.6.1, < 3", "2.6.1"),
+		Entry("exact", "2.7.2", "2.7.2"),
+		Entry("no lower bound", "< 3", ""),
+	)
+
+	It("returns an error when the metadata is not yaml", func() {
+		_, err := extractor.InspectMetadata([]byte("- not: [a product"))
+		Expect(err).To(MatchError(ContainSubstring("could not inspect product metadata")))
+	})
+})
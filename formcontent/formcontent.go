@@ -18,6 +18,7 @@ type Form struct {
 	formFields  *bytes.Buffer
 	formWriter  *multipart.Writer
 	files       []string
+	readers     []io.Reader
 	fileKeys    []*bytes.Buffer
 	doneWriting chan error
 }
@@ -65,6 +66,7 @@ func (f *Form) Reset() {
 	f.formFields = buf
 	f.formWriter = formWriter
 	f.files = nil
+	f.readers = nil
 	f.fileKeys = nil
 	f.doneWriting = make(chan error, 1)
 }
@@ -85,10 +87,24 @@ func (f *Form) AddFile(key string, path string) error {
 		return err
 	}
 
+	return f.addFile(key, path, nil, fileLength)
+}
+
+// AddReader adds a file whose contents are read from r, such as a file being
+// streamed from a blobstore, instead of from a path on disk.
+func (f *Form) AddReader(key string, fileName string, r io.Reader, length int64) error {
+	if length <= 0 {
+		return errors.New("file provided has no content")
+	}
+
+	return f.addFile(key, fileName, r, length)
+}
+
+func (f *Form) addFile(key string, path string, r io.Reader, fileLength int64) error {
 	buf := &bytes.Buffer{}
 
 	fileKey := multipart.NewWriter(buf)
-	err = fileKey.SetBoundary(f.boundary)
+	err := fileKey.SetBoundary(f.boundary)
 	if err != nil {
 		return err
 	}
@@ -103,6 +119,7 @@ func (f *Form) AddFile(key string, path string) error {
 	f.length += int64(buf.Len())
 
 	f.files = append(f.files, path)
+	f.readers = append(f.readers, r)
 	f.fileKeys = append(f.fileKeys, buf)
 
 	return nil
@@ -173,8 +190,11 @@ func (f *Form) writeToPipe() {
 			return
 		}
 
-		fileName := f.files[i]
-		err = writeFileToPipe(fileName, f.pw)
+		if reader := f.readers[i]; reader != nil {
+			_, err = io.Copy(f.pw, reader)
+		} else {
+			err = writeFileToPipe(f.files[i], f.pw)
+		}
 		if err != nil {
 			_ = f.pw.CloseWithError(err)
 			f.doneWriting <- err
@@ -7,6 +7,7 @@ import (
 
 	"io/ioutil"
 	"os"
+	"strings"
 )
 
 var _ = Describe("Formcontent", func() {
@@ -135,6 +136,40 @@ var _ = Describe("Formcontent", func() {
 		})
 	})
 
+	Describe("AddReader", func() {
+		BeforeEach(func() {
+			form = formcontent.NewForm()
+		})
+
+		It("writes out the contents of the reader as a file in the multipart form", func() {
+			err := form.AddReader("installation[file]", "backups/installation.zip", strings.NewReader("some content"), 12)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = form.AddField("passphrase", "some-passphrase")
+			Expect(err).ToNot(HaveOccurred())
+
+			submission := form.Finalize()
+
+			content, err := ioutil.ReadAll(submission.Content)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(content)).To(MatchRegexp(`^--\w+\r\nContent-Disposition: form-data; name=\"installation\[file\]\"; filename=\"installation.zip\"\r\n` +
+				`Content-Type: application/octet-stream\r\n\r\n` +
+				`some content` +
+				`\r\n--\w+\r\nContent-Disposition: form-data; name=\"passphrase\"\r\n\r\n` +
+				`some-passphrase` +
+				`\r\n--\w+--\r\n$`))
+			Expect(submission.ContentLength).To(Equal(int64(len(content))))
+		})
+
+		When("the length is zero", func() {
+			It("returns an error", func() {
+				err := form.AddReader("foo", "empty.zip", strings.NewReader(""), 0)
+				Expect(err).To(MatchError("file provided has no content"))
+			})
+		})
+	})
+
 	Describe("AddField", func() {
 		BeforeEach(func() {
 			form = formcontent.NewForm()
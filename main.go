@@ -23,6 +23,7 @@ import (
 	"github.com/pivotal-cf/om/extractor"
 	"github.com/pivotal-cf/om/formcontent"
 	"github.com/pivotal-cf/om/network"
+	"github.com/pivotal-cf/om/notifications"
 	"github.com/pivotal-cf/om/presenters"
 	"github.com/pivotal-cf/om/progress"
 
@@ -33,6 +34,37 @@ var version = "unknown"
 
 var applySleepDurationString = "10s"
 
+// notifyingCommands change the Ops Manager, and notify --notify-url when run.
+var notifyingCommands = []string{
+	"activate-certificate-authority",
+	"apply-changes",
+	"apply-foundation",
+	"assign-multi-stemcell",
+	"assign-stemcell",
+	"configure-authentication",
+	"configure-director",
+	"configure-ldap-authentication",
+	"configure-product",
+	"configure-saml-authentication",
+	"create-certificate-authority",
+	"create-vm-extension",
+	"delete-certificate-authority",
+	"delete-installation",
+	"delete-product",
+	"delete-ssl-certificate",
+	"delete-unused-products",
+	"disable-director-verifiers",
+	"disable-product-verifiers",
+	"generate-certificate-authority",
+	"import-installation",
+	"regenerate-certificates",
+	"stage-product",
+	"unstage-product",
+	"update-ssl-certificate",
+	"upload-product",
+	"upload-stemcell",
+}
+
 type httpClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
@@ -45,6 +77,7 @@ type options struct {
 	DecryptionPassphrase string `yaml:"decryption-passphrase" short:"d"  long:"decryption-passphrase" env:"OM_DECRYPTION_PASSPHRASE"             description:"Passphrase to decrypt the installation if the Ops Manager VM has been rebooted (optional for most commands)"`
 	Env                  string `                             short:"e"  long:"env"                                                              description:"env file with login credentials"`
 	Help                 bool   `                             short:"h"  long:"help"                                             default:"false" description:"prints this usage information"`
+	NotifyURL            string `yaml:"notify-url"                       long:"notify-url"            env:"OM_NOTIFY_URL"                          description:"URL to POST a JSON event to when a command that changes Ops Manager starts and finishes"`
 	Password             string `yaml:"password"              short:"p"  long:"password"              env:"OM_PASSWORD"                            description:"admin password for the Ops Manager VM (not required for unauthenticated commands)"`
 	RequestTimeout       int    `yaml:"request-timeout"       short:"r"  long:"request-timeout"       env:"OM_REQUEST_TIMEOUT"     default:"1800"  description:"timeout in seconds for HTTP requests to Ops Manager"`
 	SkipSSLValidation    bool   `yaml:"skip-ssl-validation"   short:"k"  long:"skip-ssl-validation"   env:"OM_SKIP_SSL_VALIDATION" default:"false" description:"skip ssl certificate validation during http requests"`
@@ -152,13 +185,16 @@ func main() {
 
 	commandSet := jhanda.CommandSet{}
 	commandSet["activate-certificate-authority"] = commands.NewActivateCertificateAuthority(api, stdout)
-	commandSet["apply-changes"] = commands.NewApplyChanges(api, api, logWriter, stdout, applySleepDuration)
+	commandSet["apply-changes"] = commands.NewApplyChanges(api, api, logWriter, commands.NewInstallationEventWriter(os.Stdout, os.Stderr), stdout, stderr, make(chan os.Signal, 1), applySleepDuration)
+	commandSet["apply-foundation"] = commands.NewApplyFoundation(os.Environ, commandSet, metadataExtractor, stdout)
 	commandSet["assign-multi-stemcell"] = commands.NewAssignMultiStemcell(api, stdout)
 	commandSet["assign-stemcell"] = commands.NewAssignStemcell(api, stdout)
 	commandSet["available-products"] = commands.NewAvailableProducts(api, presenter, stdout)
 	commandSet["bosh-env"] = commands.NewBoshEnvironment(api, stdout, global.Target, envRendererFactory)
+	commandSet["cache"] = commands.NewCache(stdout)
 	commandSet["certificate-authorities"] = commands.NewCertificateAuthorities(api, presenter)
 	commandSet["certificate-authority"] = commands.NewCertificateAuthority(api, presenter, stdout)
+	commandSet["check-product-compatibility"] = commands.NewCheckProductCompatibility(metadataExtractor, api, stdout)
 	commandSet["config-template"] = commands.NewConfigTemplate(commands.DefaultProvider())
 	commandSet["configure-authentication"] = commands.NewConfigureAuthentication(os.Environ, api, stdout)
 	commandSet["configure-director"] = commands.NewConfigureDirector(os.Environ, api, stdout)
@@ -178,19 +214,26 @@ func main() {
 	commandSet["deployed-manifest"] = commands.NewDeployedManifest(api, stdout)
 	commandSet["deployed-products"] = commands.NewDeployedProducts(presenter, api)
 	commandSet["diagnostic-report"] = commands.NewDiagnosticReport(presenter, api)
+	commandSet["diff-product-config"] = commands.NewDiffProductConfig(os.Environ, api, stdout)
 	commandSet["disable-director-verifiers"] = commands.NewDisableDirectorVerifiers(presenter, api, stdout)
 	commandSet["disable-product-verifiers"] = commands.NewDisableProductVerifiers(presenter, api, stdout)
 	commandSet["download-product"] = commands.NewDownloadProduct(os.Environ, stdout, stderr, os.Stderr)
+	commandSet["download-products"] = commands.NewDownloadProducts(os.Environ, stdout, stderr, os.Stderr)
 	commandSet["errands"] = commands.NewErrands(presenter, api)
 	commandSet["expiring-certificates"] = commands.NewExpiringCertificates(api, stdout)
 	commandSet["export-installation"] = commands.NewExportInstallation(api, stderr)
+	commandSet["export-staged-configs"] = commands.NewExportStagedConfigs(api, stdout)
+	commandSet["extract-vars"] = commands.NewExtractVars(stdout)
 	commandSet["generate-certificate"] = commands.NewGenerateCertificate(api, stdout)
 	commandSet["generate-certificate-authority"] = commands.NewGenerateCertificateAuthority(api, presenter)
 	commandSet["help"] = commands.NewHelp(os.Stdout, globalFlagsUsage, commandSet)
 	commandSet["import-installation"] = commands.NewImportInstallation(form, api, global.DecryptionPassphrase, stdout)
-	commandSet["installation-log"] = commands.NewInstallationLog(api, stdout)
+	commandSet["inspect-installation"] = commands.NewInspectInstallation(global.DecryptionPassphrase, os.Stdout)
+	commandSet["installation-history"] = commands.NewInstallationHistory(api, os.Stdout)
+	commandSet["installation-log"] = commands.NewInstallationLog(api, stdout, applySleepDuration)
 	commandSet["installations"] = commands.NewInstallations(api, presenter)
 	commandSet["interpolate"] = commands.NewInterpolate(os.Environ, stdout, os.Stdin)
+	commandSet["mirror-products"] = commands.NewMirrorProducts(os.Environ, stdout, stderr, os.Stderr)
 	commandSet["pending-changes"] = commands.NewPendingChanges(presenter, api)
 	commandSet["pre-deploy-check"] = commands.NewPreDeployCheck(presenter, api, stdout)
 	commandSet["regenerate-certificates"] = commands.NewRegenerateCertificates(api, stdout)
@@ -208,6 +251,18 @@ func main() {
 	commandSet["upload-stemcell"] = commands.NewUploadStemcell(form, api, stdout)
 	commandSet["version"] = commands.NewVersion(version, os.Stdout)
 
+	if global.NotifyURL != "" {
+		user := global.Username
+		if user == "" {
+			user = global.ClientID
+		}
+
+		notifier := notifications.NewNotifier(global.NotifyURL, &http.Client{Timeout: connectTimeout}, api, global.Target, user, stderr)
+		for _, name := range notifyingCommands {
+			commandSet[name] = notifier.Wrap(name, commandSet[name], name == "apply-changes" || name == "delete-installation")
+		}
+	}
+
 	err = commandSet.Execute(command, args)
 	if err != nil {
 		stderr.Fatal(err)
@@ -273,6 +328,9 @@ func setEnvFileProperties(global *options) error {
 	if global.CACert == "" {
 		global.CACert = opts.CACert
 	}
+	if global.NotifyURL == "" {
+		global.NotifyURL = opts.NotifyURL
+	}
 
 	err = checkForVars(global)
 	if err != nil {
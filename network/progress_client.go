@@ -1,6 +1,7 @@
 package network
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"time"
@@ -32,6 +33,16 @@ type ProgressClient struct {
 	liveWriter  liveWriter
 }
 
+// hideProgressKey is the context key of requests whose progress is not
+// shown, such as uploads that run at the same time as others.
+type hideProgressKey struct{}
+
+// HideProgress returns a context for a request whose progress the
+// ProgressClient does not show when hide is true.
+func HideProgress(ctx context.Context, hide bool) context.Context {
+	return context.WithValue(ctx, hideProgressKey{}, hide)
+}
+
 func NewProgressClient(client httpClient, progressBar progressBar, liveWriter liveWriter) ProgressClient {
 	return ProgressClient{
 		client:      client,
@@ -41,6 +52,10 @@ func NewProgressClient(client httpClient, progressBar progressBar, liveWriter li
 }
 
 func (pc ProgressClient) Do(req *http.Request) (*http.Response, error) {
+	if hide, _ := req.Context().Value(hideProgressKey{}).(bool); hide {
+		return pc.client.Do(req)
+	}
+
 	duration, ok := req.Context().Value("polling-interval").(time.Duration)
 	if !ok {
 		duration = time.Second
@@ -74,6 +74,25 @@ var _ = Describe("ProgressClient", func() {
 			Expect(progressBar.FinishCallCount()).To(Equal(1))
 		})
 
+		It("does not show progress when it is hidden", func() {
+			client.DoReturns(&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+			}, nil)
+
+			req, err := http.NewRequest("POST", "/some/endpoint", strings.NewReader("some content"))
+			Expect(err).ToNot(HaveOccurred())
+			req = req.WithContext(network.HideProgress(req.Context(), true))
+
+			_, err = progressClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.DoArgsForCall(0)).To(Equal(req))
+			Expect(progressBar.ResetCallCount()).To(Equal(0))
+			Expect(progressBar.NewProxyReaderCallCount()).To(Equal(0))
+			Expect(liveWriter.StartCallCount()).To(Equal(0))
+		})
+
 		It("logs while waiting for a response from the Ops Manager", func() {
 			client.DoStub = func(req *http.Request) (*http.Response, error) {
 				_, err := ioutil.ReadAll(req.Body)
@@ -0,0 +1,353 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/extractor"
+	"github.com/pivotal-cf/om/interpolate"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	EventStarted  = "started"
+	EventFinished = "finished"
+
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+)
+
+// Event is the JSON body POSTed to the notify URL when a command starts and
+// when it finishes.
+type Event struct {
+	Event          string     `json:"event"`
+	Command        string     `json:"command"`
+	Target         string     `json:"target"`
+	User           string     `json:"user,omitempty"`
+	Products       []string   `json:"products,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	Outcome        string     `json:"outcome,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	InstallationID int        `json:"installation_id,omitempty"`
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+//counterfeiter:generate -o ./fakes/http_client.go --fake-name HttpClient . httpClient
+type httpClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+//counterfeiter:generate -o ./fakes/installations_service.go --fake-name InstallationsService . installationsService
+type installationsService interface {
+	ListInstallations() ([]api.InstallationsServiceOutput, error)
+}
+
+//counterfeiter:generate -o ./fakes/logger.go --fake-name Logger . logger
+type logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type Notifier struct {
+	url     string
+	client  httpClient
+	service installationsService
+	target  string
+	user    string
+	logger  logger
+}
+
+func NewNotifier(url string, client httpClient, service installationsService, target, user string, logger logger) Notifier {
+	return Notifier{
+		url:     url,
+		client:  client,
+		service: service,
+		target:  target,
+		user:    user,
+		logger:  logger,
+	}
+}
+
+// Wrap returns a command that notifies before and after running command.
+// When installation is set, for commands such as apply-changes that start an
+// installation, the ID of the installation the command started is included
+// once it finishes. It is left out when the command failed before starting one.
+func (n Notifier) Wrap(name string, command jhanda.Command, installation bool) jhanda.Command {
+	return notifyingCommand{
+		notifier:     n,
+		name:         name,
+		command:      command,
+		installation: installation,
+	}
+}
+
+// Notify POSTs the event to the notify URL. Failing to notify does not fail
+// the command, so errors are only logged.
+func (n Notifier) Notify(event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Printf("could not send notification to %s: %s", n.url, err) // un-tested
+		return
+	}
+
+	req, err := http.NewRequest("POST", n.url, bytes.NewReader(body))
+	if err != nil {
+		n.logger.Printf("could not send notification to %s: %s", n.url, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.logger.Printf("could not send notification to %s: %s", n.url, err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		n.logger.Printf("could not send notification to %s: unexpected response %s", n.url, resp.Status)
+	}
+}
+
+func (n Notifier) latestInstallationID() (int, bool) {
+	installations, err := n.service.ListInstallations()
+	if err != nil {
+		n.logger.Printf("could not find the installation ID for the notification: %s", err)
+		return 0, false
+	}
+
+	var id int
+	for _, installation := range installations {
+		if installation.ID > id {
+			id = installation.ID
+		}
+	}
+	return id, true
+}
+
+type notifyingCommand struct {
+	notifier     Notifier
+	name         string
+	command      jhanda.Command
+	installation bool
+}
+
+func (nc notifyingCommand) Execute(args []string) error {
+	event := Event{
+		Event:     EventStarted,
+		Command:   nc.name,
+		Target:    nc.notifier.target,
+		User:      nc.notifier.user,
+		Products:  productNames(nc.command, args),
+		StartedAt: time.Now().UTC(),
+	}
+	nc.notifier.Notify(event)
+
+	var previousID int
+	var previousFound bool
+	if nc.installation {
+		previousID, previousFound = nc.notifier.latestInstallationID()
+	}
+
+	err := nc.command.Execute(args)
+
+	finishedAt := time.Now().UTC()
+	event.Event = EventFinished
+	event.FinishedAt = &finishedAt
+	event.Outcome = OutcomeSucceeded
+	if err != nil {
+		event.Outcome = OutcomeFailed
+		event.Error = err.Error()
+	}
+	if nc.installation && previousFound {
+		id, found := nc.notifier.latestInstallationID()
+		if found && id > previousID {
+			event.InstallationID = id
+		}
+	}
+	nc.notifier.Notify(event)
+
+	return err
+}
+
+func (nc notifyingCommand) Usage() jhanda.Usage {
+	return nc.command.Usage()
+}
+
+// productNames finds the products a command acts on by parsing its args
+// into a copy of its options: the values of any product name flag, the tiles
+// of a --product flag, and the product-name of a --config file, such as the
+// one given to configure-product.
+func productNames(command jhanda.Command, args []string) []string {
+	value := reflect.Indirect(reflect.ValueOf(command))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	optionsField := value.FieldByName("Options")
+	if !optionsField.IsValid() || optionsField.Kind() != reflect.Struct {
+		return nil
+	}
+
+	options := reflect.New(optionsField.Type())
+	if _, err := commands.ParseFlags(options.Interface(), args); err != nil {
+		return nil
+	}
+
+	names := map[string]bool{}
+	addProductNames(names, options.Elem(), options.Elem())
+
+	var products []string
+	for name := range names {
+		if name != "" {
+			products = append(products, name)
+		}
+	}
+	sort.Strings(products)
+
+	return products
+}
+
+// addProductNames adds the product names of the options to names, including
+// those of any options embedded in them. The config file is interpolated with
+// the vars flags of all the options, root.
+func addProductNames(names map[string]bool, options reflect.Value, root reflect.Value) {
+	for i := 0; i < options.NumField(); i++ {
+		field := options.Type().Field(i)
+		fieldValue := options.Field(i)
+
+		switch {
+		case field.Anonymous && fieldValue.Kind() == reflect.Struct:
+			addProductNames(names, fieldValue, root)
+		case field.Tag.Get("long") == "product-name" || field.Name == "ProductName":
+			switch fieldValue.Kind() {
+			case reflect.String:
+				names[fieldValue.String()] = true
+			case reflect.Slice:
+				for j := 0; j < fieldValue.Len(); j++ {
+					names[fmt.Sprintf("%v", fieldValue.Index(j).Interface())] = true
+				}
+			}
+		case field.Tag.Get("long") == "product":
+			var values []string
+			switch fieldValue.Kind() {
+			case reflect.String:
+				values = []string{fieldValue.String()}
+			case reflect.Slice:
+				values, _ = fieldValue.Interface().([]string)
+			}
+			for _, value := range values {
+				for _, name := range productFlagNames(value) {
+					names[name] = true
+				}
+			}
+		case field.Tag.Get("long") == "config" && fieldValue.Kind() == reflect.String:
+			if name := configProductName(fieldValue.String(), root); name != "" {
+				names[name] = true
+			}
+		}
+	}
+}
+
+// productFlagNames are the names of the products of a --product flag, which
+// is the path of a tile for upload-product and check-product-compatibility.
+// The names of the tiles it matches, as a file, a directory of .pivotal files
+// or a glob, are read from their metadata. A value that matches no file is
+// the name of a product unless it looks like a path.
+func productFlagNames(value string) []string {
+	paths, _ := filepath.Glob(value)
+	if info, err := os.Stat(value); err == nil && info.IsDir() {
+		paths, _ = filepath.Glob(filepath.Join(value, "*.pivotal"))
+	}
+
+	if len(paths) == 0 {
+		if strings.ContainsAny(value, `/\*?[`) || filepath.Ext(value) != "" {
+			return nil
+		}
+		return []string{value}
+	}
+
+	var names []string
+	for _, path := range paths {
+		metadata, err := extractor.MetadataExtractor{}.ExtractMetadata(path)
+		if err != nil {
+			continue
+		}
+		names = append(names, metadata.Name)
+	}
+
+	return names
+}
+
+// configProductName is the product-name of a config file, interpolated as
+// the command does with its vars flags. A product-name that is still a
+// placeholder after that is left out.
+func configProductName(path string, options reflect.Value) string {
+	if path == "" {
+		return ""
+	}
+
+	varsEnvs := stringsFlag(options, "vars-env")
+	if value, ok := os.LookupEnv("OM_VARS_ENV"); ok {
+		varsEnvs = append(varsEnvs, value)
+	}
+
+	contents, err := interpolate.Execute(interpolate.Options{
+		TemplateFile: path,
+		VarsFiles:    stringsFlag(options, "vars-file"),
+		Vars:         stringsFlag(options, "var"),
+		VarsEnvs:     varsEnvs,
+		OpsFiles:     stringsFlag(options, "ops-file"),
+		EnvironFunc:  os.Environ,
+	})
+	if err != nil {
+		return ""
+	}
+
+	var config struct {
+		ProductName string `yaml:"product-name"`
+	}
+	_ = yaml.Unmarshal(contents, &config)
+
+	if strings.HasPrefix(config.ProductName, "((") && strings.HasSuffix(config.ProductName, "))") {
+		return ""
+	}
+
+	return config.ProductName
+}
+
+// stringsFlag is the value of the []string flag of the options with the long
+// name, such as vars-file, when they have one.
+func stringsFlag(options reflect.Value, long string) []string {
+	for i := 0; i < options.NumField(); i++ {
+		field := options.Type().Field(i)
+		fieldValue := options.Field(i)
+
+		if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+			if values := stringsFlag(fieldValue, long); values != nil {
+				return values
+			}
+			continue
+		}
+
+		if field.Tag.Get("long") == long {
+			values, _ := fieldValue.Interface().([]string)
+			return values
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package notifications_test
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/notifications"
+	"github.com/pivotal-cf/om/notifications/fakes"
+)
+
+var _ = Describe("Notifier", func() {
+	var (
+		server   *ghttp.Server
+		events   []notifications.Event
+		service  *fakes.InstallationsService
+		logger   *fakes.Logger
+		notifier notifications.Notifier
+	)
+
+	BeforeEach(func() {
+		events = nil
+		server = ghttp.NewServer()
+		server.RouteToHandler("POST", "/hooks", func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			var event notifications.Event
+			Expect(json.NewDecoder(req.Body).Decode(&event)).To(Succeed())
+			events = append(events, event)
+		})
+
+		service = &fakes.InstallationsService{}
+		logger = &fakes.Logger{}
+		notifier = notifications.NewNotifier(server.URL()+"/hooks", http.DefaultClient, service, "https://opsman.example.com", "some-user", logger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Wrap", func() {
+		It("notifies when the command starts and when it finishes", func() {
+			command := &stageProduct{}
+
+			err := notifier.Wrap("stage-product", command, false).Execute([]string{"--product-name", "cf", "--product-version", "2.7.0"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command.args).To(Equal([]string{"--product-name", "cf", "--product-version", "2.7.0"}))
+
+			Expect(events).To(HaveLen(2))
+
+			Expect(events[0].Event).To(Equal("started"))
+			Expect(events[0].Command).To(Equal("stage-product"))
+			Expect(events[0].Target).To(Equal("https://opsman.example.com"))
+			Expect(events[0].User).To(Equal("some-user"))
+			Expect(events[0].Products).To(Equal([]string{"cf"}))
+			Expect(events[0].StartedAt).ToNot(BeZero())
+			Expect(events[0].FinishedAt).To(BeNil())
+			Expect(events[0].Outcome).To(BeEmpty())
+
+			Expect(events[1].Event).To(Equal("finished"))
+			Expect(events[1].StartedAt).To(Equal(events[0].StartedAt))
+			Expect(*events[1].FinishedAt).To(BeTemporally(">=", events[0].StartedAt))
+			Expect(events[1].Outcome).To(Equal("succeeded"))
+			Expect(events[1].InstallationID).To(BeZero())
+
+			Expect(service.ListInstallationsCallCount()).To(Equal(0))
+			Expect(logger.PrintfCallCount()).To(Equal(0))
+		})
+
+		It("notifies with the error when the command fails", func() {
+			command := &stageProduct{err: errors.New("some-error")}
+
+			err := notifier.Wrap("stage-product", command, false).Execute([]string{"-p", "cf"})
+			Expect(err).To(MatchError("some-error"))
+
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].Products).To(Equal([]string{"cf"}))
+			Expect(events[1].Outcome).To(Equal("failed"))
+			Expect(events[1].Error).To(Equal("some-error"))
+		})
+
+		It("includes the product from a --config file", func() {
+			config, err := ioutil.TempFile("", "config-*.yml")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = config.WriteString("product-name: p-redis\nproduct-properties: {}\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Close()).To(Succeed())
+			defer os.Remove(config.Name())
+
+			err = notifier.Wrap("configure-product", &configureProduct{}, false).Execute([]string{"--config", config.Name()})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(events[0].Products).To(Equal([]string{"p-redis"}))
+		})
+
+		It("interpolates the product-name of the --config file with the vars of the command", func() {
+			config, err := ioutil.TempFile("", "config-*.yml")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = config.WriteString("product-name: ((product_name))\nproduct-properties: {}\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Close()).To(Succeed())
+			defer os.Remove(config.Name())
+
+			err = notifier.Wrap("configure-product", &configureProduct{}, false).Execute([]string{"--config", config.Name(), "--var", "product_name=p-redis"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(events[0].Products).To(Equal([]string{"p-redis"}))
+		})
+
+		It("leaves out the product-name of the --config file when it is still a placeholder", func() {
+			config, err := ioutil.TempFile("", "config-*.yml")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = config.WriteString("product-name: ((product_name))\nproduct-properties: {}\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Close()).To(Succeed())
+			defer os.Remove(config.Name())
+
+			err = notifier.Wrap("configure-product", &configureProduct{}, false).Execute([]string{"--config", config.Name()})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(events[0].Products).To(BeEmpty())
+		})
+
+		It("includes the product from options embedded in those of the command", func() {
+			command := &downloadProduct{}
+
+			err := notifier.Wrap("download-product", command, false).Execute([]string{"--product-name", "cf", "--blobstore-bucket", "some-bucket"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(events[0].Products).To(Equal([]string{"cf"}))
+		})
+
+		It("includes the product of the tile given to --product", func() {
+			tile, err := ioutil.TempFile("", "tile-*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(tile.Name())
+
+			z := zip.NewWriter(tile)
+			f, err := z.Create("metadata/p-redis.yml")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = f.Write([]byte("name: p-redis\nproduct_version: 2.0.0\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(z.Close()).To(Succeed())
+			Expect(tile.Close()).To(Succeed())
+
+			err = notifier.Wrap("upload-product", &uploadProduct{}, false).Execute([]string{"--product", tile.Name()})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(events[0].Products).To(Equal([]string{"p-redis"}))
+		})
+
+		It("includes the product named by --product when it is not a file", func() {
+			err := notifier.Wrap("upload-product", &uploadProduct{}, false).Execute([]string{"--product", "cf"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events[0].Products).To(Equal([]string{"cf"}))
+		})
+
+		It("leaves out a --product that is the path of a missing tile", func() {
+			err := notifier.Wrap("upload-product", &uploadProduct{}, false).Execute([]string{"--product", "/tmp/missing/cf.pivotal"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events[0].Products).To(BeEmpty())
+		})
+
+		It("includes the ID of the installation the command started", func() {
+			service.ListInstallationsReturnsOnCall(0, []api.InstallationsServiceOutput{{ID: 12}, {ID: 13}}, nil)
+			service.ListInstallationsReturnsOnCall(1, []api.InstallationsServiceOutput{{ID: 12}, {ID: 14}, {ID: 13}}, nil)
+
+			err := notifier.Wrap("apply-changes", &stageProduct{}, true).Execute([]string{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(service.ListInstallationsCallCount()).To(Equal(2))
+			Expect(events[0].InstallationID).To(BeZero())
+			Expect(events[1].InstallationID).To(Equal(14))
+		})
+
+		It("leaves out the installation ID when the command fails before starting an installation", func() {
+			service.ListInstallationsReturns([]api.InstallationsServiceOutput{{ID: 12}, {ID: 13}}, nil)
+
+			err := notifier.Wrap("apply-changes", &stageProduct{err: errors.New("some-error")}, true).Execute([]string{})
+			Expect(err).To(MatchError("some-error"))
+
+			Expect(service.ListInstallationsCallCount()).To(Equal(2))
+			Expect(events[1].Outcome).To(Equal("failed"))
+			Expect(events[1].InstallationID).To(BeZero())
+		})
+
+		It("leaves out the installation ID when the installations cannot be listed", func() {
+			service.ListInstallationsReturns(nil, errors.New("some-error"))
+
+			err := notifier.Wrap("apply-changes", &stageProduct{}, true).Execute([]string{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(service.ListInstallationsCallCount()).To(Equal(1))
+			Expect(events[1].InstallationID).To(BeZero())
+
+			format, content := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("could not find the installation ID for the notification: %s"))
+			Expect(content).To(Equal([]interface{}{errors.New("some-error")}))
+		})
+
+		It("uses the usage of the command", func() {
+			command := notifier.Wrap("stage-product", &stageProduct{}, false)
+			Expect(command.Usage().ShortDescription).To(Equal("stages a product"))
+		})
+	})
+
+	Describe("Notify", func() {
+		It("logs, but does not fail, when the notification cannot be sent", func() {
+			server.RouteToHandler("POST", "/hooks", ghttp.RespondWith(http.StatusInternalServerError, ""))
+
+			err := notifier.Wrap("stage-product", &stageProduct{}, false).Execute([]string{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(logger.PrintfCallCount()).To(Equal(2))
+			format, content := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("could not send notification to %s: unexpected response %s"))
+			Expect(content).To(Equal([]interface{}{server.URL() + "/hooks", "500 Internal Server Error"}))
+		})
+
+		It("logs when the endpoint cannot be reached", func() {
+			client := &fakes.HttpClient{}
+			client.DoReturns(nil, errors.New("connection refused"))
+			notifier = notifications.NewNotifier("https://hooks.example.com", client, service, "", "", logger)
+
+			notifier.Notify(notifications.Event{Event: "started"})
+
+			Expect(logger.PrintfCallCount()).To(Equal(1))
+			format, content := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("could not send notification to %s: %s"))
+			Expect(content[1]).To(MatchError("connection refused"))
+		})
+	})
+})
+
+type stageProduct struct {
+	args    []string
+	err     error
+	Options struct {
+		Product string `long:"product-name"    short:"p" description:"name of product"`
+		Version string `long:"product-version" short:"v" description:"version of product"`
+	}
+}
+
+func (s *stageProduct) Execute(args []string) error {
+	s.args = args
+	return s.err
+}
+
+func (s *stageProduct) Usage() jhanda.Usage {
+	return jhanda.Usage{ShortDescription: "stages a product"}
+}
+
+type configureProduct struct {
+	Options struct {
+		ConfigFile string   `long:"config"    short:"c" description:"path to yml file for configuration"`
+		VarsFile   []string `long:"vars-file" short:"l" description:"Load variables from a YAML file"`
+		Vars       []string `long:"var"       short:"v" description:"Load variable from the command line. Format: VAR=VAL"`
+	}
+}
+
+func (c configureProduct) Execute([]string) error {
+	return nil
+}
+
+func (c configureProduct) Usage() jhanda.Usage {
+	return jhanda.Usage{}
+}
+
+type productFlags struct {
+	Product string `long:"product-name" description:"name of product"`
+}
+
+type downloadProduct struct {
+	Options struct {
+		Bucket string `long:"blobstore-bucket" description:"bucket name"`
+		productFlags
+	}
+}
+
+func (d downloadProduct) Execute([]string) error {
+	return nil
+}
+
+func (d downloadProduct) Usage() jhanda.Usage {
+	return jhanda.Usage{}
+}
+
+type uploadProduct struct {
+	Options struct {
+		Product []string `long:"product" short:"p" description:"path to product"`
+	}
+}
+
+func (u uploadProduct) Execute([]string) error {
+	return nil
+}
+
+func (u uploadProduct) Usage() jhanda.Usage {
+	return jhanda.Usage{}
+}
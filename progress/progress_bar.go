@@ -35,6 +35,10 @@ func (b Bar) SetTotal64(size int64) {
 	b.bar.Total = size
 }
 
+func (b Bar) Set64(current int64) {
+	b.bar.Set64(current)
+}
+
 func (b *Bar) Reset() {
 	b.bar = NewBar().bar
 }